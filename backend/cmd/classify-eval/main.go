@@ -0,0 +1,145 @@
+// Command classify-eval measures a classifier provider against a labeled incident corpus.
+//
+// The corpus is a JSON Lines file with one incident per line, in the format written by
+// GET /api/v1/classification/feedback:
+//
+//	{"title": "...", "description": "...", "severity": "high", "category": "network"}
+//
+// Usage:
+//
+//	go run ./cmd/classify-eval -input cmd/classify-eval/testdata/incidents.jsonl -provider rules
+//
+// Provider settings not given as flags are read from the same AI_* environment variables as the server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"incident-management/services"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	input := flag.String("input", "", "JSON Lines file of labeled incidents (required)")
+	provider := flag.String("provider", "", "classifier provider: openai, openai_compatible or rules (default $AI_PROVIDER)")
+	modelName := flag.String("model", "", "chat model name (default $AI_MODEL)")
+	baseURL := flag.String("base-url", "", "base URL of an OpenAI-compatible API (default $AI_BASE_URL)")
+	rulesFile := flag.String("rules", "", "YAML rules file for the rules provider (default $AI_RULES_FILE)")
+	concurrency := flag.Int("concurrency", 1, "number of parallel classifier calls")
+	timeout := flag.Duration("timeout", 10*time.Minute, "overall evaluation timeout")
+	jsonOutput := flag.Bool("json", false, "print the report as JSON")
+	mistakes := flag.Int("mistakes", 10, "number of misclassified examples to list")
+	flag.Parse()
+
+	if *input == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config := services.ClassifierConfigFromEnv()
+	if *provider != "" {
+		config.Provider = *provider
+	}
+	if *modelName != "" {
+		config.Model = *modelName
+	}
+	if *baseURL != "" {
+		config.BaseURL = *baseURL
+	}
+	if *rulesFile != "" {
+		config.RulesFile = *rulesFile
+	}
+	// Every example must reach the classifier, so results are never served from the cache
+	config.Cache = services.CacheConfig{}
+	classifier, err := services.NewClassifier(config)
+	if err != nil {
+		log.Fatalf("Failed to create classifier: %v", err)
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		log.Fatalf("Failed to open corpus: %v", err)
+	}
+	examples, err := services.ReadFeedbackExamples(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("Failed to read corpus %s: %v", *input, err)
+	}
+	if len(examples) == 0 {
+		log.Fatalf("Corpus %s has no examples", *input)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	report := services.EvaluateClassifier(ctx, classifier, examples, *concurrency)
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		return
+	}
+	printReport(os.Stdout, report, *mistakes)
+}
+
+// printReport writes a human-readable report
+func printReport(w io.Writer, report *services.EvaluationReport, mistakes int) {
+	fmt.Fprintf(w, "Provider:    %s\n", report.Provider)
+	fmt.Fprintf(w, "Examples:    %d (%d errors, %d fallbacks)\n", report.Examples, report.Errors, report.Fallbacks)
+	fmt.Fprintf(w, "Exact match: %.1f%%\n", report.ExactMatch*100)
+	fmt.Fprintf(w, "Latency:     mean %s, p50 %s, p95 %s, max %s\n",
+		report.Latency.Mean.Round(time.Microsecond), report.Latency.P50.Round(time.Microsecond),
+		report.Latency.P95.Round(time.Microsecond), report.Latency.Max.Round(time.Microsecond))
+
+	printLabel(w, "Severity", report.Severity)
+	printLabel(w, "Category", report.Category)
+
+	if mistakes > 0 && len(report.Mistakes) > 0 {
+		fmt.Fprintf(w, "\nMistakes (%d):\n", len(report.Mistakes))
+		for i, mistake := range report.Mistakes {
+			if i == mistakes {
+				fmt.Fprintf(w, "  ... %d more\n", len(report.Mistakes)-mistakes)
+				break
+			}
+			if mistake.Error != "" {
+				fmt.Fprintf(w, "  %q: error: %s\n", mistake.Title, mistake.Error)
+				continue
+			}
+			fmt.Fprintf(w, "  %q: expected %s/%s, got %s/%s\n", mistake.Title,
+				mistake.ExpectedSeverity, mistake.ExpectedCategory, mistake.PredictedSeverity, mistake.PredictedCategory)
+		}
+	}
+}
+
+// printLabel writes the accuracy, per-class scores and confusion matrix of one label
+func printLabel(w io.Writer, name string, metrics services.LabelMetrics) {
+	fmt.Fprintf(w, "\n%s accuracy: %.1f%%\n\n", name, metrics.Accuracy*100)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "class\tprecision\trecall\tf1\tsupport\t")
+	for _, class := range metrics.Classes {
+		fmt.Fprintf(table, "%s\t%.2f\t%.2f\t%.2f\t%d\t\n", class.Label, class.Precision, class.Recall, class.F1, class.Support)
+	}
+	table.Flush()
+
+	fmt.Fprintln(w, "\nConfusion matrix (rows: expected, columns: predicted)")
+	table = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "\t"+strings.Join(metrics.Labels, "\t")+"\t")
+	for _, expected := range metrics.Labels {
+		row := []string{expected}
+		for _, predicted := range metrics.Labels {
+			row = append(row, fmt.Sprint(metrics.Confusion[expected][predicted]))
+		}
+		fmt.Fprintln(table, strings.Join(row, "\t")+"\t")
+	}
+	table.Flush()
+}
@@ -10,6 +10,10 @@ import (
 
 var DB *gorm.DB
 
+// FTSEnabled reports whether the SQLite driver supports FTS5 full-text search.
+// FTS5 is only compiled in when building with -tags sqlite_fts5.
+var FTSEnabled bool
+
 // InitDB initializes the database connection and runs migrations
 func InitDB() error {
 	var err error
@@ -19,11 +23,34 @@ func InitDB() error {
 	}
 
 	// Auto migrate the schema
-	err = DB.AutoMigrate(&model.Incident{})
+	err = DB.AutoMigrate(
+		&model.Incident{},
+		&model.StatusTransition{},
+		&model.ClassificationJob{},
+		&model.Classification{},
+		&model.ClassificationOverride{},
+		&model.ReclassificationJob{},
+		&model.TaxonomyTerm{},
+		&model.ClassificationCacheEntry{},
+		&model.IncidentSummary{},
+		&model.IncidentEmbedding{},
+		&model.IncidentOccurrence{},
+		&model.AIUsage{},
+		&model.RemediationSuggestion{},
+		&model.IncidentPostmortem{},
+		&model.Comment{},
+		&model.IncidentChange{},
+	)
 	if err != nil {
 		return err
 	}
 
+	setupSearchIndex()
+
+	if err := seedTaxonomy(); err != nil {
+		return err
+	}
+
 	log.Println("Database initialized successfully")
 	return nil
 }
@@ -32,3 +59,40 @@ func InitDB() error {
 func GetDB() *gorm.DB {
 	return DB
 }
+
+// setupSearchIndex creates the FTS5 index over incident titles and descriptions
+// and backfills any incidents that are not indexed yet
+func setupSearchIndex() {
+	err := DB.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS incidents_fts USING fts5(
+		incident_id UNINDEXED,
+		title,
+		description,
+		tokenize = 'porter unicode61'
+	)`).Error
+	if err != nil {
+		log.Println("FTS5 unavailable, search will fall back to LIKE queries:", err)
+		FTSEnabled = false
+		return
+	}
+	FTSEnabled = true
+
+	err = DB.Exec(`INSERT INTO incidents_fts (incident_id, title, description)
+		SELECT id, title, description FROM incidents
+		WHERE id NOT IN (SELECT incident_id FROM incidents_fts)`).Error
+	if err != nil {
+		log.Println("Failed to backfill search index:", err)
+	}
+}
+
+// seedTaxonomy stores the built-in severities and categories when no taxonomy exists yet
+func seedTaxonomy() error {
+	var count int64
+	if err := DB.Model(&model.TaxonomyTerm{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	terms := model.DefaultTaxonomyTerms()
+	return DB.Create(&terms).Error
+}
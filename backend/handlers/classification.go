@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"incident-management/repository"
+	"incident-management/services"
+	"incident-management/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClassificationHandler struct {
+	service *services.ClassificationService
+	bulk    *services.BulkReclassifier
+}
+
+// overrideRequest is the body of POST /incidents/:id/overrides
+type overrideRequest struct {
+	Severity string `json:"severity"`
+	Category string `json:"category"`
+	Reason   string `json:"reason" validate:"required,max=1000"`
+}
+
+// reclassifyRequest is the body of POST /classification/reclassify
+type reclassifyRequest struct {
+	Status        []string   `json:"status"`
+	Priority      []string   `json:"priority"`
+	AISeverity    []string   `json:"ai_severity"`
+	AICategory    []string   `json:"ai_category"`
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
+	DryRun        bool       `json:"dry_run"`
+	RateLimit     float64    `json:"rate_limit"`
+}
+
+// NewClassificationHandler creates a new classification handler
+func NewClassificationHandler() *ClassificationHandler {
+	service := services.NewClassificationService(services.NewClassifierFromEnv())
+	return &ClassificationHandler{
+		service: service,
+		bulk:    services.NewBulkReclassifier(service, services.ReclassifyRateLimitFromEnv()),
+	}
+}
+
+// GetClassification handles GET /incidents/:id/classification
+func (h *ClassificationHandler) GetClassification(c *gin.Context) {
+	classification, err := h.service.GetLatestClassification(c.Param("id"))
+	if errors.Is(err, services.ErrNotClassified) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Classification not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve classification")
+		return
+	}
+	c.JSON(http.StatusOK, classification)
+}
+
+// ClassifyIncident handles POST /incidents/:id/classify, re-running the classifier synchronously
+func (h *ClassificationHandler) ClassifyIncident(c *gin.Context) {
+	incident, classification, err := h.service.ClassifyIncident(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to classify incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"incident":       incident,
+		"classification": classification,
+	})
+}
+
+// StartReclassification handles POST /classification/reclassify
+func (h *ClassificationHandler) StartReclassification(c *gin.Context) {
+	var request reclassifyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	job, err := h.bulk.Start(services.ReclassifyOptions{
+		Filter: repository.IncidentFilter{
+			Status:        request.Status,
+			Priority:      request.Priority,
+			AISeverity:    request.AISeverity,
+			AICategory:    request.AICategory,
+			CreatedAfter:  request.CreatedAfter,
+			CreatedBefore: request.CreatedBefore,
+		},
+		DryRun:    request.DryRun,
+		RateLimit: request.RateLimit,
+		Actor:     actorFromRequest(c),
+	})
+	if err != nil {
+		respondIncidentError(c, err, "Failed to start reclassification")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetReclassifications handles GET /classification/reclassify
+func (h *ClassificationHandler) GetReclassifications(c *gin.Context) {
+	jobs, err := h.bulk.ListJobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve reclassification jobs",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetReclassification handles GET /classification/reclassify/:id
+func (h *ClassificationHandler) GetReclassification(c *gin.Context) {
+	job, err := h.bulk.GetJob(c.Param("id"))
+	if err != nil {
+		respondReclassificationError(c, err, "Failed to retrieve reclassification job")
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelReclassification handles POST /classification/reclassify/:id/cancel
+func (h *ClassificationHandler) CancelReclassification(c *gin.Context) {
+	if err := h.bulk.Cancel(c.Param("id")); err != nil {
+		respondReclassificationError(c, err, "Failed to cancel reclassification job")
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Cancellation requested"})
+}
+
+// OverrideClassification handles POST /incidents/:id/overrides
+func (h *ClassificationHandler) OverrideClassification(c *gin.Context) {
+	var request overrideRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	validationErrors := utils.ValidateAndGetErrors(&request)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	incident, override, err := h.service.OverrideClassification(c.Param("id"), request.Severity, request.Category, request.Reason, actorFromRequest(c))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to override classification")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"incident": incident,
+		"override": override,
+	})
+}
+
+// GetOverrides handles GET /incidents/:id/overrides
+func (h *ClassificationHandler) GetOverrides(c *gin.Context) {
+	overrides, err := h.service.GetOverrides(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve overrides")
+		return
+	}
+	c.JSON(http.StatusOK, overrides)
+}
+
+// ExportFeedback handles GET /classification/feedback, streaming the labeled dataset as JSON Lines
+func (h *ClassificationHandler) ExportFeedback(c *gin.Context) {
+	examples, err := h.service.FeedbackExamples()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to export feedback",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="classification-feedback.jsonl"`)
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	for _, example := range examples {
+		if err := encoder.Encode(example); err != nil {
+			return
+		}
+	}
+}
+
+// respondReclassificationError maps bulk job errors to HTTP responses
+func respondReclassificationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Reclassification job not found",
+			"details": "no reclassification job exists with id " + c.Param("id"),
+		})
+	case errors.Is(err, services.ErrJobNotRunning):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Reclassification job is not running",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
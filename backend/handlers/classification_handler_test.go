@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetClassificationEndpoint(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	classificationHandler := NewClassificationHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.GET("/api/v1/incidents/:id/classification", classificationHandler.GetClassification)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler classification incident",
+		Description: "Database connection pool exhausted",
+	})
+
+	// Nothing has been recorded yet
+	req, _ := http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/classification", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	if _, _, err := classificationHandler.service.ClassifyIncident(context.Background(), created.ID); err != nil {
+		t.Fatalf("Failed to classify incident: %v", err)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/classification", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var classification model.Classification
+	if err := json.Unmarshal(w.Body.Bytes(), &classification); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if classification.IncidentID != created.ID {
+		t.Errorf("Expected incident %s, got %s", created.ID, classification.IncidentID)
+	}
+	if classification.Provider == "" || classification.Severity == "" || classification.Category == "" {
+		t.Errorf("Expected provider, severity and category to be set, got %+v", classification)
+	}
+
+	// Unknown incidents are reported as not found
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/00000000-0000-4000-8000-000000000000/classification", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestOverrideEndpoints(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	classificationHandler := NewClassificationHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.POST("/api/v1/incidents/:id/overrides", classificationHandler.OverrideClassification)
+	router.GET("/api/v1/incidents/:id/overrides", classificationHandler.GetOverrides)
+	router.GET("/api/v1/classification/feedback", classificationHandler.ExportFeedback)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler override incident",
+		Description: "Disk array controller failed",
+	})
+
+	// A reason is required
+	req, _ := http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/overrides", strings.NewReader(`{"category": "hardware"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/overrides", strings.NewReader(`{"category": "hardware", "reason": "Controller failure"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Actor", "oncall")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/overrides", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var overrides []model.ClassificationOverride
+	if err := json.Unmarshal(w.Body.Bytes(), &overrides); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].Actor != "oncall" || overrides[0].HumanCategory != "hardware" {
+		t.Fatalf("Unexpected overrides: %+v", overrides)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/classification/feedback", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"incident_id":"`+created.ID+`"`) {
+		t.Errorf("Expected feedback export to contain the incident, got %s", w.Body.String())
+	}
+}
+
+func TestReclassificationEndpoints(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	classificationHandler := NewClassificationHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.POST("/api/v1/incidents/:id/classify", classificationHandler.ClassifyIncident)
+	router.POST("/api/v1/classification/reclassify", classificationHandler.StartReclassification)
+	router.GET("/api/v1/classification/reclassify/:id", classificationHandler.GetReclassification)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler reclassify incident",
+		Description: "Firewall dropped packets to the VPN gateway",
+	})
+
+	req, _ := http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/classify", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/classification/reclassify", strings.NewReader(`{"dry_run": true, "rate_limit": 50}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	var job model.ReclassificationJob
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	classificationHandler.bulk.Wait()
+
+	req, _ = http.NewRequest("GET", "/api/v1/classification/reclassify/"+job.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if job.Status != model.ReclassificationCompleted || !job.DryRun || job.Processed == 0 {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+
+	// Invalid rate limits and unknown jobs are rejected
+	req, _ = http.NewRequest("POST", "/api/v1/classification/reclassify", strings.NewReader(`{"rate_limit": -1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/classification/reclassify/unknown", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"incident-management/services"
+	"incident-management/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	service *services.CommentService
+}
+
+// commentRequest is the body of POST /incidents/:id/comments; the service enforces the length limit
+type commentRequest struct {
+	Body string `json:"body" validate:"required"`
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler() *CommentHandler {
+	return &CommentHandler{
+		service: services.NewCommentService(),
+	}
+}
+
+// AddComment handles POST /incidents/:id/comments; the author is the X-Actor header
+func (h *CommentHandler) AddComment(c *gin.Context) {
+	var request commentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	validationErrors := utils.ValidateAndGetErrors(&request)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	comment, err := h.service.AddComment(c.Param("id"), request.Body, actorFromRequest(c))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to add comment")
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// GetComments handles GET /incidents/:id/comments
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	comments, err := h.service.GetComments(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve comments")
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// DeleteComment handles DELETE /incidents/:id/comments/:comment_id
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	err := h.service.DeleteComment(c.Param("id"), c.Param("comment_id"))
+	if errors.Is(err, services.ErrNoComment) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Comment not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to delete comment")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetActivity handles GET /incidents/:id/activity[?type=comment,transition]
+func (h *CommentHandler) GetActivity(c *gin.Context) {
+	activity, err := h.service.GetActivity(c.Param("id"), splitQueryList(c.Query("type")))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve activity")
+		return
+	}
+	c.JSON(http.StatusOK, activity)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCommentEndpoints(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	commentHandler := NewCommentHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.POST("/api/v1/incidents/:id/comments", commentHandler.AddComment)
+	router.GET("/api/v1/incidents/:id/comments", commentHandler.GetComments)
+	router.DELETE("/api/v1/incidents/:id/comments/:comment_id", commentHandler.DeleteComment)
+	router.GET("/api/v1/incidents/:id/activity", commentHandler.GetActivity)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler comment incident",
+		Description: "Reports dashboard shows no data",
+	})
+
+	addComment := func(id, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/incidents/"+id+"/comments", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Actor", "alice")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := addComment(created.ID, `{"body": "Warehouse load job failed at 02:00"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var comment model.Comment
+	if err := json.Unmarshal(w.Body.Bytes(), &comment); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if comment.Author != "alice" || comment.IncidentID != created.ID {
+		t.Errorf("Unexpected comment: %+v", comment)
+	}
+
+	longBody, _ := json.Marshal(map[string]string{"body": strings.Repeat("a", 10001)})
+	for _, body := range []string{`{}`, `{"body": "   "}`, string(longBody)} {
+		if w = addComment(created.ID, body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %.20s, got %d", http.StatusBadRequest, body, w.Code)
+		}
+	}
+	if w = addComment("non-existent-id", `{"body": "Hello"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown incident, got %d", http.StatusNotFound, w.Code)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/activity", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var activity []services.ActivityEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &activity); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(activity) != 2 || activity[0].Type != services.ActivityCreated || activity[1].Type != services.ActivityComment {
+		t.Errorf("Expected the creation then the comment, got %s", w.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/activity?type=email", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown type, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/incidents/"+created.ID+"/comments/"+comment.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+	req, _ = http.NewRequest("DELETE", "/api/v1/incidents/"+created.ID+"/comments/"+comment.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a deleted comment, got %d", http.StatusNotFound, w.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/comments", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Body.String() != "[]" {
+		t.Errorf("Expected no comments left, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetHistory(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	handler := NewIncidentHandler()
+	router := setupIncidentRouter(handler)
+	router.GET("/api/v1/incidents/:id/history", handler.GetHistory)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler history incident",
+		Description: "Mail relay rejects outbound messages",
+	})
+
+	req, _ := http.NewRequest("PATCH", "/api/v1/incidents/"+created.ID, bytes.NewBufferString(`{"priority": "critical"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("X-Actor", "alice")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/history?field=priority", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var history []model.IncidentChange
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected one priority change, got %+v", history)
+	}
+	if change := history[0]; change.OldValue != "medium" || change.NewValue != "critical" || change.Actor != "alice" || change.Source != model.ChangeSourceAPI {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+
+	for path, status := range map[string]int{
+		"/api/v1/incidents/" + created.ID + "/history?source=email": http.StatusBadRequest,
+		"/api/v1/incidents/non-existent-id/history":                 http.StatusNotFound,
+	} {
+		req, _ = http.NewRequest("GET", path, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("Expected status %d for %s, got %d", status, path, w.Code)
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"incident-management/model"
+	"incident-management/repository"
 	"incident-management/services"
 	"incident-management/utils"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +19,12 @@ type IncidentHandler struct {
 	service *services.IncidentService
 }
 
+// transitionRequest is the body of POST /incidents/:id/transitions
+type transitionRequest struct {
+	Action string `json:"action" validate:"required"`
+	Reason string `json:"reason" validate:"max=1000"`
+}
+
 // NewIncidentHandler creates a new incident handler
 func NewIncidentHandler() *IncidentHandler {
 	return &IncidentHandler{
@@ -58,27 +70,340 @@ func (h *IncidentHandler) CreateIncident(c *gin.Context) {
 		return
 	}
 
+	// A repeat report was attached to an existing incident rather than creating one
+	if createdIncident.Deduplicated {
+		c.JSON(http.StatusOK, createdIncident)
+		return
+	}
 	c.JSON(http.StatusCreated, createdIncident)
 }
 
-// GetAllIncidents handles GET /incidents
+// GetAllIncidents handles GET /incidents with filtering, sorting and cursor pagination
 func (h *IncidentHandler) GetAllIncidents(c *gin.Context) {
-	incidents, err := h.service.GetAllIncidents()
+	filter, validationErrors := parseIncidentFilter(c)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	page, err := h.service.ListIncidents(filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve incidents",
+		respondIncidentError(c, err, "Failed to retrieve incidents")
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// SearchIncidents handles GET /incidents/search
+func (h *IncidentHandler) SearchIncidents(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": map[string]string{"q": "q is required"},
+		})
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"details": map[string]string{"limit": "limit must be a positive integer"},
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.service.SearchIncidents(query, limit)
+	if err != nil {
+		respondIncidentError(c, err, "Failed to search incidents")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   results,
+		"query":  query,
+		"engine": repository.SearchEngine(),
+	})
+}
+
+// GetIncident handles GET /incidents/:id
+func (h *IncidentHandler) GetIncident(c *gin.Context) {
+	incident, err := h.service.GetIncident(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve incident")
+		return
+	}
+	c.JSON(http.StatusOK, incident)
+}
+
+// UpdateIncident handles PUT /incidents/:id
+func (h *IncidentHandler) UpdateIncident(c *gin.Context) {
+	var incident model.Incident
+	if err := c.ShouldBindJSON(&incident); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// The path parameter is authoritative for the incident ID
+	incident.ID = ""
+	validationErrors := utils.ValidateAndGetErrors(&incident)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	updatedIncident, err := h.service.UpdateIncident(c.Param("id"), incident, actorFromRequest(c))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to update incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedIncident)
+}
+
+// PatchIncident handles PATCH /incidents/:id using JSON Merge Patch semantics
+func (h *IncidentHandler) PatchIncident(c *gin.Context) {
+	patch, err := c.GetRawData()
+	if err != nil || !json.Valid(patch) {
+		details := "request body is not valid JSON"
+		if err != nil {
+			details = err.Error()
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": details,
+		})
+		return
+	}
+
+	patchedIncident, err := h.service.PatchIncident(c.Param("id"), patch, actorFromRequest(c))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to update incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, patchedIncident)
+}
+
+// DeleteIncident handles DELETE /incidents/:id
+func (h *IncidentHandler) DeleteIncident(c *gin.Context) {
+	if err := h.service.DeleteIncident(c.Param("id")); err != nil {
+		respondIncidentError(c, err, "Failed to delete incident")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// TransitionIncident handles POST /incidents/:id/transitions
+func (h *IncidentHandler) TransitionIncident(c *gin.Context) {
+	var request transitionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
 			"details": err.Error(),
 		})
 		return
 	}
-	c.JSON(http.StatusOK, incidents)
+
+	validationErrors := utils.ValidateAndGetErrors(&request)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	incident, err := h.service.TransitionIncident(c.Param("id"), request.Action, request.Reason, actorFromRequest(c))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to transition incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
 }
 
-// HealthCheck handles GET /health
+// GetTransitions handles GET /incidents/:id/transitions
+func (h *IncidentHandler) GetTransitions(c *gin.Context) {
+	transitions, err := h.service.GetTransitions(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve transitions")
+		return
+	}
+	c.JSON(http.StatusOK, transitions)
+}
+
+// GetOccurrences handles GET /incidents/:id/occurrences
+func (h *IncidentHandler) GetOccurrences(c *gin.Context) {
+	occurrences, err := h.service.GetOccurrences(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve occurrences")
+		return
+	}
+	c.JSON(http.StatusOK, occurrences)
+}
+
+// GetHistory handles GET /incidents/:id/history[?field=priority&source=api]
+func (h *IncidentHandler) GetHistory(c *gin.Context) {
+	history, err := h.service.GetHistory(c.Param("id"), c.Query("field"), c.Query("source"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve history")
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// HealthCheck handles GET /health. The status is "degraded" while an AI provider's
+// circuit breaker is not closed or the monthly AI budget is spent; incidents are still
+// classified by the fallback.
 func (h *IncidentHandler) HealthCheck(c *gin.Context) {
+	status := "ok"
+	breakers := services.CircuitBreakerStatuses()
+	for _, breaker := range breakers {
+		if breaker.State != services.BreakerClosed {
+			status = "degraded"
+		}
+	}
+	budget := services.AIBudgetStatus()
+	if budget.Exceeded {
+		status = "degraded"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "ok",
+		"status":  status,
 		"message": "Incident Management API is running",
 		"version": "1.0.0",
+		"classifier": gin.H{
+			"circuit_breakers": breakers,
+			"cache":            services.ClassificationCacheStats(),
+			"budget":           budget,
+		},
 	})
 }
+
+// respondIncidentError maps service errors onto HTTP error responses
+func respondIncidentError(c *gin.Context, err error, message string) {
+	var validationErr *services.ValidationError
+	var transitionErr *services.TransitionError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Incident not found",
+			"details": "no incident exists with id " + c.Param("id"),
+		})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErr.Details,
+		})
+	case errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid cursor",
+			"details": err.Error(),
+		})
+	case errors.Is(err, repository.ErrInvalidSearchQuery):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid search query",
+			"details": err.Error(),
+		})
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":           "Invalid status transition",
+			"details":         transitionErr.Error(),
+			"allowed_actions": transitionErr.AllowedActions,
+		})
+	case errors.Is(err, services.ErrUnknownAction):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Unknown transition action",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// actorFromRequest identifies who is making the request, defaulting to anonymous
+func actorFromRequest(c *gin.Context) string {
+	if actor := strings.TrimSpace(c.GetHeader("X-Actor")); actor != "" {
+		return actor
+	}
+	return "anonymous"
+}
+
+// parseIncidentFilter reads list filters from the query string
+func parseIncidentFilter(c *gin.Context) (repository.IncidentFilter, map[string]string) {
+	filter := repository.IncidentFilter{
+		Status:     splitQueryList(c.Query("status")),
+		Priority:   splitQueryList(c.Query("priority")),
+		AISeverity: splitQueryList(c.Query("ai_severity")),
+		AICategory: splitQueryList(c.Query("ai_category")),
+		SortBy:     c.Query("sort"),
+		Cursor:     c.Query("cursor"),
+	}
+	errors := make(map[string]string)
+
+	switch strings.ToLower(c.DefaultQuery("order", "desc")) {
+	case "asc":
+		filter.SortDesc = false
+	case "desc":
+		filter.SortDesc = true
+	default:
+		errors["order"] = "order must be one of: asc desc"
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			errors["limit"] = "limit must be a positive integer"
+		} else {
+			filter.Limit = value
+		}
+	}
+
+	for param, target := range map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+	} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				errors[param] = param + " must be an RFC 3339 timestamp"
+				continue
+			}
+			*target = &parsed
+		}
+	}
+
+	if len(errors) > 0 {
+		return filter, errors
+	}
+	return filter, nil
+}
+
+// splitQueryList splits a comma-separated query parameter into trimmed values
+func splitQueryList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
@@ -239,14 +239,18 @@ func TestGetAllIncidents(t *testing.T) {
 	}
 
 	// Parse response body
-	var response []model.Incident
+	var response struct {
+		Data       []model.Incident `json:"data"`
+		NextCursor string           `json:"next_cursor"`
+		Total      int64            `json:"total"`
+	}
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	if err != nil {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 
-	// Response should be an array (even if empty)
-	if response == nil {
+	// Data should be an array (even if empty)
+	if response.Data == nil {
 		t.Error("Expected incidents array, got nil")
 	}
 }
@@ -294,11 +298,337 @@ func TestHealthCheck(t *testing.T) {
 		t.Errorf("Expected message 'Incident Management API is running', got '%v'", response["message"])
 	}
 
+	classifier, ok := response["classifier"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected classifier health, got %v", response["classifier"])
+	}
+	if _, ok := classifier["circuit_breakers"].([]interface{}); !ok {
+		t.Errorf("Expected a list of circuit breakers, got %v", classifier["circuit_breakers"])
+	}
+
 	if response["version"] != "1.0.0" {
 		t.Errorf("Expected version '1.0.0', got '%v'", response["version"])
 	}
 }
 
+func setupIncidentRouter(handler *IncidentHandler) *gin.Engine {
+	router := gin.New()
+	router.POST("/api/v1/incidents", handler.CreateIncident)
+	router.GET("/api/v1/incidents/:id", handler.GetIncident)
+	router.PUT("/api/v1/incidents/:id", handler.UpdateIncident)
+	router.PATCH("/api/v1/incidents/:id", handler.PatchIncident)
+	router.DELETE("/api/v1/incidents/:id", handler.DeleteIncident)
+	return router
+}
+
+func createIncidentThroughRouter(t *testing.T, router *gin.Engine, incident model.Incident) model.Incident {
+	jsonData, err := json.Marshal(incident)
+	if err != nil {
+		t.Fatalf("Failed to marshal incident: %v", err)
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/incidents", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var created model.Incident
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return created
+}
+
+func TestSingleIncidentEndpoints(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	router := setupIncidentRouter(NewIncidentHandler())
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Single Incident Endpoint Test",
+		Description: "Incident used to test single-incident endpoints",
+		Priority:    "low",
+	})
+
+	// GET returns the incident
+	req, _ := http.NewRequest("GET", "/api/v1/incidents/"+created.ID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected GET status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// PUT replaces the incident
+	putBody := `{"title": "Replaced Title", "description": "Replaced description", "priority": "high"}`
+	req, _ = http.NewRequest("PUT", "/api/v1/incidents/"+created.ID, bytes.NewBufferString(putBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected PUT status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// PATCH updates a single field
+	req, _ = http.NewRequest("PATCH", "/api/v1/incidents/"+created.ID, bytes.NewBufferString(`{"priority": "critical"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected PATCH status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var patched model.Incident
+	if err := json.Unmarshal(w.Body.Bytes(), &patched); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if patched.Priority != "critical" {
+		t.Errorf("Expected priority 'critical', got '%s'", patched.Priority)
+	}
+	if patched.Title != "Replaced Title" {
+		t.Errorf("Expected title 'Replaced Title', got '%s'", patched.Title)
+	}
+
+	// PATCH with an invalid value fails validation
+	req, _ = http.NewRequest("PATCH", "/api/v1/incidents/"+created.ID, bytes.NewBufferString(`{"status": "unknown"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected PATCH validation status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// DELETE removes the incident
+	req, _ = http.NewRequest("DELETE", "/api/v1/incidents/"+created.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected DELETE status %d, got %d", http.StatusNoContent, w.Code)
+	}
+}
+
+func TestSingleIncidentEndpoints_NotFound(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	router := setupIncidentRouter(NewIncidentHandler())
+	unknownID := "3f1c2b4a-9d8e-4f7a-8b6c-5d4e3f2a1b0c"
+
+	tests := []struct {
+		method string
+		body   string
+	}{
+		{"GET", ""},
+		{"PUT", `{"title": "Missing", "description": "Missing incident"}`},
+		{"PATCH", `{"priority": "high"}`},
+		{"DELETE", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/api/v1/incidents/"+unknownID, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+			}
+		})
+	}
+}
+
+func TestTransitionIncidentEndpoint(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	handler := NewIncidentHandler()
+	router := setupIncidentRouter(handler)
+	router.POST("/api/v1/incidents/:id/transitions", handler.TransitionIncident)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Transition Endpoint Test",
+		Description: "Incident used to test the transitions endpoint",
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"Resolve", `{"action": "resolve", "reason": "Fixed"}`, http.StatusOK},
+		{"Illegal start", `{"action": "start"}`, http.StatusConflict},
+		{"Unknown action", `{"action": "escalate"}`, http.StatusBadRequest},
+		{"Missing action", `{}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/transitions", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Actor", "handler-test")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateIncident_Duplicate(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	t.Setenv("INCIDENT_DEDUP_WINDOW", "1h")
+
+	handler := NewIncidentHandler()
+	router := setupIncidentRouter(handler)
+	router.GET("/api/v1/incidents/:id/occurrences", handler.GetOccurrences)
+
+	report := model.Incident{
+		Title:       "Duplicate Endpoint Test",
+		Description: "Incident used to test deduplication",
+		DedupKey:    "handler-dedup-test",
+	}
+	created := createIncidentThroughRouter(t, router, report)
+
+	jsonData, _ := json.Marshal(report)
+	req, _ := http.NewRequest("POST", "/api/v1/incidents", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d for a duplicate, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var duplicate model.Incident
+	if err := json.Unmarshal(w.Body.Bytes(), &duplicate); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if duplicate.ID != created.ID || !duplicate.Deduplicated || duplicate.OccurrenceCount != 2 {
+		t.Errorf("Expected the report to be attached to %s, got %+v", created.ID, duplicate)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/occurrences", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var occurrences []model.IncidentOccurrence
+	if err := json.Unmarshal(w.Body.Bytes(), &occurrences); err != nil || len(occurrences) != 1 {
+		t.Errorf("Expected one occurrence, got %s", w.Body.String())
+	}
+}
+
+func TestGetAllIncidents_InvalidQuery(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	handler := NewIncidentHandler()
+
+	tests := []string{
+		"/api/v1/incidents?limit=0",
+		"/api/v1/incidents?sort=description",
+		"/api/v1/incidents?order=sideways",
+		"/api/v1/incidents?created_after=yesterday",
+		"/api/v1/incidents?cursor=bogus",
+	}
+
+	for _, url := range tests {
+		t.Run(url, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", url, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.GetAllIncidents(c)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestSearchIncidentsEndpoint(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	handler := NewIncidentHandler()
+	router := setupIncidentRouter(handler)
+	router.GET("/api/v1/incidents/search", handler.SearchIncidents)
+
+	createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler search resolver timeout",
+		Description: "DNS lookups timed out for the billing service",
+	})
+
+	req, _ := http.NewRequest("GET", "/api/v1/incidents/search?q=resolver", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data []struct {
+			Incident       model.Incident `json:"incident"`
+			TitleHighlight string         `json:"title_highlight"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Data) == 0 {
+		t.Fatal("Expected at least one search result")
+	}
+
+	// A missing query is rejected
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/search", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Clean up test database before running tests
 	os.Remove("incidents.db")
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"incident-management/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PostmortemHandler struct {
+	service *services.PostmortemService
+}
+
+// NewPostmortemHandler creates a new postmortem handler
+func NewPostmortemHandler() *PostmortemHandler {
+	return &PostmortemHandler{
+		service: services.NewPostmortemService(services.NewPostmortemWriterFromEnv()),
+	}
+}
+
+// GetPostmortem handles GET /incidents/:id/postmortem; ?version=n selects an earlier version
+// and ?format=markdown returns the document itself
+func (h *PostmortemHandler) GetPostmortem(c *gin.Context) {
+	details := make(map[string]string)
+	version := 0
+	if raw := c.Query("version"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			details["version"] = "must be a positive integer"
+		}
+		version = parsed
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "markdown" {
+		details["format"] = "must be json or markdown"
+	}
+	if len(details) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": details,
+		})
+		return
+	}
+
+	postmortem, err := h.service.GetPostmortem(c.Param("id"), version)
+	if errors.Is(err, services.ErrNoPostmortem) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Postmortem not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve postmortem")
+		return
+	}
+	if format == "markdown" {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(postmortem.Markdown))
+		return
+	}
+	c.JSON(http.StatusOK, postmortem)
+}
+
+// GetPostmortems handles GET /incidents/:id/postmortems
+func (h *PostmortemHandler) GetPostmortems(c *gin.Context) {
+	postmortems, err := h.service.GetPostmortems(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve postmortems")
+		return
+	}
+	c.JSON(http.StatusOK, postmortems)
+}
+
+// DraftPostmortem handles POST /incidents/:id/postmortem, writing a new postmortem version
+func (h *PostmortemHandler) DraftPostmortem(c *gin.Context) {
+	postmortem, err := h.service.DraftPostmortem(c.Request.Context(), c.Param("id"), actorFromRequest(c))
+	if errors.Is(err, services.ErrIncidentNotResolved) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Incident not resolved",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to draft postmortem")
+		return
+	}
+	c.JSON(http.StatusCreated, postmortem)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPostmortemEndpoints(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	t.Setenv("AI_PROVIDER", "rules")
+
+	incidentHandler := NewIncidentHandler()
+	postmortemHandler := NewPostmortemHandler()
+	router := setupIncidentRouter(incidentHandler)
+	router.POST("/api/v1/incidents/:id/transitions", incidentHandler.TransitionIncident)
+	router.GET("/api/v1/incidents/:id/postmortem", postmortemHandler.GetPostmortem)
+	router.POST("/api/v1/incidents/:id/postmortem", postmortemHandler.DraftPostmortem)
+	router.GET("/api/v1/incidents/:id/postmortems", postmortemHandler.GetPostmortems)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler postmortem incident",
+		Description: "Image uploads time out",
+	})
+
+	req, _ := http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/postmortem", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d for an open incident, got %d", http.StatusConflict, w.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/transitions", bytes.NewBufferString(`{"action": "resolve", "reason": "Raised the upload timeout"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/postmortem", nil)
+	req.Header.Set("X-Actor", "commander")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var postmortem model.IncidentPostmortem
+	if err := json.Unmarshal(w.Body.Bytes(), &postmortem); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if postmortem.Version != 1 || postmortem.Actor != "commander" || postmortem.Provider != "local" {
+		t.Errorf("Unexpected postmortem: %+v", postmortem)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/postmortem?format=markdown", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/markdown") {
+		t.Fatalf("Expected a markdown document, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.HasPrefix(w.Body.String(), "# Postmortem: Handler postmortem incident") {
+		t.Errorf("Unexpected document:\n%s", w.Body.String())
+	}
+
+	for _, query := range []string{"version=zero", "format=pdf"} {
+		req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/postmortem?"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/postmortems", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var postmortems []model.IncidentPostmortem
+	if err := json.Unmarshal(w.Body.Bytes(), &postmortems); err != nil || len(postmortems) != 1 {
+		t.Errorf("Expected 1 postmortem version, got %s", w.Body.String())
+	}
+}
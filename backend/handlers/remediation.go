@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"incident-management/services"
+	"incident-management/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RemediationHandler struct {
+	service *services.RemediationService
+}
+
+// remediationFeedbackRequest is the body of POST /incidents/:id/remediation/:suggestion_id/feedback
+type remediationFeedbackRequest struct {
+	Helpful *bool  `json:"helpful" validate:"required"`
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+// NewRemediationHandler creates a new remediation suggestion handler
+func NewRemediationHandler() *RemediationHandler {
+	return &RemediationHandler{
+		service: services.NewRemediationService(services.NewRemediatorFromEnv(), services.NewEmbedderFromEnv()),
+	}
+}
+
+// SuggestRemediation handles POST /incidents/:id/remediation, storing a new suggestion
+func (h *RemediationHandler) SuggestRemediation(c *gin.Context) {
+	suggestion, err := h.service.SuggestRemediation(c.Request.Context(), c.Param("id"), actorFromRequest(c))
+	if errors.Is(err, services.ErrEmbeddingUnavailable) {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Embedding provider unavailable",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to suggest remediation")
+		return
+	}
+	c.JSON(http.StatusCreated, suggestion)
+}
+
+// GetSuggestions handles GET /incidents/:id/remediation
+func (h *RemediationHandler) GetSuggestions(c *gin.Context) {
+	suggestions, err := h.service.GetSuggestions(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve remediation suggestions")
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// RecordFeedback handles POST /incidents/:id/remediation/:suggestion_id/feedback
+func (h *RemediationHandler) RecordFeedback(c *gin.Context) {
+	var request remediationFeedbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	validationErrors := utils.ValidateAndGetErrors(&request)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	suggestion, err := h.service.RecordFeedback(c.Param("id"), c.Param("suggestion_id"), *request.Helpful, request.Comment, actorFromRequest(c))
+	if errors.Is(err, services.ErrNoSuggestion) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Suggestion not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to record feedback")
+		return
+	}
+	c.JSON(http.StatusOK, suggestion)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRemediationSuggestions(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	t.Setenv("AI_PROVIDER", "rules")
+	t.Setenv("AI_EMBEDDING_PROVIDER", "")
+
+	remediationHandler := NewRemediationHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.POST("/api/v1/incidents/:id/remediation", remediationHandler.SuggestRemediation)
+	router.GET("/api/v1/incidents/:id/remediation", remediationHandler.GetSuggestions)
+	router.POST("/api/v1/incidents/:id/remediation/:suggestion_id/feedback", remediationHandler.RecordFeedback)
+
+	incident := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Narwhal scheduler skipping jobs",
+		Description: "Narwhal scheduler skipped the nightly jobs",
+	})
+
+	req, _ := http.NewRequest("POST", "/api/v1/incidents/"+incident.ID+"/remediation", nil)
+	req.Header.Set("X-Actor", "alice")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var suggestion model.RemediationSuggestion
+	if err := json.Unmarshal(w.Body.Bytes(), &suggestion); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if suggestion.Provider != "local" || suggestion.Actor != "alice" {
+		t.Errorf("Expected a local suggestion by alice, got %s", w.Body.String())
+	}
+
+	feedback := func(suggestionID, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/incidents/"+incident.ID+"/remediation/"+suggestionID+"/feedback", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	if w = feedback(suggestion.ID, `{"helpful": false, "comment": "Not relevant"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w = feedback(suggestion.ID, `{"comment": "No verdict"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without a verdict, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w = feedback("non-existent-id", `{"helpful": true}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown suggestion, got %d", http.StatusNotFound, w.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+incident.ID+"/remediation", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var suggestions []model.RemediationSuggestion
+	if err := json.Unmarshal(w.Body.Bytes(), &suggestions); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Helpful == nil || *suggestions[0].Helpful {
+		t.Errorf("Expected one suggestion marked not helpful, got %s", w.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/incidents/non-existent-id/remediation", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown incident, got %d", http.StatusNotFound, w.Code)
+	}
+}
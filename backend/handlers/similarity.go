@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"incident-management/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SimilarityHandler struct {
+	service *services.SimilarityService
+}
+
+// NewSimilarityHandler creates a new similar-incident handler
+func NewSimilarityHandler() *SimilarityHandler {
+	return &SimilarityHandler{
+		service: services.NewSimilarityService(services.NewEmbedderFromEnv()),
+	}
+}
+
+// GetSimilarIncidents handles GET /incidents/:id/similar?k=5&min_score=0.3
+func (h *SimilarityHandler) GetSimilarIncidents(c *gin.Context) {
+	details := make(map[string]string)
+	k := services.DefaultSimilarLimit
+	if raw := c.Query("k"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > services.MaxSimilarLimit {
+			details["k"] = "must be an integer between 1 and " + strconv.Itoa(services.MaxSimilarLimit)
+		}
+		k = parsed
+	}
+	minScore := 0.0
+	if raw := c.Query("min_score"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			details["min_score"] = "must be a number between 0 and 1"
+		}
+		minScore = parsed
+	}
+	if len(details) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": details,
+		})
+		return
+	}
+
+	similar, err := h.service.FindSimilar(c.Request.Context(), c.Param("id"), k, minScore)
+	if errors.Is(err, services.ErrEmbeddingUnavailable) {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Embedding provider unavailable",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to find similar incidents")
+		return
+	}
+	c.JSON(http.StatusOK, similar)
+}
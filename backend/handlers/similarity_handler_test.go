@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetSimilarIncidents(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	t.Setenv("AI_PROVIDER", "rules")
+	t.Setenv("AI_EMBEDDING_PROVIDER", "")
+
+	similarityHandler := NewSimilarityHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.GET("/api/v1/incidents/:id/similar", similarityHandler.GetSimilarIncidents)
+
+	target := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Wombat queue consumers stalled",
+		Description: "Wombat queue backlog growing, consumers stalled",
+	})
+	related := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Wombat queue consumers stalled again",
+		Description: "Wombat queue backlog, consumers not acking",
+	})
+
+	// The background indexer embeds new incidents; run one pass of it here
+	if _, err := services.NewSimilarityService(services.NewLocalEmbedder()).IndexPending(context.Background(), 1000); err != nil {
+		t.Fatalf("Failed to index incidents: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/incidents/"+target.ID+"/similar?k=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var similar []services.SimilarIncident
+	if err := json.Unmarshal(w.Body.Bytes(), &similar); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(similar) == 0 || len(similar) > 2 || similar[0].Incident.ID != related.ID {
+		t.Errorf("Expected %s as the best match, got %s", related.ID, w.Body.String())
+	}
+
+	for _, query := range []string{"k=0", "k=many", "min_score=1.5"} {
+		req, _ = http.NewRequest("GET", "/api/v1/incidents/"+target.ID+"/similar?"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/non-existent-id/similar", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown incident, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"incident-management/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SummaryHandler struct {
+	service *services.SummaryService
+}
+
+// NewSummaryHandler creates a new incident summary handler
+func NewSummaryHandler() *SummaryHandler {
+	return &SummaryHandler{
+		service: services.NewSummaryService(services.NewSummarizerFromEnv()),
+	}
+}
+
+// GetSummary handles GET /incidents/:id/summary; ?version=n selects an earlier version
+func (h *SummaryHandler) GetSummary(c *gin.Context) {
+	version := 0
+	if raw := c.Query("version"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"details": map[string]string{"version": "must be a positive integer"},
+			})
+			return
+		}
+		version = parsed
+	}
+
+	summary, err := h.service.GetSummary(c.Param("id"), version)
+	if errors.Is(err, services.ErrNoSummary) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Summary not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve summary")
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetSummaries handles GET /incidents/:id/summaries
+func (h *SummaryHandler) GetSummaries(c *gin.Context) {
+	summaries, err := h.service.GetSummaries(c.Param("id"))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve summaries")
+		return
+	}
+	c.JSON(http.StatusOK, summaries)
+}
+
+// GenerateSummary handles POST /incidents/:id/summary, writing a new summary version
+func (h *SummaryHandler) GenerateSummary(c *gin.Context) {
+	summary, err := h.service.GenerateSummary(c.Request.Context(), c.Param("id"), actorFromRequest(c))
+	if err != nil {
+		respondIncidentError(c, err, "Failed to generate summary")
+		return
+	}
+	c.JSON(http.StatusCreated, summary)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSummaryEndpoints(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	summaryHandler := NewSummaryHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.GET("/api/v1/incidents/:id/summary", summaryHandler.GetSummary)
+	router.POST("/api/v1/incidents/:id/summary", summaryHandler.GenerateSummary)
+	router.GET("/api/v1/incidents/:id/summaries", summaryHandler.GetSummaries)
+
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler summary incident",
+		Description: "Search results are stale for all users",
+	})
+
+	req, _ := http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/summary", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d before any summary, got %d", http.StatusNotFound, w.Code)
+	}
+
+	for version := 1; version <= 2; version++ {
+		req, _ = http.NewRequest("POST", "/api/v1/incidents/"+created.ID+"/summary", nil)
+		req.Header.Set("X-Actor", "commander")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		var summary model.IncidentSummary
+		if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if summary.Version != version || summary.Actor != "commander" || summary.Summary == "" {
+			t.Errorf("Unexpected summary: %+v", summary)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/summary?version=1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/summary?version=zero", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid version, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/incidents/"+created.ID+"/summaries", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var summaries []model.IncidentSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summaries); err != nil || len(summaries) != 2 {
+		t.Errorf("Expected 2 summary versions, got %s", w.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/incidents/00000000-0000-4000-8000-000000000000/summary", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown incident, got %d", http.StatusNotFound, w.Code)
+	}
+}
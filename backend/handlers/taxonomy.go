@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"incident-management/model"
+	"incident-management/repository"
+	"incident-management/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaxonomyHandler struct {
+	service *services.TaxonomyService
+}
+
+// NewTaxonomyHandler creates a new taxonomy handler
+func NewTaxonomyHandler() *TaxonomyHandler {
+	return &TaxonomyHandler{
+		service: services.NewTaxonomyService(),
+	}
+}
+
+// GetTaxonomy handles GET /taxonomy
+func (h *TaxonomyHandler) GetTaxonomy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.GetTaxonomy())
+}
+
+// CreateTerm handles POST /taxonomy/:kind
+func (h *TaxonomyHandler) CreateTerm(c *gin.Context) {
+	var term model.TaxonomyTerm
+	if err := c.ShouldBindJSON(&term); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+	term.Kind = c.Param("kind")
+
+	created, err := h.service.CreateTerm(term)
+	if err != nil {
+		respondTaxonomyError(c, err, "Failed to create taxonomy value")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateTerm handles PATCH /taxonomy/:kind/:value
+func (h *TaxonomyHandler) UpdateTerm(c *gin.Context) {
+	var update services.TaxonomyTermUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	term, err := h.service.UpdateTerm(c.Param("kind"), c.Param("value"), update)
+	if err != nil {
+		respondTaxonomyError(c, err, "Failed to update taxonomy value")
+		return
+	}
+	c.JSON(http.StatusOK, term)
+}
+
+// DeleteTerm handles DELETE /taxonomy/:kind/:value
+func (h *TaxonomyHandler) DeleteTerm(c *gin.Context) {
+	if err := h.service.DeleteTerm(c.Param("kind"), c.Param("value")); err != nil {
+		respondTaxonomyError(c, err, "Failed to delete taxonomy value")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondTaxonomyError maps taxonomy errors to HTTP responses
+func respondTaxonomyError(c *gin.Context, err error, message string) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Taxonomy value not found",
+			"details": "no " + c.Param("kind") + " named " + c.Param("value"),
+		})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErr.Details,
+		})
+	case errors.Is(err, services.ErrTaxonomyTermExists), errors.Is(err, services.ErrTaxonomyTermInUse):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTaxonomyEndpoints(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	if err := services.LoadTaxonomy(); err != nil {
+		t.Fatalf("Failed to load taxonomy: %v", err)
+	}
+	t.Cleanup(func() {
+		database.GetDB().Where("kind = ? AND value = ?", model.TaxonomyCategory, "third-party").Delete(&model.TaxonomyTerm{})
+		model.SetTaxonomy(nil)
+	})
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTaxonomyHandler()
+	router := setupIncidentRouter(NewIncidentHandler())
+	router.GET("/api/v1/taxonomy", handler.GetTaxonomy)
+	router.POST("/api/v1/taxonomy/:kind", handler.CreateTerm)
+	router.PATCH("/api/v1/taxonomy/:kind/:value", handler.UpdateTerm)
+	router.DELETE("/api/v1/taxonomy/:kind/:value", handler.DeleteTerm)
+
+	req, _ := http.NewRequest("POST", "/api/v1/taxonomy/category", strings.NewReader(`{"value": "third-party", "description": "Outages of external providers", "position": 5}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/taxonomy", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var taxonomy model.Taxonomy
+	if err := json.Unmarshal(w.Body.Bytes(), &taxonomy); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !taxonomy.Contains(model.TaxonomyCategory, "third-party") {
+		t.Errorf("Expected 'third-party' in categories, got %v", taxonomy.Values(model.TaxonomyCategory))
+	}
+
+	// Incidents accept the new category right away
+	created := createIncidentThroughRouter(t, router, model.Incident{
+		Title:       "Handler taxonomy incident",
+		Description: "Payment provider is down",
+	})
+	req, _ = http.NewRequest("PATCH", "/api/v1/incidents/"+created.ID, strings.NewReader(`{"ai_category": "third-party"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// A value in use cannot be deleted
+	req, _ = http.NewRequest("DELETE", "/api/v1/taxonomy/category/third-party", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/incidents/"+created.ID, nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("DELETE", "/api/v1/taxonomy/category/third-party", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequest("PATCH", "/api/v1/taxonomy/category/unknown", strings.NewReader(`{"position": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
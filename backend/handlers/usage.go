@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"incident-management/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	service *services.UsageService
+}
+
+// NewUsageHandler creates a new AI usage handler
+func NewUsageHandler() *UsageHandler {
+	return &UsageHandler{
+		service: services.NewUsageService(),
+	}
+}
+
+// GetUsage handles GET /ai/usage?from=2024-03-01&to=2024-03-31&group_by=day,provider.
+// The period defaults to the current month; a date as to includes that whole day.
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	details := make(map[string]string)
+	if value := c.Query("from"); value != "" {
+		parsed, _, err := parseUsageTime(value)
+		if err != nil {
+			details["from"] = "from must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, dateOnly, err := parseUsageTime(value)
+		if err != nil {
+			details["to"] = "to must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"
+		}
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		to = parsed
+	}
+	if len(details) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": details,
+		})
+		return
+	}
+
+	groups := splitQueryList(c.DefaultQuery("group_by", "day,provider"))
+	report, err := h.service.Report(from, to, groups)
+	if err != nil {
+		respondIncidentError(c, err, "Failed to retrieve AI usage")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// parseUsageTime parses a UTC date or an RFC 3339 timestamp, reporting whether it was a date
+func parseUsageTime(value string) (time.Time, bool, error) {
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		return parsed, true, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	return parsed, false, err
+}
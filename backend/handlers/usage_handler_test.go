@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetUsage(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/api/v1/ai/usage", NewUsageHandler().GetUsage)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ai/usage?from=2024-03-01&to=2024-03-31&group_by=provider,operation", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var report services.UsageReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if report.To.Format("2006-01-02") != "2024-04-01" || len(report.GroupBy) != 2 {
+		t.Errorf("Expected the period to include March 31, got %+v", report)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"Invalid from", "from=yesterday"},
+		{"Unknown group", "group_by=incident"},
+		{"Empty period", "from=2024-03-02&to=2024-03-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/ai/usage?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
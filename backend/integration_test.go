@@ -40,6 +40,13 @@ func setupTestServer() *gin.Engine {
 	return r
 }
 
+// incidentPage mirrors the envelope returned by GET /api/v1/incidents
+type incidentPage struct {
+	Data       []model.Incident `json:"data"`
+	NextCursor string           `json:"next_cursor"`
+	Total      int64            `json:"total"`
+}
+
 func TestIntegration_CreateAndGetIncident(t *testing.T) {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
@@ -117,8 +124,9 @@ func TestIntegration_CreateAndGetIncident(t *testing.T) {
 	}
 
 	// Parse GET response
-	var incidents []model.Incident
-	err = json.Unmarshal(getRecorder.Body.Bytes(), &incidents)
+	var page incidentPage
+	err = json.Unmarshal(getRecorder.Body.Bytes(), &page)
+	incidents := page.Data
 	if err != nil {
 		t.Fatalf("Failed to unmarshal GET response: %v", err)
 	}
@@ -223,8 +231,9 @@ func TestIntegration_MultipleIncidents(t *testing.T) {
 		t.Errorf("Expected GET status %d, got %d", http.StatusOK, getRecorder.Code)
 	}
 
-	var incidents []model.Incident
-	err = json.Unmarshal(getRecorder.Body.Bytes(), &incidents)
+	var page incidentPage
+	err = json.Unmarshal(getRecorder.Body.Bytes(), &page)
+	incidents := page.Data
 	if err != nil {
 		t.Fatalf("Failed to unmarshal GET response: %v", err)
 	}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"incident-management/database"
 	"incident-management/handlers"
+	"incident-management/services"
 	"incident-management/utils"
 	"log"
 	"time"
@@ -18,19 +20,47 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// Put the stored severity and category taxonomy into effect
+	if err := services.LoadTaxonomy(); err != nil {
+		log.Fatalf("Failed to load taxonomy: %v", err)
+	}
+
 	// Initialize validator
 	utils.InitValidator()
 
+	// Start the background classification workers
+	worker := services.NewClassificationWorker(services.NewClassifierFromEnv(), services.WorkerConfigFromEnv())
+	worker.Start(context.Background())
+	defer worker.Stop()
+
+	// Keep incident embeddings up to date for similar-incident search
+	indexer := services.NewEmbeddingIndexer(services.NewEmbedderFromEnv(), services.IndexerConfigFromEnv())
+	indexer.Start(context.Background())
+	defer indexer.Stop()
+
+	// Bulk reclassification jobs do not survive a restart
+	if err := services.FailInterruptedReclassifications(); err != nil {
+		log.Printf("Failed to recover reclassification jobs: %v", err)
+	}
+
 	// Create Gin router
 	r := gin.Default()
 
-	// Create handler
+	// Create handlers
 	handler := handlers.NewIncidentHandler()
+	classificationHandler := handlers.NewClassificationHandler()
+	taxonomyHandler := handlers.NewTaxonomyHandler()
+	summaryHandler := handlers.NewSummaryHandler()
+	similarityHandler := handlers.NewSimilarityHandler()
+	usageHandler := handlers.NewUsageHandler()
+	remediationHandler := handlers.NewRemediationHandler()
+	postmortemHandler := handlers.NewPostmortemHandler()
+	commentHandler := handlers.NewCommentHandler()
 	// Allow everything (for development/testing only)
 	r.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Actor"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -40,6 +70,43 @@ func main() {
 	{
 		api.POST("/incidents", handler.CreateIncident)
 		api.GET("/incidents", handler.GetAllIncidents)
+		api.GET("/incidents/search", handler.SearchIncidents)
+		api.GET("/incidents/:id", handler.GetIncident)
+		api.PUT("/incidents/:id", handler.UpdateIncident)
+		api.PATCH("/incidents/:id", handler.PatchIncident)
+		api.DELETE("/incidents/:id", handler.DeleteIncident)
+		api.POST("/incidents/:id/transitions", handler.TransitionIncident)
+		api.GET("/incidents/:id/transitions", handler.GetTransitions)
+		api.GET("/incidents/:id/occurrences", handler.GetOccurrences)
+		api.GET("/incidents/:id/history", handler.GetHistory)
+		api.POST("/incidents/:id/comments", commentHandler.AddComment)
+		api.GET("/incidents/:id/comments", commentHandler.GetComments)
+		api.DELETE("/incidents/:id/comments/:comment_id", commentHandler.DeleteComment)
+		api.GET("/incidents/:id/activity", commentHandler.GetActivity)
+		api.GET("/incidents/:id/classification", classificationHandler.GetClassification)
+		api.POST("/incidents/:id/classify", classificationHandler.ClassifyIncident)
+		api.POST("/incidents/:id/overrides", classificationHandler.OverrideClassification)
+		api.GET("/incidents/:id/overrides", classificationHandler.GetOverrides)
+		api.GET("/incidents/:id/summary", summaryHandler.GetSummary)
+		api.POST("/incidents/:id/summary", summaryHandler.GenerateSummary)
+		api.GET("/incidents/:id/summaries", summaryHandler.GetSummaries)
+		api.GET("/incidents/:id/similar", similarityHandler.GetSimilarIncidents)
+		api.POST("/incidents/:id/remediation", remediationHandler.SuggestRemediation)
+		api.GET("/incidents/:id/remediation", remediationHandler.GetSuggestions)
+		api.POST("/incidents/:id/remediation/:suggestion_id/feedback", remediationHandler.RecordFeedback)
+		api.GET("/incidents/:id/postmortem", postmortemHandler.GetPostmortem)
+		api.POST("/incidents/:id/postmortem", postmortemHandler.DraftPostmortem)
+		api.GET("/incidents/:id/postmortems", postmortemHandler.GetPostmortems)
+		api.GET("/classification/feedback", classificationHandler.ExportFeedback)
+		api.POST("/classification/reclassify", classificationHandler.StartReclassification)
+		api.GET("/classification/reclassify", classificationHandler.GetReclassifications)
+		api.GET("/classification/reclassify/:id", classificationHandler.GetReclassification)
+		api.POST("/classification/reclassify/:id/cancel", classificationHandler.CancelReclassification)
+		api.GET("/ai/usage", usageHandler.GetUsage)
+		api.GET("/taxonomy", taxonomyHandler.GetTaxonomy)
+		api.POST("/taxonomy/:kind", taxonomyHandler.CreateTerm)
+		api.PATCH("/taxonomy/:kind/:value", taxonomyHandler.UpdateTerm)
+		api.DELETE("/taxonomy/:kind/:value", taxonomyHandler.DeleteTerm)
 	}
 
 	// Health check endpoint
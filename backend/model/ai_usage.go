@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AIUsage records the token usage and cost of a single AI provider call
+type AIUsage struct {
+	ID               string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Provider         string `json:"provider" gorm:"index"`
+	Model            string `json:"model"`
+	Operation        string `json:"operation"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+	// Cost is the price of the call in US dollars; zero when the model has no price
+	Cost      float64   `json:"cost"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (usage *AIUsage) BeforeCreate(tx *gorm.DB) error {
+	if usage.ID == "" {
+		usage.ID = uuid.New().String()
+	}
+	return nil
+}
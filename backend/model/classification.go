@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Classification is the audit record of a single AI classification run
+type Classification struct {
+	ID               string          `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID       string          `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	Provider         string          `json:"provider"`
+	Model            string          `json:"model"`
+	PromptVersion    string          `json:"prompt_version"`
+	Severity         string          `json:"severity"`
+	Category         string          `json:"category"`
+	Confidence       float64         `json:"confidence"`
+	Rationale        string          `json:"rationale" gorm:"type:text"`
+	RawResponse      string          `json:"raw_response" gorm:"type:text"`
+	PromptTokens     int             `json:"prompt_tokens"`
+	CompletionTokens int             `json:"completion_tokens"`
+	TotalTokens      int             `json:"total_tokens"`
+	Fallback         bool            `json:"fallback"`
+	Cached           bool            `json:"cached"`
+	FallbackReason   string          `json:"fallback_reason" gorm:"type:text"`
+	MatchedRules     json.RawMessage `json:"matched_rules,omitempty" gorm:"type:text"`
+	Redactions       json.RawMessage `json:"redactions,omitempty" gorm:"type:text"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (classification *Classification) BeforeCreate(tx *gorm.DB) error {
+	if classification.ID == "" {
+		classification.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// ClassificationCacheEntry is a cached classifier result in the persistent cache tier.
+// The key is a hash of the normalized incident text and the classifier configuration.
+type ClassificationCacheEntry struct {
+	Key           string    `json:"key" gorm:"column:cache_key;primaryKey;type:varchar(64)"`
+	Provider      string    `json:"provider"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	Result        string    `json:"result" gorm:"type:text"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"index"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ClassificationJob is an entry in the durable classification queue
+type ClassificationJob struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	IncidentID string `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	Status     string `json:"status" gorm:"index;not null;default:'queued'"`
+	Attempts   int    `json:"attempts"`
+	// MaxAttempts overrides the worker's attempt limit when set
+	MaxAttempts int       `json:"max_attempts"`
+	RunAt       time.Time `json:"run_at" gorm:"index"`
+	LastError   string    `json:"last_error" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Classification job states
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
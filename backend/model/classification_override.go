@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ClassificationOverride records a human correction of an incident's AI severity and/or category.
+// The AI values at the time of the override are kept so the correction can be used as feedback.
+type ClassificationOverride struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID    string    `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	AISeverity    string    `json:"ai_severity"`
+	AICategory    string    `json:"ai_category"`
+	HumanSeverity string    `json:"human_severity,omitempty"`
+	HumanCategory string    `json:"human_category,omitempty"`
+	Reason        string    `json:"reason" gorm:"type:text"`
+	Actor         string    `json:"actor"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (override *ClassificationOverride) BeforeCreate(tx *gorm.DB) error {
+	if override.ID == "" {
+		override.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Comment is a note a responder left on an incident, e.g. what was tried and what was found
+type Comment struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID string    `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	Body       string    `json:"body" gorm:"type:text;not null"`
+	Author     string    `json:"author"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (comment *Comment) BeforeCreate(tx *gorm.DB) error {
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -14,10 +14,47 @@ type Incident struct {
 	Status      string `json:"status" gorm:"default:'open'" validate:"omitempty,oneof=open in_progress resolved closed"`
 	Priority    string `json:"priority" gorm:"default:'medium'" validate:"omitempty,oneof=low medium high critical"`
 	// AI-determined fields
-	AISeverity string    `json:"ai_severity" gorm:"default:'medium'" validate:"omitempty,oneof=low medium high"`
-	AICategory string    `json:"ai_category" gorm:"default:'software'" validate:"omitempty,oneof=network software hardware security"`
-	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	AISeverity string `json:"ai_severity" validate:"omitempty,taxonomy=severity"`
+	AICategory string `json:"ai_category" validate:"omitempty,taxonomy=category"`
+	// Human overrides of the AI fields, set through the overrides endpoint only
+	HumanSeverity string `json:"human_severity,omitempty" validate:"omitempty,taxonomy=severity"`
+	HumanCategory string `json:"human_category,omitempty" validate:"omitempty,taxonomy=category"`
+	// ClassificationStatus tracks the asynchronous AI classification of the incident
+	ClassificationStatus string `json:"classification_status" gorm:"default:'pending';index" validate:"omitempty,oneof=pending completed failed"`
+	// DedupKey is an optional caller-chosen key, such as an alert ID, that identifies repeat reports
+	DedupKey string `json:"dedup_key,omitempty" validate:"omitempty,max=200"`
+	// Fingerprint groups repeat reports of the same problem; it is derived from the dedup key or the title
+	Fingerprint string `json:"fingerprint,omitempty" gorm:"index"`
+	// OccurrenceCount is the number of reports attached to the incident, including the first
+	OccurrenceCount  int       `json:"occurrence_count" gorm:"default:1;not null"`
+	LastOccurrenceAt time.Time `json:"last_occurrence_at"`
+	// Deduplicated is set in the response when a report was attached to this existing incident
+	Deduplicated bool      `json:"deduplicated,omitempty" gorm:"-"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Classification states of an incident
+const (
+	ClassificationPending   = "pending"
+	ClassificationCompleted = "completed"
+	ClassificationFailed    = "failed"
+)
+
+// EffectiveSeverity returns the human override of the severity, or the AI value when there is none
+func (incident *Incident) EffectiveSeverity() string {
+	if incident.HumanSeverity != "" {
+		return incident.HumanSeverity
+	}
+	return incident.AISeverity
+}
+
+// EffectiveCategory returns the human override of the category, or the AI value when there is none
+func (incident *Incident) EffectiveCategory() string {
+	if incident.HumanCategory != "" {
+		return incident.HumanCategory
+	}
+	return incident.AICategory
 }
 
 // BeforeCreate will set a UUID rather than numeric ID
@@ -30,22 +67,10 @@ func (incident *Incident) BeforeCreate(tx *gorm.DB) error {
 
 // ValidateSeverity checks if the severity is valid
 func (incident *Incident) ValidateSeverity() bool {
-	validSeverities := []string{"low", "medium", "high"}
-	for _, severity := range validSeverities {
-		if incident.AISeverity == severity {
-			return true
-		}
-	}
-	return false
+	return CurrentTaxonomy().Contains(TaxonomySeverity, incident.AISeverity)
 }
 
 // ValidateCategory checks if the category is valid
 func (incident *Incident) ValidateCategory() bool {
-	validCategories := []string{"network", "software", "hardware", "security"}
-	for _, category := range validCategories {
-		if incident.AICategory == category {
-			return true
-		}
-	}
-	return false
+	return CurrentTaxonomy().Contains(TaxonomyCategory, incident.AICategory)
 }
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sources of incident changes
+const (
+	// ChangeSourceAPI marks changes made by a responder through the API
+	ChangeSourceAPI = "api"
+	// ChangeSourceAI marks changes made by the AI classifier
+	ChangeSourceAI = "ai"
+	// ChangeSourceAutomation marks changes made by the service itself, such as deduplication
+	ChangeSourceAutomation = "automation"
+)
+
+// IncidentChange records a change of one field of an incident
+type IncidentChange struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID string    `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	Field      string    `json:"field" gorm:"not null"`
+	OldValue   string    `json:"old_value" gorm:"type:text"`
+	NewValue   string    `json:"new_value" gorm:"type:text"`
+	Actor      string    `json:"actor"`
+	Source     string    `json:"source" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (change *IncidentChange) BeforeCreate(tx *gorm.DB) error {
+	if change.ID == "" {
+		change.ID = uuid.New().String()
+	}
+	return nil
+}
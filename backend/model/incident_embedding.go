@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// IncidentEmbedding is the text embedding of an incident's title and description
+type IncidentEmbedding struct {
+	IncidentID string `json:"incident_id" gorm:"primaryKey;type:varchar(36)"`
+	Provider   string `json:"provider"`
+	// Model identifies the vector space; only embeddings of the same model are compared
+	Model      string `json:"model" gorm:"index"`
+	Dimensions int    `json:"dimensions"`
+	// Vector holds the float32 components in little-endian order
+	Vector []byte `json:"-" gorm:"type:blob"`
+	// TextHash is the hash of the embedded text, so unchanged text is not embedded again
+	TextHash string `json:"text_hash"`
+	// SourceUpdatedAt is the incident's updated_at when the embedding was last checked
+	SourceUpdatedAt time.Time `json:"source_updated_at"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// EncodeVector packs vector components into the stored binary form
+func EncodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
+
+// DecodeVector unpacks a stored vector
+func DecodeVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
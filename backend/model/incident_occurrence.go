@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IncidentOccurrence records a repeat report that was attached to an existing incident
+// instead of creating a new one
+type IncidentOccurrence struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID  string    `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	Title       string    `json:"title"`
+	Description string    `json:"description" gorm:"type:text"`
+	Priority    string    `json:"priority"`
+	DedupKey    string    `json:"dedup_key,omitempty"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (occurrence *IncidentOccurrence) BeforeCreate(tx *gorm.DB) error {
+	if occurrence.ID == "" {
+		occurrence.ID = uuid.New().String()
+	}
+	return nil
+}
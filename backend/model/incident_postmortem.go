@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IncidentPostmortem is one version of the drafted postmortem of a resolved incident.
+// Every regeneration adds a version; earlier versions are kept.
+type IncidentPostmortem struct {
+	ID         string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID string `json:"incident_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_postmortem_incident_version"`
+	Version    int    `json:"version" gorm:"not null;uniqueIndex:idx_postmortem_incident_version"`
+	// Markdown is the postmortem document
+	Markdown string `json:"markdown" gorm:"type:text"`
+	// Sections holds the summary, impact, timeline, contributing factors and action items as JSON
+	Sections         json.RawMessage `json:"sections" gorm:"type:text"`
+	Provider         string          `json:"provider"`
+	Model            string          `json:"model"`
+	PromptVersion    string          `json:"prompt_version"`
+	PromptTokens     int             `json:"prompt_tokens"`
+	CompletionTokens int             `json:"completion_tokens"`
+	TotalTokens      int             `json:"total_tokens"`
+	Fallback         bool            `json:"fallback"`
+	FallbackReason   string          `json:"fallback_reason" gorm:"type:text"`
+	Redactions       json.RawMessage `json:"redactions,omitempty" gorm:"type:text"`
+	Actor            string          `json:"actor"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (postmortem *IncidentPostmortem) BeforeCreate(tx *gorm.DB) error {
+	if postmortem.ID == "" {
+		postmortem.ID = uuid.New().String()
+	}
+	return nil
+}
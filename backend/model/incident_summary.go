@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IncidentSummary is one version of the stakeholder summary of an incident.
+// Every regeneration adds a version; earlier versions are kept.
+type IncidentSummary struct {
+	ID         string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID string `json:"incident_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_summary_incident_version"`
+	Version    int    `json:"version" gorm:"not null;uniqueIndex:idx_summary_incident_version"`
+	Summary    string `json:"summary" gorm:"type:text"`
+	// Timeline is the digest of key events, one line per event, as a JSON array
+	Timeline         json.RawMessage `json:"timeline" gorm:"type:text"`
+	Provider         string          `json:"provider"`
+	Model            string          `json:"model"`
+	PromptVersion    string          `json:"prompt_version"`
+	PromptTokens     int             `json:"prompt_tokens"`
+	CompletionTokens int             `json:"completion_tokens"`
+	TotalTokens      int             `json:"total_tokens"`
+	Fallback         bool            `json:"fallback"`
+	FallbackReason   string          `json:"fallback_reason" gorm:"type:text"`
+	Redactions       json.RawMessage `json:"redactions,omitempty" gorm:"type:text"`
+	Actor            string          `json:"actor"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (summary *IncidentSummary) BeforeCreate(tx *gorm.DB) error {
+	if summary.ID == "" {
+		summary.ID = uuid.New().String()
+	}
+	return nil
+}
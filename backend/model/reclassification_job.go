@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReclassificationJob tracks a bulk re-run of the classifier over a filtered set of incidents
+type ReclassificationJob struct {
+	ID     string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Status string `json:"status" gorm:"index;not null;default:'queued'"`
+	// Filter is the incident filter the job was started with
+	Filter    json.RawMessage `json:"filter" gorm:"type:text"`
+	DryRun    bool            `json:"dry_run"`
+	RateLimit float64         `json:"rate_limit"`
+	Actor     string          `json:"actor"`
+	// Progress counters
+	Total           int64  `json:"total"`
+	Processed       int64  `json:"processed"`
+	Changed         int64  `json:"changed"`
+	SeverityChanged int64  `json:"severity_changed"`
+	CategoryChanged int64  `json:"category_changed"`
+	Failed          int64  `json:"failed"`
+	LastError       string `json:"last_error" gorm:"type:text"`
+	// Timestamps
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Reclassification job states
+const (
+	ReclassificationQueued    = "queued"
+	ReclassificationRunning   = "running"
+	ReclassificationCompleted = "completed"
+	ReclassificationFailed    = "failed"
+	ReclassificationCancelled = "cancelled"
+)
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (job *ReclassificationJob) BeforeCreate(tx *gorm.DB) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	return nil
+}
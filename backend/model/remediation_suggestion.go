@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RemediationSuggestion is a set of AI-suggested probable causes and next steps for an incident.
+// It keeps its provenance, the past incidents and model it came from, and the responders' verdict.
+type RemediationSuggestion struct {
+	ID         string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID string `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	// Causes is a JSON array of probable causes with a confidence each
+	Causes json.RawMessage `json:"causes" gorm:"type:text"`
+	// Steps is a JSON array of suggested next steps, in order
+	Steps json.RawMessage `json:"steps" gorm:"type:text"`
+	// SourceIncidents is a JSON array of the past resolved incidents the suggestion drew on
+	SourceIncidents  json.RawMessage `json:"source_incidents" gorm:"type:text"`
+	Provider         string          `json:"provider"`
+	Model            string          `json:"model"`
+	PromptVersion    string          `json:"prompt_version"`
+	PromptTokens     int             `json:"prompt_tokens"`
+	CompletionTokens int             `json:"completion_tokens"`
+	TotalTokens      int             `json:"total_tokens"`
+	Fallback         bool            `json:"fallback"`
+	FallbackReason   string          `json:"fallback_reason" gorm:"type:text"`
+	Redactions       json.RawMessage `json:"redactions,omitempty" gorm:"type:text"`
+	Actor            string          `json:"actor"`
+	// Helpful is the responders' verdict on the suggestion; nil until feedback is given
+	Helpful         *bool      `json:"helpful"`
+	FeedbackComment string     `json:"feedback_comment,omitempty" gorm:"type:text"`
+	FeedbackActor   string     `json:"feedback_actor,omitempty"`
+	FeedbackAt      *time.Time `json:"feedback_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (suggestion *RemediationSuggestion) BeforeCreate(tx *gorm.DB) error {
+	if suggestion.ID == "" {
+		suggestion.ID = uuid.New().String()
+	}
+	return nil
+}
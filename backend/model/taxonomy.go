@@ -0,0 +1,130 @@
+package model
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Taxonomy kinds
+const (
+	TaxonomySeverity = "severity"
+	TaxonomyCategory = "category"
+)
+
+// TaxonomyTerm is one allowed value of a classification taxonomy
+type TaxonomyTerm struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Kind        string `json:"kind" gorm:"not null;uniqueIndex:idx_taxonomy_kind_value" validate:"required,oneof=severity category"`
+	Value       string `json:"value" gorm:"not null;uniqueIndex:idx_taxonomy_kind_value" validate:"required,min=1,max=50,lowercase,excludesall= "`
+	Description string `json:"description" gorm:"type:text" validate:"max=500"`
+	// Position orders the terms within their kind, e.g. severities from least to most severe
+	Position int `json:"position"`
+	// IsDefault marks the value used when the classifier cannot decide
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Taxonomy is a snapshot of the allowed severities and categories
+type Taxonomy struct {
+	Severities []TaxonomyTerm `json:"severities"`
+	Categories []TaxonomyTerm `json:"categories"`
+}
+
+var (
+	taxonomyMu      sync.RWMutex
+	currentTaxonomy *Taxonomy
+)
+
+// DefaultTaxonomyTerms returns the built-in taxonomy used to seed the database
+func DefaultTaxonomyTerms() []TaxonomyTerm {
+	return []TaxonomyTerm{
+		{Kind: TaxonomySeverity, Value: "low", Position: 0, Description: "Minor impact, few users affected, a workaround exists"},
+		{Kind: TaxonomySeverity, Value: "medium", Position: 1, IsDefault: true, Description: "Noticeable impact on a feature or group of users"},
+		{Kind: TaxonomySeverity, Value: "high", Position: 2, Description: "Outage, data loss or security exposure affecting many users"},
+		{Kind: TaxonomyCategory, Value: "network", Position: 0, Description: "Connectivity, DNS, load balancers, firewalls"},
+		{Kind: TaxonomyCategory, Value: "software", Position: 1, IsDefault: true, Description: "Application bugs, deployments, configuration"},
+		{Kind: TaxonomyCategory, Value: "hardware", Position: 2, Description: "Servers, disks, power, physical devices"},
+		{Kind: TaxonomyCategory, Value: "security", Position: 3, Description: "Breaches, vulnerabilities, access and credential issues"},
+	}
+}
+
+// NewTaxonomy groups terms by kind, ordered by position
+func NewTaxonomy(terms []TaxonomyTerm) *Taxonomy {
+	taxonomy := &Taxonomy{}
+	for _, term := range terms {
+		switch term.Kind {
+		case TaxonomySeverity:
+			taxonomy.Severities = append(taxonomy.Severities, term)
+		case TaxonomyCategory:
+			taxonomy.Categories = append(taxonomy.Categories, term)
+		}
+	}
+	for _, list := range [][]TaxonomyTerm{taxonomy.Severities, taxonomy.Categories} {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Position < list[j].Position })
+	}
+	return taxonomy
+}
+
+// CurrentTaxonomy returns the taxonomy in effect, or the built-in one if none has been loaded
+func CurrentTaxonomy() *Taxonomy {
+	taxonomyMu.RLock()
+	defer taxonomyMu.RUnlock()
+	if currentTaxonomy == nil {
+		return NewTaxonomy(DefaultTaxonomyTerms())
+	}
+	return currentTaxonomy
+}
+
+// SetTaxonomy replaces the taxonomy in effect; nil restores the built-in one
+func SetTaxonomy(taxonomy *Taxonomy) {
+	taxonomyMu.Lock()
+	defer taxonomyMu.Unlock()
+	currentTaxonomy = taxonomy
+}
+
+// Terms returns the terms of a kind
+func (t *Taxonomy) Terms(kind string) []TaxonomyTerm {
+	switch kind {
+	case TaxonomySeverity:
+		return t.Severities
+	case TaxonomyCategory:
+		return t.Categories
+	}
+	return nil
+}
+
+// Values returns the allowed values of a kind, in order
+func (t *Taxonomy) Values(kind string) []string {
+	terms := t.Terms(kind)
+	values := make([]string, 0, len(terms))
+	for _, term := range terms {
+		values = append(values, term.Value)
+	}
+	return values
+}
+
+// Contains reports whether value is allowed for kind
+func (t *Taxonomy) Contains(kind, value string) bool {
+	for _, term := range t.Terms(kind) {
+		if term.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Default returns the default value of a kind, or its first value if none is marked
+func (t *Taxonomy) Default(kind string) string {
+	terms := t.Terms(kind)
+	for _, term := range terms {
+		if term.IsDefault {
+			return term.Value
+		}
+	}
+	if len(terms) > 0 {
+		return terms[0].Value
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StatusTransition records a single change of an incident's status
+type StatusTransition struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	IncidentID string    `json:"incident_id" gorm:"type:varchar(36);index;not null"`
+	Action     string    `json:"action" gorm:"not null"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status" gorm:"not null"`
+	Reason     string    `json:"reason" gorm:"type:text"`
+	Actor      string    `json:"actor"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (transition *StatusTransition) BeforeCreate(tx *gorm.DB) error {
+	if transition.ID == "" {
+		transition.ID = uuid.New().String()
+	}
+	return nil
+}
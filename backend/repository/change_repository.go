@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+)
+
+type ChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewChangeRepository creates a new incident change history repository
+func NewChangeRepository() *ChangeRepository {
+	return &ChangeRepository{
+		db: database.GetDB(),
+	}
+}
+
+// ListByIncident retrieves the field changes of an incident, oldest first. Empty field and
+// source match every change.
+func (r *ChangeRepository) ListByIncident(incidentID, field, source string) ([]model.IncidentChange, error) {
+	query := r.db.Where("incident_id = ?", incidentID)
+	if field != "" {
+		query = query.Where("field = ?", field)
+	}
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	var changes []model.IncidentChange
+	err := query.Order("created_at asc").Find(&changes).Error
+	return changes, err
+}
+
+// recordChanges stores field changes as part of the transaction that made them
+func recordChanges(tx *gorm.DB, changes []model.IncidentChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.Create(&changes).Error
+}
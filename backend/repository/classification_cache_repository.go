@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ClassificationCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewClassificationCacheRepository creates a new classification cache repository
+func NewClassificationCacheRepository() *ClassificationCacheRepository {
+	return &ClassificationCacheRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Get retrieves an unexpired cache entry
+func (r *ClassificationCacheRepository) Get(key string, now time.Time) (*model.ClassificationCacheEntry, error) {
+	var entry model.ClassificationCacheEntry
+	err := r.db.Where("cache_key = ? AND expires_at > ?", key, now).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Put stores a cache entry, replacing any entry with the same key
+func (r *ClassificationCacheRepository) Put(entry *model.ClassificationCacheEntry) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(entry).Error
+}
+
+// DeleteExpired removes entries that expired before now and returns how many were removed
+func (r *ClassificationCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&model.ClassificationCacheEntry{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ClassificationJobRepository struct {
+	db *gorm.DB
+}
+
+// NewClassificationJobRepository creates a new classification job repository
+func NewClassificationJobRepository() *ClassificationJobRepository {
+	return &ClassificationJobRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Enqueue adds a job to the queue
+func (r *ClassificationJobRepository) Enqueue(job *model.ClassificationJob) error {
+	return r.db.Create(job).Error
+}
+
+// EnqueueMissing queues a job for every pending incident that has no queued or running job,
+// covering incidents created before the queue existed. It returns the number of jobs created.
+func (r *ClassificationJobRepository) EnqueueMissing(maxAttempts int) (int64, error) {
+	now := time.Now()
+	result := r.db.Exec(`INSERT INTO classification_jobs (incident_id, status, attempts, max_attempts, run_at, created_at, updated_at)
+		SELECT id, ?, 0, ?, ?, ?, ? FROM incidents
+		WHERE classification_status = ?
+		AND id NOT IN (SELECT incident_id FROM classification_jobs WHERE status IN ?)`,
+		model.JobQueued, maxAttempts, now, now, now,
+		model.ClassificationPending, []string{model.JobQueued, model.JobRunning},
+	)
+	return result.RowsAffected, result.Error
+}
+
+// ClaimNext marks the next due job as running and returns it, or nil if no job is due
+func (r *ClassificationJobRepository) ClaimNext(now time.Time) (*model.ClassificationJob, error) {
+	for {
+		var job model.ClassificationJob
+		err := r.db.Where("status = ? AND run_at <= ?", model.JobQueued, now).
+			Order("run_at asc, id asc").
+			Limit(1).
+			Find(&job).Error
+		if err != nil {
+			return nil, err
+		}
+		if job.ID == 0 {
+			return nil, nil
+		}
+
+		// Only one worker can move the job out of the queued state
+		result := r.db.Model(&model.ClassificationJob{}).
+			Where("id = ? AND status = ?", job.ID, model.JobQueued).
+			Updates(map[string]interface{}{
+				"status":   model.JobRunning,
+				"attempts": gorm.Expr("attempts + 1"),
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			// Reload so the attempt count reflects every earlier claim
+			if err := r.db.First(&job, job.ID).Error; err != nil {
+				return nil, err
+			}
+			return &job, nil
+		}
+	}
+}
+
+// Complete marks a job as done
+func (r *ClassificationJobRepository) Complete(job *model.ClassificationJob) error {
+	job.Status = model.JobDone
+	job.LastError = ""
+	return r.db.Model(job).Updates(map[string]interface{}{
+		"status":     job.Status,
+		"last_error": job.LastError,
+	}).Error
+}
+
+// Retry puts a job back in the queue to run again at runAt
+func (r *ClassificationJobRepository) Retry(job *model.ClassificationJob, runAt time.Time, cause error) error {
+	job.Status = model.JobQueued
+	job.RunAt = runAt
+	job.LastError = cause.Error()
+	return r.db.Model(job).Updates(map[string]interface{}{
+		"status":     job.Status,
+		"run_at":     job.RunAt,
+		"last_error": job.LastError,
+	}).Error
+}
+
+// Fail marks a job as permanently failed
+func (r *ClassificationJobRepository) Fail(job *model.ClassificationJob, cause error) error {
+	job.Status = model.JobFailed
+	job.LastError = cause.Error()
+	return r.db.Model(job).Updates(map[string]interface{}{
+		"status":     job.Status,
+		"last_error": job.LastError,
+	}).Error
+}
+
+// RequeueRunning returns jobs left running by a previous process to the queue
+func (r *ClassificationJobRepository) RequeueRunning() (int64, error) {
+	result := r.db.Model(&model.ClassificationJob{}).
+		Where("status = ?", model.JobRunning).
+		Updates(map[string]interface{}{
+			"status": model.JobQueued,
+			"run_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListByIncident retrieves the jobs of an incident, oldest first
+func (r *ClassificationJobRepository) ListByIncident(incidentID string) ([]model.ClassificationJob, error) {
+	var jobs []model.ClassificationJob
+	err := r.db.Where("incident_id = ?", incidentID).Order("id asc").Find(&jobs).Error
+	return jobs, err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassificationJobQueue(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	incidents := NewIncidentRepository()
+	jobs := NewClassificationJobRepository()
+
+	// Drain anything queued by other tests
+	for {
+		job, err := jobs.ClaimNext(time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to drain queue: %v", err)
+		}
+		if job == nil {
+			break
+		}
+		jobs.Complete(job)
+	}
+
+	incident := &model.Incident{Title: "Queued incident", Description: "Waiting for classification"}
+	job := &model.ClassificationJob{Status: model.JobQueued, RunAt: time.Now()}
+	if err := incidents.CreateWithJob(incident, job); err != nil {
+		t.Fatalf("Failed to create incident with job: %v", err)
+	}
+	if job.IncidentID != incident.ID {
+		t.Fatalf("Expected job to reference incident %s, got %s", incident.ID, job.IncidentID)
+	}
+
+	// Concurrent claims hand the job to exactly one caller
+	var wg sync.WaitGroup
+	claimed := make(chan *model.ClassificationJob, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job, err := jobs.ClaimNext(time.Now())
+			if err != nil {
+				t.Errorf("Failed to claim job: %v", err)
+				return
+			}
+			if job != nil {
+				claimed <- job
+			}
+		}()
+	}
+	wg.Wait()
+	close(claimed)
+
+	var got []*model.ClassificationJob
+	for job := range claimed {
+		got = append(got, job)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected exactly one claim, got %d", len(got))
+	}
+	if got[0].Status != model.JobRunning || got[0].Attempts != 1 {
+		t.Errorf("Expected running job with 1 attempt, got %+v", got[0])
+	}
+
+	// Retried jobs are not due until their run time
+	runAt := time.Now().Add(time.Minute)
+	if err := jobs.Retry(got[0], runAt, errTest); err != nil {
+		t.Fatalf("Failed to retry job: %v", err)
+	}
+	if job, _ := jobs.ClaimNext(time.Now()); job != nil {
+		t.Errorf("Expected no due jobs, got %+v", job)
+	}
+	job, err = jobs.ClaimNext(runAt.Add(time.Second))
+	if err != nil || job == nil {
+		t.Fatalf("Expected retried job to be claimable later, got %v %v", job, err)
+	}
+	if job.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", job.Attempts)
+	}
+
+	// Jobs left running by a crashed process are requeued
+	requeued, err := jobs.RequeueRunning()
+	if err != nil || requeued != 1 {
+		t.Errorf("Expected 1 requeued job, got %d (%v)", requeued, err)
+	}
+}
+
+func TestEnqueueMissing(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	incidents := NewIncidentRepository()
+	jobs := NewClassificationJobRepository()
+
+	incident := &model.Incident{Title: "Legacy incident", Description: "Created without a job", ClassificationStatus: model.ClassificationPending}
+	if err := incidents.Create(incident); err != nil {
+		t.Fatalf("Failed to create incident: %v", err)
+	}
+
+	if _, err := jobs.EnqueueMissing(3); err != nil {
+		t.Fatalf("Failed to enqueue missing jobs: %v", err)
+	}
+	// Running it again must not create duplicates
+	if _, err := jobs.EnqueueMissing(3); err != nil {
+		t.Fatalf("Failed to enqueue missing jobs: %v", err)
+	}
+
+	queued, err := jobs.ListByIncident(incident.ID)
+	if err != nil {
+		t.Fatalf("Failed to list jobs: %v", err)
+	}
+	if len(queued) != 1 || queued[0].MaxAttempts != 3 {
+		t.Errorf("Expected a single queued job with 3 max attempts, got %+v", queued)
+	}
+}
+
+var errTest = errors.New("provider unavailable")
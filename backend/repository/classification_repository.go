@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+)
+
+type ClassificationRepository struct {
+	db *gorm.DB
+}
+
+// NewClassificationRepository creates a new classification repository
+func NewClassificationRepository() *ClassificationRepository {
+	return &ClassificationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Record stores a classification and applies its severity and category to the incident in a
+// single transaction, along with the field changes diff reports between the incident as it was
+// in the transaction and as it is after the classification was applied
+func (r *ClassificationRepository) Record(classification *model.Classification, diff func(before, after *model.Incident) []model.IncidentChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before model.Incident
+		err := tx.Where("id = ?", classification.IncidentID).First(&before).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.Incident{}).Where("id = ?", classification.IncidentID).Updates(map[string]interface{}{
+			"ai_severity":           classification.Severity,
+			"ai_category":           classification.Category,
+			"classification_status": model.ClassificationCompleted,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(classification).Error; err != nil {
+			return err
+		}
+
+		after := before
+		after.AISeverity = classification.Severity
+		after.AICategory = classification.Category
+		after.ClassificationStatus = model.ClassificationCompleted
+		return recordChanges(tx, diff(&before, &after))
+	})
+}
+
+// GetLatest retrieves the most recent classification of an incident
+func (r *ClassificationRepository) GetLatest(incidentID string) (*model.Classification, error) {
+	var classification model.Classification
+	err := r.db.Where("incident_id = ?", incidentID).Order("created_at desc").First(&classification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &classification, nil
+}
+
+// ListByIncident retrieves every classification of an incident, newest first
+func (r *ClassificationRepository) ListByIncident(incidentID string) ([]model.Classification, error) {
+	var classifications []model.Classification
+	err := r.db.Where("incident_id = ?", incidentID).Order("created_at desc").Find(&classifications).Error
+	return classifications, err
+}
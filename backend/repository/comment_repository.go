@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository creates a new comment repository
+func NewCommentRepository() *CommentRepository {
+	return &CommentRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create stores a comment
+func (r *CommentRepository) Create(comment *model.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+// Get retrieves a comment of an incident by its ID
+func (r *CommentRepository) Get(incidentID, id string) (*model.Comment, error) {
+	var comment model.Comment
+	err := r.db.Where("incident_id = ? AND id = ?", incidentID, id).First(&comment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// Delete removes a comment of an incident
+func (r *CommentRepository) Delete(incidentID, id string) error {
+	result := r.db.Where("incident_id = ? AND id = ?", incidentID, id).Delete(&model.Comment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListByIncident retrieves the comments of an incident in chronological order
+func (r *CommentRepository) ListByIncident(incidentID string) ([]model.Comment, error) {
+	var comments []model.Comment
+	err := r.db.Where("incident_id = ?", incidentID).Order("created_at asc").Find(&comments).Error
+	return comments, err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmbeddingRepository struct {
+	db *gorm.DB
+}
+
+// NewEmbeddingRepository creates a new incident embedding repository
+func NewEmbeddingRepository() *EmbeddingRepository {
+	return &EmbeddingRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Save stores an embedding, replacing the incident's previous one
+func (r *EmbeddingRepository) Save(embedding *model.IncidentEmbedding) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(embedding).Error
+}
+
+// Get retrieves the embedding of an incident
+func (r *EmbeddingRepository) Get(incidentID string) (*model.IncidentEmbedding, error) {
+	var embedding model.IncidentEmbedding
+	err := r.db.Where("incident_id = ?", incidentID).First(&embedding).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &embedding, nil
+}
+
+// ListByModel retrieves the embeddings of one model of existing incidents with one of the
+// given statuses, leaving out excludeID. Empty statuses match every incident.
+func (r *EmbeddingRepository) ListByModel(embeddingModel, excludeID string, statuses []string) ([]model.IncidentEmbedding, error) {
+	query := r.db.Model(&model.IncidentEmbedding{}).
+		Select("incident_embeddings.*").
+		Joins("JOIN incidents ON incidents.id = incident_embeddings.incident_id").
+		Where("incident_embeddings.model = ? AND incident_embeddings.incident_id <> ?", embeddingModel, excludeID)
+	if len(statuses) > 0 {
+		query = query.Where("incidents.status IN ?", statuses)
+	}
+	var embeddings []model.IncidentEmbedding
+	err := query.Find(&embeddings).Error
+	return embeddings, err
+}
+
+// ListStale retrieves incidents that have no embedding of the model, or changed since it was computed
+func (r *EmbeddingRepository) ListStale(embeddingModel string, limit int) ([]model.Incident, error) {
+	var incidents []model.Incident
+	err := r.db.Model(&model.Incident{}).
+		Joins("LEFT JOIN incident_embeddings ON incident_embeddings.incident_id = incidents.id").
+		Where("incident_embeddings.incident_id IS NULL OR incident_embeddings.model <> ? OR incident_embeddings.source_updated_at < incidents.updated_at", embeddingModel).
+		Order("incidents.created_at asc").
+		Limit(limit).
+		Find(&incidents).Error
+	return incidents, err
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPageSize is used when no limit is requested
+	DefaultPageSize = 20
+	// MaxPageSize caps the number of incidents returned per page
+	MaxPageSize = 100
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// priorityRank orders priorities by urgency rather than alphabetically
+const priorityRank = "CASE priority WHEN 'low' THEN 0 WHEN 'medium' THEN 1 WHEN 'high' THEN 2 WHEN 'critical' THEN 3 ELSE -1 END"
+
+// incidentSortColumns maps the public sort fields to their SQL expressions
+var incidentSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+	"priority":   priorityRank,
+}
+
+// IncidentFilter narrows, orders and paginates an incident listing
+type IncidentFilter struct {
+	Status        []string
+	Priority      []string
+	AISeverity    []string
+	AICategory    []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	SortBy        string
+	SortDesc      bool
+	Limit         int
+	Cursor        string
+}
+
+// IncidentPage is a single page of an incident listing
+type IncidentPage struct {
+	Incidents  []model.Incident `json:"data"`
+	NextCursor string           `json:"next_cursor"`
+	Total      int64            `json:"total"`
+}
+
+// incidentCursor identifies the last incident of a page
+type incidentCursor struct {
+	SortBy string `json:"s"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+// Validate checks the filter and returns field-level errors, or nil if it is valid
+func (f *IncidentFilter) Validate() map[string]string {
+	errors := make(map[string]string)
+	if f.SortBy != "" {
+		if _, ok := incidentSortColumns[f.SortBy]; !ok {
+			errors["sort"] = "sort must be one of: created_at updated_at title priority"
+		}
+	}
+	if f.Limit < 0 || f.Limit > MaxPageSize {
+		errors["limit"] = fmt.Sprintf("limit must be between 1 and %d", MaxPageSize)
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && f.CreatedAfter.After(*f.CreatedBefore) {
+		errors["created_after"] = "created_after must be before created_before"
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// List retrieves a page of incidents matching the filter
+func (r *IncidentRepository) List(filter IncidentFilter) (*IncidentPage, error) {
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	column := incidentSortColumns[sortBy]
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+
+	page := &IncidentPage{Incidents: []model.Incident{}}
+	if err := applyIncidentFilter(r.db.Model(&model.Incident{}), filter).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := applyIncidentFilter(r.db.Model(&model.Incident{}), filter)
+	if filter.Cursor != "" {
+		value, id, err := decodeIncidentCursor(filter.Cursor, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("(%s %s ?) OR (%s = ? AND id %s ?)", column, comparison, column, comparison),
+			value, value, id,
+		)
+	}
+
+	err := query.
+		Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).
+		Limit(limit + 1).
+		Find(&page.Incidents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetching one extra row tells us whether another page exists
+	if len(page.Incidents) > limit {
+		page.Incidents = page.Incidents[:limit]
+		page.NextCursor = encodeIncidentCursor(page.Incidents[limit-1], sortBy)
+	}
+
+	return page, nil
+}
+
+// applyIncidentFilter adds the filter's WHERE clauses to a query
+func applyIncidentFilter(query *gorm.DB, filter IncidentFilter) *gorm.DB {
+	if len(filter.Status) > 0 {
+		query = query.Where("status IN ?", filter.Status)
+	}
+	if len(filter.Priority) > 0 {
+		query = query.Where("priority IN ?", filter.Priority)
+	}
+	if len(filter.AISeverity) > 0 {
+		query = query.Where("ai_severity IN ?", filter.AISeverity)
+	}
+	if len(filter.AICategory) > 0 {
+		query = query.Where("ai_category IN ?", filter.AICategory)
+	}
+	// Timestamps are stored as local-time text, so bounds must use the same zone to compare correctly
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", filter.CreatedAfter.In(time.Local))
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", filter.CreatedBefore.In(time.Local))
+	}
+	return query
+}
+
+// encodeIncidentCursor builds an opaque cursor pointing after the given incident
+func encodeIncidentCursor(incident model.Incident, sortBy string) string {
+	cursor := incidentCursor{SortBy: sortBy, ID: incident.ID}
+	switch sortBy {
+	case "updated_at":
+		cursor.Value = incident.UpdatedAt.Format(time.RFC3339Nano)
+	case "title":
+		cursor.Value = incident.Title
+	case "priority":
+		cursor.Value = strconv.Itoa(priorityValue(incident.Priority))
+	default:
+		cursor.Value = incident.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeIncidentCursor returns the sort value and ID stored in a cursor
+func decodeIncidentCursor(encoded, sortBy string) (interface{}, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+
+	var cursor incidentCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, "", ErrInvalidCursor
+	}
+	// A cursor is only meaningful for the sort order that produced it
+	if cursor.SortBy != sortBy {
+		return nil, "", ErrInvalidCursor
+	}
+
+	switch sortBy {
+	case "title":
+		return cursor.Value, cursor.ID, nil
+	case "priority":
+		rank, err := strconv.Atoi(cursor.Value)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		return rank, cursor.ID, nil
+	default:
+		value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		return value.In(time.Local), cursor.ID, nil
+	}
+}
+
+// priorityValue mirrors priorityRank for cursor encoding
+func priorityValue(priority string) int {
+	switch priority {
+	case "low":
+		return 0
+	case "medium":
+		return 1
+	case "high":
+		return 2
+	case "critical":
+		return 3
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+	"testing"
+	"time"
+)
+
+func createQueryTestIncidents(t *testing.T, repo *IncidentRepository, category string) {
+	incidents := []*model.Incident{
+		{Title: "Query A", Description: "First", Status: "open", Priority: "low", AISeverity: "low", AICategory: category},
+		{Title: "Query B", Description: "Second", Status: "open", Priority: "critical", AISeverity: "high", AICategory: category},
+		{Title: "Query C", Description: "Third", Status: "resolved", Priority: "medium", AISeverity: "medium", AICategory: category},
+		{Title: "Query D", Description: "Fourth", Status: "open", Priority: "high", AISeverity: "high", AICategory: category},
+		{Title: "Query E", Description: "Fifth", Status: "closed", Priority: "medium", AISeverity: "low", AICategory: category},
+	}
+	for _, incident := range incidents {
+		if err := repo.Create(incident); err != nil {
+			t.Fatalf("Failed to create incident '%s': %v", incident.Title, err)
+		}
+	}
+
+	// Remove the incidents afterwards so exact counts hold when tests are repeated
+	t.Cleanup(func() {
+		for _, incident := range incidents {
+			repo.Delete(incident.ID)
+		}
+	})
+}
+
+func TestList_CursorPagination(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	repo := NewIncidentRepository()
+	category := "query-" + t.Name()
+	createQueryTestIncidents(t, repo, category)
+
+	filter := IncidentFilter{AICategory: []string{category}, SortBy: "title", Limit: 2}
+	var titles []string
+	for page := 0; page < 5; page++ {
+		result, err := repo.List(filter)
+		if err != nil {
+			t.Fatalf("Failed to list incidents: %v", err)
+		}
+		if result.Total != 5 {
+			t.Errorf("Expected total 5, got %d", result.Total)
+		}
+		for _, incident := range result.Incidents {
+			titles = append(titles, incident.Title)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		filter.Cursor = result.NextCursor
+	}
+
+	expected := []string{"Query A", "Query B", "Query C", "Query D", "Query E"}
+	if len(titles) != len(expected) {
+		t.Fatalf("Expected %d incidents across pages, got %d: %v", len(expected), len(titles), titles)
+	}
+	for i, title := range expected {
+		if titles[i] != title {
+			t.Errorf("Expected title '%s' at position %d, got '%s'", title, i, titles[i])
+		}
+	}
+}
+
+func TestList_CreatedAtCursorPagination(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	repo := NewIncidentRepository()
+	category := "query-" + t.Name()
+	createQueryTestIncidents(t, repo, category)
+
+	filter := IncidentFilter{AICategory: []string{category}, SortDesc: true, Limit: 2}
+	seen := make(map[string]bool)
+	var previous time.Time
+	for page := 0; page < 5; page++ {
+		result, err := repo.List(filter)
+		if err != nil {
+			t.Fatalf("Failed to list incidents: %v", err)
+		}
+		for _, incident := range result.Incidents {
+			if seen[incident.ID] {
+				t.Errorf("Incident '%s' returned on more than one page", incident.Title)
+			}
+			if !previous.IsZero() && incident.CreatedAt.After(previous) {
+				t.Errorf("Expected incidents in descending creation order")
+			}
+			seen[incident.ID] = true
+			previous = incident.CreatedAt
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		filter.Cursor = result.NextCursor
+	}
+
+	if len(seen) != 5 {
+		t.Errorf("Expected 5 incidents across pages, got %d", len(seen))
+	}
+}
+
+func TestList_FiltersAndPrioritySort(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	repo := NewIncidentRepository()
+	category := "query-" + t.Name()
+	createQueryTestIncidents(t, repo, category)
+
+	result, err := repo.List(IncidentFilter{
+		AICategory: []string{category},
+		Status:     []string{"open"},
+		SortBy:     "priority",
+		SortDesc:   true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list incidents: %v", err)
+	}
+
+	expected := []string{"Query B", "Query D", "Query A"}
+	if len(result.Incidents) != len(expected) {
+		t.Fatalf("Expected %d incidents, got %d", len(expected), len(result.Incidents))
+	}
+	for i, title := range expected {
+		if result.Incidents[i].Title != title {
+			t.Errorf("Expected title '%s' at position %d, got '%s'", title, i, result.Incidents[i].Title)
+		}
+	}
+
+	future := time.Now().Add(time.Hour)
+	result, err = repo.List(IncidentFilter{AICategory: []string{category}, CreatedAfter: &future})
+	if err != nil {
+		t.Fatalf("Failed to list incidents: %v", err)
+	}
+	if result.Total != 0 {
+		t.Errorf("Expected no incidents created in the future, got %d", result.Total)
+	}
+}
+
+func TestList_InvalidCursor(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	repo := NewIncidentRepository()
+	if _, err := repo.List(IncidentFilter{Cursor: "not-a-cursor"}); err != ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestIncidentFilter_Validate(t *testing.T) {
+	filter := IncidentFilter{SortBy: "description", Limit: MaxPageSize + 1}
+	errors := filter.Validate()
+	if errors["sort"] == "" {
+		t.Error("Expected sort validation error")
+	}
+	if errors["limit"] == "" {
+		t.Error("Expected limit validation error")
+	}
+
+	valid := IncidentFilter{SortBy: "priority", Limit: 10}
+	if errors := valid.Validate(); errors != nil {
+		t.Errorf("Expected no errors, got %v", errors)
+	}
+}
@@ -1,12 +1,18 @@
 package repository
 
 import (
+	"errors"
 	"incident-management/database"
 	"incident-management/model"
+	"sync"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
 type IncidentRepository struct {
 	db *gorm.DB
 }
@@ -18,9 +24,99 @@ func NewIncidentRepository() *IncidentRepository {
 	}
 }
 
-// Create creates a new incident
+// Create creates a new incident and adds it to the search index
 func (r *IncidentRepository) Create(incident *model.Incident) error {
-	return r.db.Create(incident).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(incident).Error; err != nil {
+			return err
+		}
+		return indexIncident(tx, incident)
+	})
+}
+
+// CreateWithJob creates a new incident and queues its classification job in one transaction
+func (r *IncidentRepository) CreateWithJob(incident *model.Incident, job *model.ClassificationJob) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createWithJob(tx, incident, job)
+	})
+}
+
+// dedupMu serializes CreateOrAttach. SQLite fails the second of two concurrent writers instead
+// of making it wait, so without it two reports of one incident could both miss the lookup and
+// one of them would fail to commit.
+var dedupMu sync.Mutex
+
+// CreateOrAttach finds the most recently reported incident with the given fingerprint and one of
+// the given statuses that was last reported at or after since. If there is one, it records the
+// occurrence on it, increments its occurrence count and records the field changes diff reports,
+// and returns it with attached set. Otherwise it creates the new incident and queues its
+// classification job. The lookup and the write happen in one transaction.
+func (r *IncidentRepository) CreateOrAttach(incident *model.Incident, job *model.ClassificationJob, statuses []string, since time.Time, occurrence *model.IncidentOccurrence, diff func(before, after *model.Incident) []model.IncidentChange) (*model.Incident, bool, error) {
+	dedupMu.Lock()
+	defer dedupMu.Unlock()
+
+	var existing model.Incident
+	attached := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("fingerprint = ? AND status IN ? AND last_occurrence_at >= ?", incident.Fingerprint, statuses, since).
+			Order("last_occurrence_at desc").
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return createWithJob(tx, incident, job)
+		}
+		if err != nil {
+			return err
+		}
+
+		attached = true
+		occurrence.IncidentID = existing.ID
+		if err := tx.Create(occurrence).Error; err != nil {
+			return err
+		}
+		err = tx.Model(&model.Incident{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+			"occurrence_count":   gorm.Expr("occurrence_count + 1"),
+			"last_occurrence_at": occurrence.CreatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		before := existing
+		if err := tx.Where("id = ?", existing.ID).First(&existing).Error; err != nil {
+			return err
+		}
+		return recordChanges(tx, diff(&before, &existing))
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if attached {
+		return &existing, true, nil
+	}
+	return incident, false, nil
+}
+
+// createWithJob creates an incident, adds it to the search index and queues its classification job
+func createWithJob(tx *gorm.DB, incident *model.Incident, job *model.ClassificationJob) error {
+	if err := tx.Create(incident).Error; err != nil {
+		return err
+	}
+	if err := indexIncident(tx, incident); err != nil {
+		return err
+	}
+	job.IncidentID = incident.ID
+	return tx.Create(job).Error
+}
+
+// ListOccurrences retrieves the repeat reports attached to an incident, oldest first
+func (r *IncidentRepository) ListOccurrences(incidentID string) ([]model.IncidentOccurrence, error) {
+	var occurrences []model.IncidentOccurrence
+	err := r.db.Where("incident_id = ?", incidentID).Order("created_at asc").Find(&occurrences).Error
+	return occurrences, err
+}
+
+// SetClassificationStatus changes only the classification state of an incident
+func (r *IncidentRepository) SetClassificationStatus(id, status string) error {
+	return r.db.Model(&model.Incident{}).Where("id = ?", id).Update("classification_status", status).Error
 }
 
 // GetAll retrieves all incidents
@@ -29,3 +125,75 @@ func (r *IncidentRepository) GetAll() ([]model.Incident, error) {
 	err := r.db.Find(&incidents).Error
 	return incidents, err
 }
+
+// GetByID retrieves a single incident by its ID
+func (r *IncidentRepository) GetByID(id string) (*model.Incident, error) {
+	var incident model.Incident
+	err := r.db.Where("id = ?", id).First(&incident).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// GetByIDs retrieves the incidents with the given IDs in one query, in no particular order.
+// Unknown IDs are left out.
+func (r *IncidentRepository) GetByIDs(ids []string) ([]model.Incident, error) {
+	var incidents []model.Incident
+	if len(ids) == 0 {
+		return incidents, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&incidents).Error
+	return incidents, err
+}
+
+// Update saves all fields of an existing incident, records the field changes and refreshes
+// its search index entry
+func (r *IncidentRepository) Update(incident *model.Incident, changes []model.IncidentChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(incident).Error; err != nil {
+			return err
+		}
+		if err := recordChanges(tx, changes); err != nil {
+			return err
+		}
+		return indexIncident(tx, incident)
+	})
+}
+
+// incidentRecords are the per-incident tables whose rows are deleted with their incident
+var incidentRecords = []interface{}{
+	&model.IncidentEmbedding{},
+	&model.IncidentOccurrence{},
+	&model.StatusTransition{},
+	&model.Classification{},
+	&model.ClassificationOverride{},
+	&model.ClassificationJob{},
+	&model.IncidentSummary{},
+	&model.IncidentPostmortem{},
+	&model.RemediationSuggestion{},
+	&model.Comment{},
+	&model.IncidentChange{},
+}
+
+// Delete removes an incident by its ID, along with its search index entry and every per-incident record
+func (r *IncidentRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&model.Incident{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		for _, record := range incidentRecords {
+			if err := tx.Where("incident_id = ?", id).Delete(record).Error; err != nil {
+				return err
+			}
+		}
+		return unindexIncident(tx, id)
+	})
+}
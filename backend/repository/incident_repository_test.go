@@ -5,6 +5,7 @@ import (
 	"incident-management/model"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestNewIncidentRepository(t *testing.T) {
@@ -173,6 +174,113 @@ func TestCreateMultipleIncidents(t *testing.T) {
 	}
 }
 
+func TestGetByIDUpdateAndDelete(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	repo := NewIncidentRepository()
+
+	incident := &model.Incident{
+		Title:       "Repository Lifecycle Incident",
+		Description: "Incident used to test single-record operations",
+		Status:      "open",
+		Priority:    "low",
+		AISeverity:  "low",
+		AICategory:  "network",
+	}
+	if err := repo.Create(incident); err != nil {
+		t.Fatalf("Failed to create incident: %v", err)
+	}
+
+	// Test GetByID
+	found, err := repo.GetByID(incident.ID)
+	if err != nil {
+		t.Fatalf("Failed to get incident by ID: %v", err)
+	}
+	if found.Title != incident.Title {
+		t.Errorf("Expected title '%s', got '%s'", incident.Title, found.Title)
+	}
+
+	// Test Update
+	found.Priority = "critical"
+	if err := repo.Update(found, nil); err != nil {
+		t.Fatalf("Failed to update incident: %v", err)
+	}
+	updated, err := repo.GetByID(incident.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload incident: %v", err)
+	}
+	if updated.Priority != "critical" {
+		t.Errorf("Expected priority 'critical', got '%s'", updated.Priority)
+	}
+
+	// Test Delete
+	if err := repo.Delete(incident.ID); err != nil {
+		t.Fatalf("Failed to delete incident: %v", err)
+	}
+	if _, err := repo.GetByID(incident.ID); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete(incident.ID); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestDeleteRemovesPerIncidentRecords(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	repo := NewIncidentRepository()
+	incident := &model.Incident{
+		Title:       "Repository Cascade Incident",
+		Description: "Incident used to test that deletes remove every dependent record",
+		Status:      "open",
+		Priority:    "low",
+	}
+	job := &model.ClassificationJob{Status: model.JobQueued, RunAt: time.Now()}
+	if err := repo.CreateWithJob(incident, job); err != nil {
+		t.Fatalf("Failed to create incident: %v", err)
+	}
+
+	db := database.GetDB()
+	records := []interface{}{
+		&model.IncidentEmbedding{IncidentID: incident.ID, Model: "test-model"},
+		&model.IncidentOccurrence{IncidentID: incident.ID, Title: incident.Title},
+		&model.StatusTransition{IncidentID: incident.ID, Action: "start", FromStatus: "open", ToStatus: "in_progress"},
+		&model.Classification{IncidentID: incident.ID, Severity: "low", Category: "network"},
+		&model.ClassificationOverride{IncidentID: incident.ID, HumanSeverity: "high"},
+		&model.IncidentSummary{IncidentID: incident.ID, Version: 1, Summary: "Summary"},
+		&model.IncidentPostmortem{IncidentID: incident.ID, Version: 1, Markdown: "# Postmortem"},
+		&model.RemediationSuggestion{IncidentID: incident.ID},
+		&model.Comment{IncidentID: incident.ID, Body: "Looking"},
+		&model.IncidentChange{IncidentID: incident.ID, Field: "priority", Source: model.ChangeSourceAPI},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("Failed to create %T: %v", record, err)
+		}
+	}
+
+	if err := repo.Delete(incident.ID); err != nil {
+		t.Fatalf("Failed to delete incident: %v", err)
+	}
+	for _, record := range incidentRecords {
+		var count int64
+		if err := db.Model(record).Where("incident_id = ?", incident.ID).Count(&count).Error; err != nil {
+			t.Fatalf("Failed to count %T: %v", record, err)
+		}
+		if count != 0 {
+			t.Errorf("Expected no %T rows after delete, got %d", record, count)
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Clean up test database before running tests
 	os.Remove("incidents.db")
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"errors"
+	"html"
+	"incident-management/database"
+	"incident-management/model"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// highlightStart and highlightEnd wrap matched terms in titles and snippets
+	highlightStart = "<mark>"
+	highlightEnd   = "</mark>"
+	// matchStart and matchEnd are the control characters FTS5 marks matches with, replaced by
+	// the highlight tags once the text is HTML-escaped
+	matchStart = "\x02"
+	matchEnd   = "\x03"
+
+	// fallbackCandidateLimit bounds how many rows the LIKE fallback scores in memory
+	fallbackCandidateLimit = 500
+	// fallbackSnippetRadius is the number of bytes of context kept around the first match
+	fallbackSnippetRadius = 80
+)
+
+// ErrInvalidSearchQuery is returned when a search query cannot be parsed
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// SearchResult is an incident matching a full-text search, with highlighted text
+type SearchResult struct {
+	Incident       model.Incident `json:"incident"`
+	Score          float64        `json:"score"`
+	TitleHighlight string         `json:"title_highlight"`
+	Snippet        string         `json:"snippet"`
+}
+
+// searchTerm is a word or quoted phrase from a search query
+type searchTerm struct {
+	text   string
+	prefix bool
+}
+
+// ftsMatch is a row returned by the FTS5 search query
+type ftsMatch struct {
+	IncidentID     string
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// SearchEngine reports which search implementation is active
+func SearchEngine() string {
+	if database.FTSEnabled {
+		return "fts5"
+	}
+	return "like"
+}
+
+// Search ranks incidents whose title or description match the query.
+// Titles weigh ten times more than descriptions. Phrases ("disk full") and
+// prefixes (dns*) are supported by both the FTS5 index and the LIKE fallback.
+func (r *IncidentRepository) Search(query string, limit int) ([]SearchResult, error) {
+	if database.FTSEnabled {
+		return r.searchFTS(query, limit)
+	}
+	return r.searchLike(query, limit)
+}
+
+// searchFTS runs the query against the FTS5 index using bm25 ranking
+func (r *IncidentRepository) searchFTS(query string, limit int) ([]SearchResult, error) {
+	var matches []ftsMatch
+	err := r.db.Raw(`SELECT incident_id,
+			bm25(incidents_fts, 0.0, 10.0, 1.0) AS rank,
+			highlight(incidents_fts, 1, ?, ?) AS title_highlight,
+			snippet(incidents_fts, 2, ?, ?, '…', 24) AS snippet
+		FROM incidents_fts
+		WHERE incidents_fts MATCH ?
+		ORDER BY rank
+		LIMIT ?`,
+		matchStart, matchEnd, matchStart, matchEnd, query, limit,
+	).Scan(&matches).Error
+	if err != nil {
+		if isFTSQueryError(err) {
+			return nil, ErrInvalidSearchQuery
+		}
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.IncidentID)
+	}
+	incidents, err := r.getByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(matches))
+	for _, match := range matches {
+		incident, ok := incidents[match.IncidentID]
+		if !ok {
+			continue
+		}
+		results = append(results, SearchResult{
+			Incident: incident,
+			// bm25 scores are negative with the best match lowest; flip so higher is better
+			Score:          -match.Rank,
+			TitleHighlight: renderMatches(match.TitleHighlight),
+			Snippet:        renderMatches(match.Snippet),
+		})
+	}
+	return results, nil
+}
+
+// searchLike matches every term with LIKE and ranks candidates in memory
+func (r *IncidentRepository) searchLike(query string, limit int) ([]SearchResult, error) {
+	terms := parseSearchTerms(query)
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	db := r.db.Model(&model.Incident{})
+	patterns := make([]string, 0, len(terms))
+	for _, term := range terms {
+		like := "%" + escapeLike(strings.ToLower(term.text)) + "%"
+		db = db.Where("(LOWER(title) LIKE ? ESCAPE '\\' OR LOWER(description) LIKE ? ESCAPE '\\')", like, like)
+		pattern := regexp.QuoteMeta(term.text)
+		if term.prefix {
+			pattern = `\b` + pattern + `\w*`
+		}
+		patterns = append(patterns, pattern)
+	}
+	matcher := regexp.MustCompile("(?i)" + strings.Join(patterns, "|"))
+
+	var candidates []model.Incident
+	if err := db.Order("created_at desc").Limit(fallbackCandidateLimit).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(candidates))
+	for _, incident := range candidates {
+		titleMatches := len(matcher.FindAllStringIndex(incident.Title, -1))
+		descriptionMatches := matcher.FindAllStringIndex(incident.Description, -1)
+		results = append(results, SearchResult{
+			Incident:       incident,
+			Score:          float64(titleMatches*10 + len(descriptionMatches)),
+			TitleHighlight: highlight(matcher, incident.Title),
+			Snippet:        fallbackSnippet(matcher, incident.Description, descriptionMatches),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// getByIDs loads incidents keyed by ID
+func (r *IncidentRepository) getByIDs(ids []string) (map[string]model.Incident, error) {
+	incidents := make(map[string]model.Incident, len(ids))
+	if len(ids) == 0 {
+		return incidents, nil
+	}
+
+	var rows []model.Incident
+	if err := r.db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, incident := range rows {
+		incidents[incident.ID] = incident
+	}
+	return incidents, nil
+}
+
+// indexIncident replaces the search index entry of an incident
+func indexIncident(tx *gorm.DB, incident *model.Incident) error {
+	if !database.FTSEnabled {
+		return nil
+	}
+	if err := unindexIncident(tx, incident.ID); err != nil {
+		return err
+	}
+	return tx.Exec(
+		"INSERT INTO incidents_fts (incident_id, title, description) VALUES (?, ?, ?)",
+		incident.ID, incident.Title, incident.Description,
+	).Error
+}
+
+// unindexIncident removes an incident from the search index
+func unindexIncident(tx *gorm.DB, id string) error {
+	if !database.FTSEnabled {
+		return nil
+	}
+	return tx.Exec("DELETE FROM incidents_fts WHERE incident_id = ?", id).Error
+}
+
+// isFTSQueryError reports whether an error was caused by malformed MATCH syntax
+func isFTSQueryError(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "fts5: syntax error") ||
+		strings.Contains(message, "no such column") ||
+		strings.Contains(message, "unterminated string")
+}
+
+// parseSearchTerms splits a query into words and quoted phrases, dropping FTS operators
+func parseSearchTerms(query string) []searchTerm {
+	var terms []searchTerm
+	for _, token := range regexp.MustCompile(`"[^"]*"|\S+`).FindAllString(query, -1) {
+		if strings.HasPrefix(token, `"`) {
+			if phrase := strings.TrimSpace(strings.Trim(token, `"`)); phrase != "" {
+				terms = append(terms, searchTerm{text: phrase})
+			}
+			continue
+		}
+		switch token {
+		case "AND", "OR", "NOT", "NEAR":
+			continue
+		}
+		term := searchTerm{text: strings.Trim(token, `()^+-`)}
+		if strings.HasSuffix(term.text, "*") {
+			term.text = strings.TrimRight(term.text, "*")
+			term.prefix = true
+		}
+		if term.text != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// escapeLike escapes LIKE wildcards so terms match literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// highlight HTML-escapes text and wraps every match in highlight tags
+func highlight(matcher *regexp.Regexp, text string) string {
+	var highlighted strings.Builder
+	last := 0
+	for _, match := range matcher.FindAllStringIndex(text, -1) {
+		highlighted.WriteString(html.EscapeString(text[last:match[0]]))
+		highlighted.WriteString(highlightStart + html.EscapeString(text[match[0]:match[1]]) + highlightEnd)
+		last = match[1]
+	}
+	highlighted.WriteString(html.EscapeString(text[last:]))
+	return highlighted.String()
+}
+
+// renderMatches HTML-escapes text marked by FTS5 and turns the match markers into highlight tags
+func renderMatches(marked string) string {
+	return strings.NewReplacer(matchStart, highlightStart, matchEnd, highlightEnd).Replace(html.EscapeString(marked))
+}
+
+// fallbackSnippet returns highlighted context around the first match in text
+func fallbackSnippet(matcher *regexp.Regexp, text string, matches [][]int) string {
+	if len(matches) == 0 {
+		if len(text) <= 2*fallbackSnippetRadius {
+			return html.EscapeString(text)
+		}
+		return html.EscapeString(text[:runeBoundary(text, 2*fallbackSnippetRadius)]) + "…"
+	}
+
+	start := runeBoundary(text, matches[0][0]-fallbackSnippetRadius)
+	end := runeBoundary(text, matches[0][1]+fallbackSnippetRadius)
+	snippet := highlight(matcher, text[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// runeBoundary clamps a byte offset into text and moves it back to the start of a rune
+func runeBoundary(text string, offset int) int {
+	if offset <= 0 {
+		return 0
+	}
+	if offset >= len(text) {
+		return len(text)
+	}
+	for offset > 0 && !utf8.RuneStart(text[offset]) {
+		offset--
+	}
+	return offset
+}
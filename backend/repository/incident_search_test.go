@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	// Initialize test database
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	repo := NewIncidentRepository()
+
+	incidents := []*model.Incident{
+		{Title: "Resolver outage in eu-west", Description: "Upstream resolvers returned SERVFAIL for internal zones"},
+		{Title: "Checkout latency", Description: "Payments slowed down because the DNS resolver cache was flushed"},
+		{Title: "Disk full on build agent", Description: "The build agent ran out of disk space during nightly builds"},
+	}
+	for _, incident := range incidents {
+		incident.Status, incident.Priority, incident.AISeverity, incident.AICategory = "open", "medium", "medium", "software"
+		if err := repo.Create(incident); err != nil {
+			t.Fatalf("Failed to create incident: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, incident := range incidents {
+			repo.Delete(incident.ID)
+		}
+	})
+
+	t.Run("Title matches rank first", func(t *testing.T) {
+		results, err := repo.Search("resolver", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) < 2 {
+			t.Fatalf("Expected at least 2 results, got %d", len(results))
+		}
+		if results[0].Incident.ID != incidents[0].ID {
+			t.Errorf("Expected title match to rank first, got '%s'", results[0].Incident.Title)
+		}
+		if !strings.Contains(results[0].TitleHighlight, "<mark>") {
+			t.Errorf("Expected highlighted title, got '%s'", results[0].TitleHighlight)
+		}
+	})
+
+	t.Run("Phrase query", func(t *testing.T) {
+		results, err := repo.Search(`"disk space"`, 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Incident.ID != incidents[2].ID {
+			t.Fatalf("Expected only the disk incident, got %d results", len(results))
+		}
+		if !strings.Contains(results[0].Snippet, "<mark>") {
+			t.Errorf("Expected highlighted snippet, got '%s'", results[0].Snippet)
+		}
+	})
+
+	t.Run("Prefix query", func(t *testing.T) {
+		results, err := repo.Search("paym*", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Incident.ID != incidents[1].ID {
+			t.Errorf("Expected only the checkout incident, got %d results", len(results))
+		}
+	})
+
+	t.Run("Highlights are HTML-escaped", func(t *testing.T) {
+		markup := &model.Incident{
+			Title:       `<script>alert(1)</script> resolver`,
+			Description: `Payload <img src=x onerror=alert(1)> in the resolver logs`,
+			Status:      "open", Priority: "medium", AISeverity: "medium", AICategory: "software",
+		}
+		if err := repo.Create(markup); err != nil {
+			t.Fatalf("Failed to create incident: %v", err)
+		}
+		defer repo.Delete(markup.ID)
+
+		results, err := repo.Search("alert", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results))
+		}
+		for _, text := range []string{results[0].TitleHighlight, results[0].Snippet} {
+			if strings.Contains(text, "<script>") || strings.Contains(text, "<img") {
+				t.Errorf("Expected markup to be escaped, got '%s'", text)
+			}
+			if !strings.Contains(text, "<mark>alert</mark>") {
+				t.Errorf("Expected highlighted match, got '%s'", text)
+			}
+		}
+		if !strings.Contains(results[0].TitleHighlight, "&lt;script&gt;") {
+			t.Errorf("Expected escaped title, got '%s'", results[0].TitleHighlight)
+		}
+	})
+
+	t.Run("Deleted incidents are not returned", func(t *testing.T) {
+		if err := repo.Delete(incidents[2].ID); err != nil {
+			t.Fatalf("Failed to delete incident: %v", err)
+		}
+		results, err := repo.Search("disk", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Expected no results after delete, got %d", len(results))
+		}
+	})
+}
+
+func TestParseSearchTerms(t *testing.T) {
+	terms := parseSearchTerms(`"disk full" OR dns* (latency)`)
+	expected := []searchTerm{
+		{text: "disk full"},
+		{text: "dns", prefix: true},
+		{text: "latency"},
+	}
+	if len(terms) != len(expected) {
+		t.Fatalf("Expected %d terms, got %d: %+v", len(expected), len(terms), terms)
+	}
+	for i := range expected {
+		if terms[i] != expected[i] {
+			t.Errorf("Expected term %+v, got %+v", expected[i], terms[i])
+		}
+	}
+}
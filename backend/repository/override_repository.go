@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+)
+
+type OverrideRepository struct {
+	db *gorm.DB
+}
+
+// NewOverrideRepository creates a new classification override repository
+func NewOverrideRepository() *OverrideRepository {
+	return &OverrideRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Apply stores the human values on the incident and records the override and field changes
+// in a single transaction
+func (r *OverrideRepository) Apply(override *model.ClassificationOverride, changes []model.IncidentChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Incident{}).Where("id = ?", override.IncidentID).Updates(map[string]interface{}{
+			"human_severity": override.HumanSeverity,
+			"human_category": override.HumanCategory,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if err := tx.Create(override).Error; err != nil {
+			return err
+		}
+		return recordChanges(tx, changes)
+	})
+}
+
+// ListByIncident retrieves the overrides of an incident, oldest first
+func (r *OverrideRepository) ListByIncident(incidentID string) ([]model.ClassificationOverride, error) {
+	var overrides []model.ClassificationOverride
+	err := r.db.Where("incident_id = ?", incidentID).Order("created_at asc").Find(&overrides).Error
+	return overrides, err
+}
+
+// ListOverriddenIncidents retrieves every incident with a human severity or category, oldest first
+func (r *OverrideRepository) ListOverriddenIncidents() ([]model.Incident, error) {
+	var incidents []model.Incident
+	err := r.db.Where("human_severity <> '' OR human_category <> ''").Order("created_at asc").Find(&incidents).Error
+	return incidents, err
+}
+
+// ListRecentlyOverridden retrieves the incidents with the most recent overrides, newest first
+func (r *OverrideRepository) ListRecentlyOverridden(limit int) ([]model.Incident, error) {
+	var incidents []model.Incident
+	err := r.db.Model(&model.Incident{}).
+		Joins("JOIN (SELECT incident_id, MAX(created_at) AS overridden_at FROM classification_overrides GROUP BY incident_id) latest ON latest.incident_id = incidents.id").
+		Where("incidents.human_severity <> '' OR incidents.human_category <> ''").
+		Order("latest.overridden_at desc").
+		Limit(limit).
+		Find(&incidents).Error
+	return incidents, err
+}
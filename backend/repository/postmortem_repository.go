@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+)
+
+type PostmortemRepository struct {
+	versions versionedRecords[model.IncidentPostmortem]
+}
+
+// NewPostmortemRepository creates a new incident postmortem repository
+func NewPostmortemRepository() *PostmortemRepository {
+	return &PostmortemRepository{
+		versions: versionedRecords[model.IncidentPostmortem]{db: database.GetDB()},
+	}
+}
+
+// Create stores a postmortem as the next version for its incident
+func (r *PostmortemRepository) Create(postmortem *model.IncidentPostmortem) error {
+	return r.versions.create(postmortem.IncidentID, postmortem, func(version int) { postmortem.Version = version })
+}
+
+// GetLatest retrieves the newest postmortem of an incident
+func (r *PostmortemRepository) GetLatest(incidentID string) (*model.IncidentPostmortem, error) {
+	return r.versions.latest(incidentID)
+}
+
+// GetVersion retrieves a specific postmortem version of an incident
+func (r *PostmortemRepository) GetVersion(incidentID string, version int) (*model.IncidentPostmortem, error) {
+	return r.versions.version(incidentID, version)
+}
+
+// ListByIncident retrieves every postmortem version of an incident, newest first
+func (r *PostmortemRepository) ListByIncident(incidentID string) ([]model.IncidentPostmortem, error) {
+	return r.versions.list(incidentID)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReclassificationJobRepository struct {
+	db *gorm.DB
+}
+
+// NewReclassificationJobRepository creates a new bulk reclassification job repository
+func NewReclassificationJobRepository() *ReclassificationJobRepository {
+	return &ReclassificationJobRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create stores a new bulk job
+func (r *ReclassificationJobRepository) Create(job *model.ReclassificationJob) error {
+	return r.db.Create(job).Error
+}
+
+// Save persists the state and progress of a bulk job
+func (r *ReclassificationJobRepository) Save(job *model.ReclassificationJob) error {
+	return r.db.Save(job).Error
+}
+
+// GetByID retrieves a bulk job by ID
+func (r *ReclassificationJobRepository) GetByID(id string) (*model.ReclassificationJob, error) {
+	var job model.ReclassificationJob
+	err := r.db.Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List retrieves the most recent bulk jobs, newest first
+func (r *ReclassificationJobRepository) List(limit int) ([]model.ReclassificationJob, error) {
+	var jobs []model.ReclassificationJob
+	err := r.db.Order("created_at desc").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// FailUnfinished marks jobs left queued or running by a previous process as failed.
+// It returns the number of jobs updated.
+func (r *ReclassificationJobRepository) FailUnfinished(reason string) (int64, error) {
+	now := time.Now()
+	result := r.db.Model(&model.ReclassificationJob{}).
+		Where("status IN ?", []string{model.ReclassificationQueued, model.ReclassificationRunning}).
+		Updates(map[string]interface{}{
+			"status":      model.ReclassificationFailed,
+			"last_error":  reason,
+			"finished_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+)
+
+type RemediationRepository struct {
+	db *gorm.DB
+}
+
+// NewRemediationRepository creates a new remediation suggestion repository
+func NewRemediationRepository() *RemediationRepository {
+	return &RemediationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create stores a remediation suggestion
+func (r *RemediationRepository) Create(suggestion *model.RemediationSuggestion) error {
+	return r.db.Create(suggestion).Error
+}
+
+// Get retrieves a suggestion of an incident by its ID
+func (r *RemediationRepository) Get(incidentID, id string) (*model.RemediationSuggestion, error) {
+	var suggestion model.RemediationSuggestion
+	err := r.db.Where("incident_id = ? AND id = ?", incidentID, id).First(&suggestion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+// SaveFeedback stores the feedback fields of a suggestion
+func (r *RemediationRepository) SaveFeedback(suggestion *model.RemediationSuggestion) error {
+	return r.db.Model(suggestion).Select("helpful", "feedback_comment", "feedback_actor", "feedback_at").Updates(suggestion).Error
+}
+
+// ListByIncident retrieves the suggestions of an incident, newest first
+func (r *RemediationRepository) ListByIncident(incidentID string) ([]model.RemediationSuggestion, error) {
+	var suggestions []model.RemediationSuggestion
+	err := r.db.Where("incident_id = ?", incidentID).Order("created_at desc").Find(&suggestions).Error
+	return suggestions, err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+)
+
+type SummaryRepository struct {
+	versions versionedRecords[model.IncidentSummary]
+}
+
+// NewSummaryRepository creates a new incident summary repository
+func NewSummaryRepository() *SummaryRepository {
+	return &SummaryRepository{
+		versions: versionedRecords[model.IncidentSummary]{db: database.GetDB()},
+	}
+}
+
+// Create stores a summary as the next version for its incident
+func (r *SummaryRepository) Create(summary *model.IncidentSummary) error {
+	return r.versions.create(summary.IncidentID, summary, func(version int) { summary.Version = version })
+}
+
+// GetLatest retrieves the newest summary of an incident
+func (r *SummaryRepository) GetLatest(incidentID string) (*model.IncidentSummary, error) {
+	return r.versions.latest(incidentID)
+}
+
+// GetVersion retrieves a specific summary version of an incident
+func (r *SummaryRepository) GetVersion(incidentID string, version int) (*model.IncidentSummary, error) {
+	return r.versions.version(incidentID, version)
+}
+
+// ListByIncident retrieves every summary version of an incident, newest first
+func (r *SummaryRepository) ListByIncident(incidentID string) ([]model.IncidentSummary, error) {
+	return r.versions.list(incidentID)
+}
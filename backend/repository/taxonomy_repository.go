@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+)
+
+type TaxonomyRepository struct {
+	db *gorm.DB
+}
+
+// taxonomyColumns maps a taxonomy kind to the incident columns that hold its values
+var taxonomyColumns = map[string][2]string{
+	model.TaxonomySeverity: {"ai_severity", "human_severity"},
+	model.TaxonomyCategory: {"ai_category", "human_category"},
+}
+
+// NewTaxonomyRepository creates a new taxonomy repository
+func NewTaxonomyRepository() *TaxonomyRepository {
+	return &TaxonomyRepository{
+		db: database.GetDB(),
+	}
+}
+
+// List retrieves every taxonomy term ordered by kind and position
+func (r *TaxonomyRepository) List() ([]model.TaxonomyTerm, error) {
+	var terms []model.TaxonomyTerm
+	err := r.db.Order("kind asc, position asc, id asc").Find(&terms).Error
+	return terms, err
+}
+
+// Get retrieves a single term by kind and value
+func (r *TaxonomyRepository) Get(kind, value string) (*model.TaxonomyTerm, error) {
+	var term model.TaxonomyTerm
+	err := r.db.Where("kind = ? AND value = ?", kind, value).First(&term).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+// Save creates or updates a term. A default term clears the default flag of the other terms of its kind.
+func (r *TaxonomyRepository) Save(term *model.TaxonomyTerm) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if term.IsDefault {
+			err := tx.Model(&model.TaxonomyTerm{}).
+				Where("kind = ? AND id <> ?", term.Kind, term.ID).
+				Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Save(term).Error
+	})
+}
+
+// Delete removes a term
+func (r *TaxonomyRepository) Delete(term *model.TaxonomyTerm) error {
+	return r.db.Delete(term).Error
+}
+
+// CountUsage counts the incidents whose AI or human fields use a term
+func (r *TaxonomyRepository) CountUsage(kind, value string) (int64, error) {
+	columns, ok := taxonomyColumns[kind]
+	if !ok {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.Model(&model.Incident{}).
+		Where(columns[0]+" = ? OR "+columns[1]+" = ?", value, value).
+		Count(&count).Error
+	return count, err
+}
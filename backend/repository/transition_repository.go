@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+
+	"gorm.io/gorm"
+)
+
+type TransitionRepository struct {
+	db *gorm.DB
+}
+
+// NewTransitionRepository creates a new status transition repository
+func NewTransitionRepository() *TransitionRepository {
+	return &TransitionRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Apply saves the incident, records the transition and field changes and refreshes the incident's
+// search index entry in a single database transaction
+func (r *TransitionRepository) Apply(incident *model.Incident, transition *model.StatusTransition, changes []model.IncidentChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(incident).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(transition).Error; err != nil {
+			return err
+		}
+		if err := recordChanges(tx, changes); err != nil {
+			return err
+		}
+		return indexIncident(tx, incident)
+	})
+}
+
+// ListByIncident retrieves the transitions of an incident, oldest first
+func (r *TransitionRepository) ListByIncident(incidentID string) ([]model.StatusTransition, error) {
+	var transitions []model.StatusTransition
+	err := r.db.Where("incident_id = ?", incidentID).Order("created_at asc").Find(&transitions).Error
+	return transitions, err
+}
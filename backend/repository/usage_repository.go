@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UsageGroups maps the fields usage can be grouped by to their SQL expressions.
+// Days are calendar days in UTC.
+var UsageGroups = map[string]string{
+	"day":       "strftime('%Y-%m-%d', created_at)",
+	"provider":  "provider",
+	"model":     "model",
+	"operation": "operation",
+}
+
+// UsageAggregate is the summed usage of one group of provider calls.
+// Only the fields the usage was grouped by are set.
+type UsageAggregate struct {
+	Day              string  `json:"day,omitempty"`
+	Provider         string  `json:"provider,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Operation        string  `json:"operation,omitempty"`
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+type UsageRepository struct {
+	db *gorm.DB
+}
+
+// NewUsageRepository creates a new AI usage repository
+func NewUsageRepository() *UsageRepository {
+	return &UsageRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Record stores the usage of a provider call
+func (r *UsageRepository) Record(usage *model.AIUsage) error {
+	return r.db.Create(usage).Error
+}
+
+// TotalCost sums the cost of the calls made in [from, to)
+func (r *UsageRepository) TotalCost(from, to time.Time) (float64, error) {
+	var total float64
+	err := r.db.Model(&model.AIUsage{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Select("COALESCE(SUM(cost), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// Aggregate sums the usage of the calls made in [from, to), grouped by the given
+// UsageGroups keys in order. Without groups a single total is returned.
+func (r *UsageRepository) Aggregate(from, to time.Time, groups []string) ([]UsageAggregate, error) {
+	columns := make([]string, 0, len(groups)+5)
+	expressions := make([]string, 0, len(groups))
+	for _, group := range groups {
+		expression := UsageGroups[group]
+		columns = append(columns, expression+" AS "+group)
+		expressions = append(expressions, expression)
+	}
+	columns = append(columns,
+		"COUNT(*) AS requests",
+		"COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens",
+		"COALESCE(SUM(completion_tokens), 0) AS completion_tokens",
+		"COALESCE(SUM(total_tokens), 0) AS total_tokens",
+		"COALESCE(SUM(cost), 0) AS cost",
+	)
+
+	query := r.db.Model(&model.AIUsage{}).
+		Select(strings.Join(columns, ", ")).
+		Where("created_at >= ? AND created_at < ?", from, to)
+	if len(expressions) > 0 {
+		query = query.Group(strings.Join(expressions, ", ")).Order(strings.Join(expressions, ", "))
+	}
+
+	var aggregates []UsageAggregate
+	err := query.Scan(&aggregates).Error
+	return aggregates, err
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// versionedRecords stores records that are kept in numbered versions per incident,
+// such as summaries and postmortem drafts
+type versionedRecords[T any] struct {
+	db *gorm.DB
+}
+
+// create stores a record as the next version for its incident; setVersion assigns the version
+func (r versionedRecords[T]) create(incidentID string, record *T, setVersion func(version int)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Model(new(T)).Where("incident_id = ?", incidentID).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		setVersion(latest + 1)
+		return tx.Create(record).Error
+	})
+}
+
+// latest retrieves the newest version of an incident's record
+func (r versionedRecords[T]) latest(incidentID string) (*T, error) {
+	return r.first(r.db.Where("incident_id = ?", incidentID).Order("version desc"))
+}
+
+// version retrieves a specific version of an incident's record
+func (r versionedRecords[T]) version(incidentID string, version int) (*T, error) {
+	return r.first(r.db.Where("incident_id = ? AND version = ?", incidentID, version))
+}
+
+// list retrieves every version of an incident's record, newest first
+func (r versionedRecords[T]) list(incidentID string) ([]T, error) {
+	var records []T
+	err := r.db.Where("incident_id = ?", incidentID).Order("version desc").Find(&records).Error
+	return records, err
+}
+
+// first returns the first record matching the query
+func (r versionedRecords[T]) first(query *gorm.DB) (*T, error) {
+	var record T
+	err := query.First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -2,169 +2,408 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"incident-management/model"
+	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
 
+// AIService classifies incidents with an OpenAI or OpenAI-compatible chat completion API
 type AIService struct {
-	client *openai.Client
+	client   *openai.Client
+	provider string
+	model    string
+	apiKey   string
+	// fallback classifies incidents when no API key is configured or the provider fails
+	fallback Classifier
+	// prompt renders the classification prompt; its version is recorded on every result
+	prompt *PromptTemplate
+	// summaryPrompt renders the incident summary prompt
+	summaryPrompt *PromptTemplate
+	// remediationPrompt renders the remediation suggestion prompt
+	remediationPrompt *PromptTemplate
+	// postmortemPrompt renders the postmortem draft prompt
+	postmortemPrompt *PromptTemplate
+	// fewShot is the number of human-corrected incidents included in the prompt
+	fewShot  int
+	examples func(limit int) ([]FeedbackExample, error)
+	// outputMode selects how the model returns structured output; outputRetries is
+	// how many times an answer that does not match the schema is re-requested
+	outputMode    string
+	outputRetries int
+	// policy bounds and retries each provider call
+	policy CallPolicy
+	// breaker short-circuits to the fallback while the provider is failing; it is
+	// shared by every service talking to the same endpoint
+	breaker *CircuitBreaker
+	// redactor removes sensitive values from incident text before it is sent to the provider
+	redactor *Redactor
+	// usage records the tokens and cost of every call and enforces the monthly budget
+	usage *UsageMeter
 }
 
 type AIAnalysisResult struct {
 	Severity string `json:"severity"`
 	Category string `json:"category"`
+	// Confidence is the classifier's confidence in the result, from 0 to 1
+	Confidence float64 `json:"confidence"`
+	// Rationale is the classifier's explanation of the result
+	Rationale string `json:"rationale"`
+	// Provider and Model identify the classifier that produced the result
+	Provider      string `json:"provider"`
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	// RawResponse is the unprocessed model output
+	RawResponse string `json:"raw_response,omitempty"`
+	// Usage is the token usage reported by the provider; it is zero for cached results
+	Usage openai.Usage `json:"usage"`
+	// Cached is set when the result was served from the result cache
+	Cached bool `json:"cached"`
+	// Fallback is set when the result came from the fallback classifier, because the
+	// provider failed or its output did not match the classification schema
+	Fallback       bool   `json:"fallback"`
+	FallbackReason string `json:"fallback_reason,omitempty"`
+	// MatchedRules explains rule-based classifications
+	MatchedRules []RuleMatch `json:"matched_rules,omitempty"`
+	// Redactions counts the values removed from the text sent to the provider
+	Redactions RedactionReport `json:"redactions,omitempty"`
 }
 
 // NewAIService creates a new AI service instance
 func NewAIService() *AIService {
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	return newOpenAIService(os.Getenv("OPENAI_API_KEY"), openai.GPT3Dot5Turbo)
+}
+
+// newOpenAIService creates an AI service for the hosted OpenAI API
+func newOpenAIService(apiKey, model string) *AIService {
 	if apiKey == "" {
 		// For development, you can set a default key or handle this differently
 		fmt.Println("Warning: OPENAI_API_KEY not set")
 	}
 
-	client := openai.NewClient(apiKey)
+	config := openai.DefaultConfig(apiKey)
+	return &AIService{
+		client:   openai.NewClientWithConfig(config),
+		provider: ProviderOpenAI,
+		model:    model,
+		apiKey:   apiKey,
+		fallback: NewDefaultRuleClassifier(),
+		prompt:   defaultPrompt(ProviderOpenAI),
+
+		summaryPrompt:     builtinPrompt(DefaultSummaryPromptVersion),
+		remediationPrompt: builtinPrompt(DefaultRemediationPromptVersion),
+		postmortemPrompt:  builtinPrompt(DefaultPostmortemPromptVersion),
+		fewShot:           DefaultFewShotExamples,
+		examples:          recentFeedbackExamples,
+
+		outputMode:    defaultOutputModes[ProviderOpenAI],
+		outputRetries: DefaultOutputRetries,
+		policy:        DefaultCallPolicy(),
+		breaker:       circuitBreaker(ProviderOpenAI, config.BaseURL),
+		redactor:      defaultRedactor(),
+		usage:         usageMeter(),
+	}
+}
+
+// NewOpenAICompatibleService creates an AI service for any server implementing the
+// OpenAI chat completion API, such as Ollama or llama.cpp. The API key may be empty.
+func NewOpenAICompatibleService(baseURL, apiKey, model string) *AIService {
+	config := openai.DefaultConfig(apiKey)
+	config.BaseURL = baseURL
 	return &AIService{
-		client: client,
+		client:   openai.NewClientWithConfig(config),
+		provider: ProviderOpenAICompatible,
+		model:    model,
+		apiKey:   apiKey,
+		fallback: NewDefaultRuleClassifier(),
+		prompt:   defaultPrompt(ProviderOpenAICompatible),
+
+		summaryPrompt:     builtinPrompt(DefaultSummaryPromptVersion),
+		remediationPrompt: builtinPrompt(DefaultRemediationPromptVersion),
+		postmortemPrompt:  builtinPrompt(DefaultPostmortemPromptVersion),
+		fewShot:           DefaultFewShotExamples,
+		examples:          recentFeedbackExamples,
+
+		outputMode:    defaultOutputModes[ProviderOpenAICompatible],
+		outputRetries: DefaultOutputRetries,
+		policy:        DefaultCallPolicy(),
+		breaker:       circuitBreaker(ProviderOpenAICompatible, baseURL),
+		redactor:      defaultRedactor(),
+		usage:         usageMeter(),
 	}
 }
 
+// Name identifies the provider behind the service
+func (s *AIService) Name() string {
+	return s.provider
+}
+
+// CacheScope identifies the provider, model and prompt behind the service's results
+func (s *AIService) CacheScope() string {
+	return strings.Join([]string{s.provider, s.model, s.prompt.Version, s.outputMode}, "|")
+}
+
 // AnalyzeIncident analyzes an incident description to determine severity and category
 func (s *AIService) AnalyzeIncident(title, description string) (*AIAnalysisResult, error) {
-	// If no API key is set, return default values
-	if os.Getenv("OPENAI_API_KEY") == "" {
-		return &AIAnalysisResult{
-			Severity: "medium",
-			Category: "software",
-		}, nil
-	}
-
-	prompt := fmt.Sprintf(`
-Analyze the following incident and determine:
-1. Severity: Choose from "low", "medium", or "high"
-2. Category: Choose from "network", "software", "hardware", or "security"
-
-Consider these guidelines:
-- Severity: Based on potential impact, urgency, and scope
-- Category: Based on the type of issue described
-
-Incident Title: %s
-Incident Description: %s
-
-Respond with a JSON object in this exact format:
-{
-  "severity": "low|medium|high",
-  "category": "network|software|hardware|security"
-}
-`, title, description)
-
-	ctx := context.Background()
-	resp, err := s.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: 0.1, // Low temperature for more consistent results
+	return s.Classify(context.Background(), title, description)
+}
+
+// Classify analyzes an incident using the chat completion API. The fallback
+// classifier is used when no API key is configured (offline mode), the monthly budget
+// is spent, the provider fails or its circuit breaker is open.
+func (s *AIService) Classify(ctx context.Context, title, description string) (*AIAnalysisResult, error) {
+	taxonomy := model.CurrentTaxonomy()
+	return callModel(ctx, s, modelTask[*AIAnalysisResult]{
+		operation: OperationClassify,
+		noun:      "classification",
+		// Sensitive values in the incident and the few-shot examples are redacted
+		prompt: func(redaction *RedactionScope) (string, error) {
+			examples := s.fewShotExamples(title, description)
+			for i := range examples {
+				examples[i].Title = redaction.Redact(examples[i].Title)
+				examples[i].Description = redaction.Redact(examples[i].Description)
+			}
+			return s.prompt.Render(PromptData{
+				Title:       redaction.Redact(title),
+				Description: redaction.Redact(description),
+				Severities:  taxonomy.Severities,
+				Categories:  taxonomy.Categories,
+				Examples:    examples,
+			})
+		},
+		request: func(prompt string) openai.ChatCompletionRequest {
+			return s.chatRequest(prompt, taxonomy)
+		},
+		parse: func(message openai.ChatCompletionMessage) (*AIAnalysisResult, error) {
+			raw, err := s.responseOutput(message)
+			if err != nil {
+				return nil, err
+			}
+			result, err := parseModelOutput(raw, taxonomy)
+			if err != nil {
+				return nil, err
+			}
+			result.RawResponse = raw
+			return result, nil
+		},
+		answered: func(result *AIAnalysisResult, answer modelAnswer) {
+			result.Provider = answer.Provider
+			result.Model = answer.Model
+			result.PromptVersion = s.prompt.Version
+			result.Usage = answer.Usage
+			result.Redactions = answer.Redactions
+		},
+		fallback: func(ctx context.Context, cause error) (*AIAnalysisResult, error) {
+			return s.classifyWithFallback(ctx, title, description, cause)
 		},
-	)
+	})
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API error: %v", err)
+// recordOutcome updates the circuit breaker with the outcome of a provider operation
+func (s *AIService) recordOutcome(ctx context.Context, err error) {
+	switch {
+	case err == nil || errors.Is(err, ErrInvalidModelOutput):
+		// The provider answered, even if the answer was unusable
+		s.breaker.Success()
+	case ctx.Err() != nil:
+		s.breaker.Release()
+	default:
+		s.breaker.Failure(err)
 	}
+}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+// classifyWithFallback classifies with the fallback classifier, returning cause if there is none
+func (s *AIService) classifyWithFallback(ctx context.Context, title, description string, cause error) (*AIAnalysisResult, error) {
+	if s.fallback == nil {
+		return nil, cause
 	}
+	result, err := s.fallback.Classify(ctx, title, description)
+	if err != nil {
+		return nil, err
+	}
+	result.Fallback = true
+	result.FallbackReason = cause.Error()
+	return result, nil
+}
 
-	content := resp.Choices[0].Message.Content
-	content = strings.TrimSpace(content)
+// modelTask describes one use of the chat model, such as classifying or summarizing an incident
+type modelTask[T any] struct {
+	// operation is the operation the token usage is recorded under
+	operation string
+	// noun names the answer in log messages, e.g. "summary"
+	noun string
+	// prompt renders the prompt, redacting sensitive values with the scope
+	prompt func(redaction *RedactionScope) (string, error)
+	// request builds the chat request of the rendered prompt
+	request func(prompt string) openai.ChatCompletionRequest
+	// parse strictly decodes an answer; invalid answers are re-requested
+	parse func(message openai.ChatCompletionMessage) (T, error)
+	// answered stores where a valid answer came from on its result
+	answered func(result T, answer modelAnswer)
+	// fallback produces a result without the model, because of cause
+	fallback func(ctx context.Context, cause error) (T, error)
+}
 
-	// Try to parse the JSON response
-	var result AIAnalysisResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		// If JSON parsing fails, try to extract values using string manipulation
-		result = s.extractValuesFromText(content)
-	}
+// modelAnswer describes where a valid model answer came from
+type modelAnswer struct {
+	Provider   string
+	Model      string
+	Usage      openai.Usage
+	Redactions RedactionReport
+}
 
-	// Validate the results
-	if !s.isValidSeverity(result.Severity) {
-		result.Severity = "medium" // Default fallback
+// callModel runs a task on the chat model. The task's fallback is used when no API key is
+// configured (offline mode), the monthly budget is spent, the provider fails or its circuit
+// breaker is open.
+func callModel[T any](ctx context.Context, s *AIService, task modelTask[T]) (T, error) {
+	// The hosted OpenAI API cannot be called without a key
+	if s.provider == ProviderOpenAI && s.apiKey == "" {
+		return task.fallback(ctx, errors.New("OPENAI_API_KEY not set"))
 	}
-	if !s.isValidCategory(result.Category) {
-		result.Category = "software" // Default fallback
+	if err := s.usage.Allow(); err != nil {
+		return task.fallback(ctx, err)
+	}
+	if err := s.breaker.Allow(); err != nil {
+		return task.fallback(ctx, fmt.Errorf("%s: %w", s.provider, err))
 	}
 
-	return &result, nil
+	result, err := askModel(ctx, s, task)
+	s.recordOutcome(ctx, err)
+	if err != nil {
+		log.Printf("%s %s failed, using fallback: %v", s.provider, task.noun, err)
+		return task.fallback(ctx, err)
+	}
+	return result, nil
 }
 
-// extractValuesFromText extracts severity and category from text if JSON parsing fails
-func (s *AIService) extractValuesFromText(text string) AIAnalysisResult {
-	result := AIAnalysisResult{
-		Severity: "medium",
-		Category: "software",
+// askModel renders the task's prompt and asks the chat model. Invalid answers are
+// re-requested rather than guessed at; the usage of every attempt is added up.
+func askModel[T any](ctx context.Context, s *AIService, task modelTask[T]) (T, error) {
+	var zero T
+	redaction := s.redactor.Scope()
+	prompt, err := task.prompt(redaction)
+	if err != nil {
+		return zero, err
 	}
+	request := task.request(prompt)
 
-	text = strings.ToLower(text)
-
-	// Extract severity
-	if strings.Contains(text, `"severity"`) || strings.Contains(text, "severity") {
-		if strings.Contains(text, `"low"`) || strings.Contains(text, "low") {
-			result.Severity = "low"
-		} else if strings.Contains(text, `"high"`) || strings.Contains(text, "high") {
-			result.Severity = "high"
+	var usage openai.Usage
+	var lastErr error
+	for attempt := 0; attempt <= s.outputRetries; attempt++ {
+		resp, err := s.createChatCompletion(ctx, task.operation, request)
+		if err != nil {
+			return zero, fmt.Errorf("%s API error: %v", s.provider, err)
 		}
-	}
+		usage.PromptTokens += resp.Usage.PromptTokens
+		usage.CompletionTokens += resp.Usage.CompletionTokens
+		usage.TotalTokens += resp.Usage.TotalTokens
 
-	// Extract category
-	if strings.Contains(text, `"category"`) || strings.Contains(text, "category") {
-		if strings.Contains(text, `"network"`) || strings.Contains(text, "network") {
-			result.Category = "network"
-		} else if strings.Contains(text, `"hardware"`) || strings.Contains(text, "hardware") {
-			result.Category = "hardware"
-		} else if strings.Contains(text, `"security"`) || strings.Contains(text, "security") {
-			result.Category = "security"
+		if len(resp.Choices) == 0 {
+			return zero, fmt.Errorf("no response from %s", s.provider)
 		}
-	} else {
-		// If no category keyword found, try to detect from content
-		if strings.Contains(text, "hardware") {
-			result.Category = "hardware"
-		} else if strings.Contains(text, "network") {
-			result.Category = "network"
-		} else if strings.Contains(text, "security") {
-			result.Category = "security"
+
+		result, err := task.parse(resp.Choices[0].Message)
+		if err != nil {
+			lastErr = err
+			log.Printf("%s returned an invalid %s (attempt %d of %d): %v", s.provider, task.noun, attempt+1, s.outputRetries+1, err)
+			continue
 		}
+
+		task.answered(result, modelAnswer{
+			Provider:   s.provider,
+			Model:      firstNonEmpty(resp.Model, s.model),
+			Usage:      usage,
+			Redactions: redaction.Report(),
+		})
+		return result, nil
 	}
+	return zero, lastErr
+}
 
-	return result
+// jsonRequest builds a chat request for a prompt whose answer is a JSON object
+func (s *AIService) jsonRequest(prompt string, temperature float32) openai.ChatCompletionRequest {
+	request := openai.ChatCompletionRequest{
+		Model: s.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		},
+		Temperature: temperature,
+	}
+	if s.outputMode != OutputModeText {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+	return request
 }
 
-// isValidSeverity checks if the severity is valid
-func (s *AIService) isValidSeverity(severity string) bool {
-	validSeverities := []string{"low", "medium", "high"}
-	for _, valid := range validSeverities {
-		if strings.ToLower(severity) == valid {
-			return true
+// createChatCompletion calls the provider with a deadline per call, retrying
+// retryable failures with exponential backoff until the caller's context is done.
+// The token usage of every response is recorded under the given operation.
+func (s *AIService) createChatCompletion(ctx context.Context, operation string, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	var lastErr error
+	for attempt := 0; attempt <= s.policy.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, s.policy.backoff(attempt)); err != nil {
+				break
+			}
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, s.policy.Timeout)
+		resp, err := s.client.CreateChatCompletion(callCtx, request)
+		cancel()
+		if err == nil {
+			s.usage.Record(s.provider, firstNonEmpty(resp.Model, s.model), operation, resp.Usage)
+			return resp, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !retryableError(err) {
+			break
 		}
+		log.Printf("%s call failed (attempt %d of %d): %v", s.provider, attempt+1, s.policy.MaxRetries+1, err)
 	}
-	return false
+	return openai.ChatCompletionResponse{}, lastErr
 }
 
-// isValidCategory checks if the category is valid
-func (s *AIService) isValidCategory(category string) bool {
-	validCategories := []string{"network", "software", "hardware", "security"}
-	for _, valid := range validCategories {
-		if strings.ToLower(category) == valid {
-			return true
+// fewShotExamples returns recent human-corrected incidents for the prompt, leaving out
+// the incident being classified. Failures only cost the examples, so they are logged.
+func (s *AIService) fewShotExamples(title, description string) []FeedbackExample {
+	if s.fewShot <= 0 || s.examples == nil {
+		return nil
+	}
+	examples, err := s.examples(s.fewShot + 1)
+	if err != nil {
+		log.Printf("Failed to load few-shot examples: %v", err)
+		return nil
+	}
+
+	selected := make([]FeedbackExample, 0, s.fewShot)
+	for _, example := range examples {
+		if example.Title == title && example.Description == description {
+			continue
+		}
+		if len(selected) == s.fewShot {
+			break
 		}
+		selected = append(selected, example)
+	}
+	return selected
+}
+
+// quoteChoices formats values as a prompt choice list, e.g. "low", "medium", or "high"
+func quoteChoices(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	if len(quoted) <= 2 {
+		return strings.Join(quoted, " or ")
 	}
-	return false
+	return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
 }
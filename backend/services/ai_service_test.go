@@ -1,10 +1,40 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
 
+// newFakeChatServer starts an OpenAI-compatible server that answers every chat completion with content
+func newFakeChatServer(t *testing.T, content string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     "chatcmpl-test",
+			"object": "chat.completion",
+			"model":  "test-model",
+			"choices": []map[string]interface{}{
+				{
+					"index":         0,
+					"finish_reason": "stop",
+					"message":       map[string]string{"role": "assistant", "content": content},
+				},
+			},
+			"usage": map[string]int{"prompt_tokens": 120, "completion_tokens": 30, "total_tokens": 150},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
 func TestNewAIService(t *testing.T) {
 	// Test with no API key
 	aiService := NewAIService()
@@ -42,112 +72,58 @@ func TestAnalyzeIncident_NoAPIKey(t *testing.T) {
 	if result.Category != "software" {
 		t.Errorf("Expected category 'software', got '%s'", result.Category)
 	}
+
+	// Offline mode is served by the rule-based classifier
+	if result.Provider != ProviderRules || !result.Fallback {
+		t.Errorf("Expected offline result from rules fallback, got provider '%s' fallback %v", result.Provider, result.Fallback)
+	}
 }
 
-func TestExtractValuesFromText(t *testing.T) {
-	aiService := NewAIService()
+func TestClassify_FallbackOnProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"message": "model overloaded"}}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
 
-	tests := []struct {
-		name             string
-		text             string
-		expectedSeverity string
-		expectedCategory string
-	}{
-		{
-			name:             "Valid JSON response",
-			text:             `{"severity": "high", "category": "security"}`,
-			expectedSeverity: "high",
-			expectedCategory: "security",
-		},
-		{
-			name:             "Text with severity and category",
-			text:             "The severity is low and the category is network",
-			expectedSeverity: "low",
-			expectedCategory: "network",
-		},
-		{
-			name:             "Text with high severity and hardware category",
-			text:             "This is a high severity hardware issue",
-			expectedSeverity: "high",
-			expectedCategory: "hardware",
-		},
-		{
-			name:             "Invalid text - should use defaults",
-			text:             "Random text without severity or category",
-			expectedSeverity: "medium",
-			expectedCategory: "software",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := aiService.extractValuesFromText(tt.text)
-
-			if result.Severity != tt.expectedSeverity {
-				t.Errorf("Expected severity '%s', got '%s'", tt.expectedSeverity, result.Severity)
-			}
-
-			if result.Category != tt.expectedCategory {
-				t.Errorf("Expected category '%s', got '%s'", tt.expectedCategory, result.Category)
-			}
-		})
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+
+	result, err := aiService.Classify(context.Background(), "Ransomware detected", "Files encrypted on the finance share")
+	if err != nil {
+		t.Fatalf("Expected fallback result, got error %v", err)
+	}
+	if !result.Fallback {
+		t.Error("Expected result to be marked as fallback")
+	}
+	if result.Category != "security" {
+		t.Errorf("Expected rules to classify as 'security', got '%s'", result.Category)
+	}
+	if len(result.MatchedRules) == 0 {
+		t.Error("Expected fallback result to explain fired rules")
 	}
 }
 
-func TestIsValidSeverity(t *testing.T) {
-	aiService := NewAIService()
+func TestClassify_OpenAICompatible(t *testing.T) {
+	server := newFakeChatServer(t, `{"severity": "high", "category": "network"}`)
 
-	tests := []struct {
-		severity string
-		expected bool
-	}{
-		{"low", true},
-		{"medium", true},
-		{"high", true},
-		{"LOW", true},
-		{"Medium", true},
-		{"HIGH", true},
-		{"critical", false},
-		{"", false},
-		{"invalid", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.severity, func(t *testing.T) {
-			result := aiService.isValidSeverity(tt.severity)
-			if result != tt.expected {
-				t.Errorf("Expected %v for severity '%s', got %v", tt.expected, tt.severity, result)
-			}
-		})
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	if aiService.Name() != ProviderOpenAICompatible {
+		t.Errorf("Expected provider '%s', got '%s'", ProviderOpenAICompatible, aiService.Name())
 	}
-}
 
-func TestIsValidCategory(t *testing.T) {
-	aiService := NewAIService()
+	result, err := aiService.Classify(context.Background(), "Router down", "Core router is not forwarding packets")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
 
-	tests := []struct {
-		category string
-		expected bool
-	}{
-		{"network", true},
-		{"software", true},
-		{"hardware", true},
-		{"security", true},
-		{"NETWORK", true},
-		{"Software", true},
-		{"Hardware", true},
-		{"Security", true},
-		{"database", false},
-		{"", false},
-		{"invalid", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.category, func(t *testing.T) {
-			result := aiService.isValidCategory(tt.category)
-			if result != tt.expected {
-				t.Errorf("Expected %v for category '%s', got %v", tt.expected, tt.category, result)
-			}
-		})
+	if result.Severity != "high" {
+		t.Errorf("Expected severity 'high', got '%s'", result.Severity)
+	}
+
+	if result.Category != "network" {
+		t.Errorf("Expected category 'network', got '%s'", result.Category)
+	}
+
+	if result.Fallback {
+		t.Error("Expected model result, got fallback")
 	}
 }
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"incident-management/model"
+	"incident-management/repository"
+	"incident-management/utils"
+)
+
+// ErrNotClassified is returned when an incident has no classification record yet
+var ErrNotClassified = errors.New("incident has not been classified yet")
+
+// ClassificationService runs the configured classifier against stored incidents
+type ClassificationService struct {
+	incidents       *repository.IncidentRepository
+	classifications *repository.ClassificationRepository
+	overrides       *repository.OverrideRepository
+	ai              Classifier
+}
+
+// overrideValues validates the human values of an override against the incident's AI fields
+type overrideValues struct {
+	Severity string `validate:"omitempty,taxonomy=severity"`
+	Category string `validate:"omitempty,taxonomy=category"`
+}
+
+// FeedbackExample is one labeled incident of the human feedback dataset.
+// Severity and category are the human-confirmed labels; the AI fields hold what the classifier said.
+type FeedbackExample struct {
+	IncidentID  string `json:"incident_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Category    string `json:"category"`
+	AISeverity  string `json:"ai_severity"`
+	AICategory  string `json:"ai_category"`
+}
+
+// NewClassificationService creates a new classification service
+func NewClassificationService(ai Classifier) *ClassificationService {
+	return &ClassificationService{
+		incidents:       repository.NewIncidentRepository(),
+		classifications: repository.NewClassificationRepository(),
+		overrides:       repository.NewOverrideRepository(),
+		ai:              ai,
+	}
+}
+
+// ClassifyIncident classifies a stored incident, records an audit entry and saves
+// the resulting severity and category on the incident
+func (s *ClassificationService) ClassifyIncident(ctx context.Context, incidentID string) (*model.Incident, *model.Classification, error) {
+	incident, err := s.incidents.GetByID(incidentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.ai.Classify(ctx, incident.Title, incident.Description)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	classification := newClassificationRecord(incident.ID, result)
+	if err := s.classifications.Record(classification, classificationChanges(result)); err != nil {
+		return nil, nil, err
+	}
+
+	incident.AISeverity = result.Severity
+	incident.AICategory = result.Category
+	incident.ClassificationStatus = model.ClassificationCompleted
+	return incident, classification, nil
+}
+
+// GetLatestClassification retrieves the most recent classification record of an incident
+func (s *ClassificationService) GetLatestClassification(incidentID string) (*model.Classification, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+
+	classification, err := s.classifications.GetLatest(incidentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotClassified
+	}
+	return classification, err
+}
+
+// OverrideClassification stores a human-chosen severity and/or category next to the AI values.
+// An empty value keeps the incident's current override for that field.
+func (s *ClassificationService) OverrideClassification(incidentID, severity, category, reason, actor string) (*model.Incident, *model.ClassificationOverride, error) {
+	if severity == "" && category == "" {
+		return nil, nil, &ValidationError{Details: map[string]string{"severity": "either severity or category is required"}}
+	}
+	values := overrideValues{Severity: severity, Category: category}
+	if validationErrors := utils.ValidateAndGetErrors(&values); validationErrors != nil {
+		return nil, nil, &ValidationError{Details: validationErrors}
+	}
+
+	incident, err := s.incidents.GetByID(incidentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	before := *incident
+
+	if severity != "" {
+		incident.HumanSeverity = severity
+	}
+	if category != "" {
+		incident.HumanCategory = category
+	}
+
+	override := &model.ClassificationOverride{
+		IncidentID:    incident.ID,
+		AISeverity:    incident.AISeverity,
+		AICategory:    incident.AICategory,
+		HumanSeverity: incident.HumanSeverity,
+		HumanCategory: incident.HumanCategory,
+		Reason:        reason,
+		Actor:         actor,
+	}
+	if err := s.overrides.Apply(override, diffIncident(&before, incident, actor, model.ChangeSourceAPI)); err != nil {
+		return nil, nil, err
+	}
+
+	return incident, override, nil
+}
+
+// GetOverrides retrieves the override history of an incident
+func (s *ClassificationService) GetOverrides(incidentID string) ([]model.ClassificationOverride, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+	return s.overrides.ListByIncident(incidentID)
+}
+
+// FeedbackExamples builds the labeled dataset from every incident a human has corrected.
+// A field without an override is labeled with the AI value, which the responder left unchanged.
+func (s *ClassificationService) FeedbackExamples() ([]FeedbackExample, error) {
+	incidents, err := s.overrides.ListOverriddenIncidents()
+	if err != nil {
+		return nil, err
+	}
+
+	examples := make([]FeedbackExample, 0, len(incidents))
+	for i := range incidents {
+		examples = append(examples, newFeedbackExample(&incidents[i]))
+	}
+	return examples, nil
+}
+
+// newFeedbackExample labels an incident with its human-confirmed severity and category
+func newFeedbackExample(incident *model.Incident) FeedbackExample {
+	return FeedbackExample{
+		IncidentID:  incident.ID,
+		Title:       incident.Title,
+		Description: incident.Description,
+		Severity:    incident.EffectiveSeverity(),
+		Category:    incident.EffectiveCategory(),
+		AISeverity:  incident.AISeverity,
+		AICategory:  incident.AICategory,
+	}
+}
+
+// MarkFailed records that an incident could not be classified; its AI fields keep their defaults
+func (s *ClassificationService) MarkFailed(incidentID string) error {
+	return s.incidents.SetClassificationStatus(incidentID, model.ClassificationFailed)
+}
+
+// classificationChanges returns the diff of the changes storing a classification result makes
+// to an incident, with the classifier as the actor. It is applied to the incident as read in
+// the storing transaction, since the incident may have been edited while the model answered.
+func classificationChanges(result *AIAnalysisResult) func(before, after *model.Incident) []model.IncidentChange {
+	return func(before, after *model.Incident) []model.IncidentChange {
+		return diffIncident(before, after, result.Provider, model.ChangeSourceAI)
+	}
+}
+
+// newClassificationRecord converts a classifier result into an audit record
+func newClassificationRecord(incidentID string, result *AIAnalysisResult) *model.Classification {
+	classification := &model.Classification{
+		IncidentID:       incidentID,
+		Provider:         result.Provider,
+		Model:            result.Model,
+		PromptVersion:    result.PromptVersion,
+		Severity:         result.Severity,
+		Category:         result.Category,
+		Confidence:       result.Confidence,
+		Rationale:        result.Rationale,
+		RawResponse:      result.RawResponse,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		Fallback:         result.Fallback,
+		Cached:           result.Cached,
+		FallbackReason:   result.FallbackReason,
+	}
+	if len(result.MatchedRules) > 0 {
+		classification.MatchedRules, _ = json.Marshal(result.MatchedRules)
+	}
+	if len(result.Redactions) > 0 {
+		classification.Redactions, _ = json.Marshal(result.Redactions)
+	}
+	return classification
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// DefaultCacheSize is the number of results kept in the in-memory cache tier
+	DefaultCacheSize = 1000
+	// DefaultCacheTTL is how long a cached result is reused
+	DefaultCacheTTL = 24 * time.Hour
+)
+
+// CacheConfig controls the classification result cache
+type CacheConfig struct {
+	// Size is the capacity of the in-memory LRU tier; zero disables the cache
+	Size int
+	// TTL is how long a cached result is reused
+	TTL time.Duration
+	// Persistent adds a SQLite-backed tier that survives restarts
+	Persistent bool
+}
+
+// CacheStats reports the size and effectiveness of the result cache
+type CacheStats struct {
+	Enabled    bool   `json:"enabled"`
+	Persistent bool   `json:"persistent"`
+	Size       int    `json:"size"`
+	Capacity   int    `json:"capacity"`
+	TTL        string `json:"ttl,omitempty"`
+	// Hits counts results served from memory, PersistentHits those served from the database
+	Hits           int64   `json:"hits"`
+	PersistentHits int64   `json:"persistent_hits"`
+	Misses         int64   `json:"misses"`
+	Evictions      int64   `json:"evictions"`
+	HitRate        float64 `json:"hit_rate"`
+}
+
+// ResultCache is an LRU cache of classifier results with an optional persistent tier
+type ResultCache struct {
+	config CacheConfig
+	// store is the persistent tier; nil unless enabled
+	store *repository.ClassificationCacheRepository
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds the entries from most to least recently used
+	order          *list.List
+	hits           int64
+	persistentHits int64
+	misses         int64
+	evictions      int64
+}
+
+// cacheItem is an entry of the in-memory tier
+type cacheItem struct {
+	key       string
+	result    AIAnalysisResult
+	expiresAt time.Time
+}
+
+// NewResultCache creates a result cache. Expired entries of the persistent tier are purged.
+func NewResultCache(config CacheConfig) *ResultCache {
+	if config.TTL <= 0 {
+		config.TTL = DefaultCacheTTL
+	}
+	cache := &ResultCache{
+		config:  config,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+	if config.Persistent {
+		if database.GetDB() == nil {
+			log.Println("Persistent classification cache requested without a database, using memory only")
+			cache.config.Persistent = false
+		} else {
+			cache.store = repository.NewClassificationCacheRepository()
+			if purged, err := cache.store.DeleteExpired(cache.now()); err != nil {
+				log.Println("Failed to purge expired classification cache entries:", err)
+			} else if purged > 0 {
+				log.Printf("Purged %d expired classification cache entries", purged)
+			}
+		}
+	}
+	return cache
+}
+
+// Get returns a copy of the cached result for key, checking memory and then the persistent tier
+func (c *ResultCache) Get(key string) (*AIAnalysisResult, bool) {
+	now := c.now()
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		item := element.Value.(*cacheItem)
+		if now.Before(item.expiresAt) {
+			c.order.MoveToFront(element)
+			c.hits++
+			result := item.result
+			c.mu.Unlock()
+			return &result, true
+		}
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		entry, err := c.store.Get(key, now)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Println("Failed to read classification cache:", err)
+		}
+		if err == nil {
+			var result AIAnalysisResult
+			if err := json.Unmarshal([]byte(entry.Result), &result); err == nil {
+				c.mu.Lock()
+				c.persistentHits++
+				c.add(key, result, entry.ExpiresAt)
+				c.mu.Unlock()
+				return &result, true
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// Put caches a result for the configured TTL
+func (c *ResultCache) Put(key string, result *AIAnalysisResult) {
+	expiresAt := c.now().Add(c.config.TTL)
+	c.mu.Lock()
+	c.add(key, *result, expiresAt)
+	c.mu.Unlock()
+
+	if c.store != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return
+		}
+		err = c.store.Put(&model.ClassificationCacheEntry{
+			Key:           key,
+			Provider:      result.Provider,
+			Model:         result.Model,
+			PromptVersion: result.PromptVersion,
+			Result:        string(data),
+			ExpiresAt:     expiresAt,
+		})
+		if err != nil {
+			log.Println("Failed to write classification cache:", err)
+		}
+	}
+}
+
+// add stores an entry in memory, evicting the least recently used one when full.
+// The caller must hold c.mu.
+func (c *ResultCache) add(key string, result AIAnalysisResult, expiresAt time.Time) {
+	if element, ok := c.entries[key]; ok {
+		element.Value = &cacheItem{key: key, result: result, expiresAt: expiresAt}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheItem{key: key, result: result, expiresAt: expiresAt})
+	for c.order.Len() > c.config.Size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheItem).key)
+		c.evictions++
+	}
+}
+
+// Stats returns the cache's size and hit/miss counters
+func (c *ResultCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := CacheStats{
+		Enabled:        true,
+		Persistent:     c.store != nil,
+		Size:           c.order.Len(),
+		Capacity:       c.config.Size,
+		TTL:            c.config.TTL.String(),
+		Hits:           c.hits,
+		PersistentHits: c.persistentHits,
+		Misses:         c.misses,
+		Evictions:      c.evictions,
+	}
+	if lookups := c.hits + c.persistentHits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.hits+c.persistentHits) / float64(lookups)
+	}
+	return stats
+}
+
+var (
+	sharedCacheMu sync.Mutex
+	// sharedCaches are the process-wide result caches by configuration, so results are
+	// shared between the request handlers and the background workers
+	sharedCaches = make(map[CacheConfig]*ResultCache)
+	// sharedCache is the most recently requested shared cache, the one stats are reported for
+	sharedCache *ResultCache
+)
+
+// sharedResultCache returns the process-wide result cache of a configuration, creating it on first use
+func sharedResultCache(config CacheConfig) *ResultCache {
+	sharedCacheMu.Lock()
+	defer sharedCacheMu.Unlock()
+	cache, ok := sharedCaches[config]
+	if !ok {
+		cache = NewResultCache(config)
+		sharedCaches[config] = cache
+	}
+	sharedCache = cache
+	return cache
+}
+
+// ClassificationCacheStats returns the stats of the most recently configured process-wide result cache
+func ClassificationCacheStats() CacheStats {
+	sharedCacheMu.Lock()
+	cache := sharedCache
+	sharedCacheMu.Unlock()
+	if cache == nil {
+		return CacheStats{}
+	}
+	return cache.Stats()
+}
+
+// CachingClassifier serves repeated incidents from a result cache. Only clean model
+// answers are cached; fallback results are not, so an outage is never remembered.
+type CachingClassifier struct {
+	classifier Classifier
+	cache      *ResultCache
+}
+
+// NewCachingClassifier wraps a classifier with a result cache
+func NewCachingClassifier(classifier Classifier, cache *ResultCache) *CachingClassifier {
+	return &CachingClassifier{classifier: classifier, cache: cache}
+}
+
+// Name identifies the provider behind the wrapped classifier
+func (c *CachingClassifier) Name() string {
+	return c.classifier.Name()
+}
+
+// Classify returns the cached result of an equivalent incident, or classifies it and caches the result
+func (c *CachingClassifier) Classify(ctx context.Context, title, description string) (*AIAnalysisResult, error) {
+	key := resultCacheKey(c.classifier, title, description)
+	if result, ok := c.cache.Get(key); ok {
+		result.Cached = true
+		// No tokens were spent on this result, and the raw output and redaction counts belong
+		// to the incident that was sent to the provider, not to this one
+		result.Usage = openai.Usage{}
+		result.RawResponse = ""
+		result.Redactions = nil
+		return result, nil
+	}
+
+	result, err := c.classifier.Classify(ctx, title, description)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Fallback {
+		c.cache.Put(key, result)
+	}
+	return result, nil
+}
+
+// cacheScoped is implemented by classifiers whose results depend on their configuration,
+// such as the model and prompt version, so that a configuration change misses the cache
+type cacheScoped interface {
+	CacheScope() string
+}
+
+var (
+	uuidPattern      = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	timestampPattern = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:[t ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:z|[+-]\d{2}:?\d{2})?)?\b|\b\d{1,2}:\d{2}(?::\d{2}(?:\.\d+)?)?\b`)
+	ipPattern        = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	hexIDPattern     = regexp.MustCompile(`\b[0-9a-f]{8,}\b`)
+	// instancePattern matches numbered names such as db-12 or worker_3
+	instancePattern = regexp.MustCompile(`\b([a-z][a-z0-9]*[-_])\d+\b`)
+)
+
+// normalizeIncidentText reduces text to what matters for classification: case, whitespace,
+// identifiers, timestamps and addresses are normalized so alert storms map to the same key.
+// Other numbers, such as status codes and thresholds, are kept.
+func normalizeIncidentText(text string) string {
+	text = strings.ToLower(text)
+	text = uuidPattern.ReplaceAllString(text, "<id>")
+	text = timestampPattern.ReplaceAllString(text, "<time>")
+	text = ipPattern.ReplaceAllString(text, "<ip>")
+	text = hexIDPattern.ReplaceAllString(text, "<id>")
+	text = instancePattern.ReplaceAllString(text, "${1}#")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// resultCacheKey hashes the normalized incident text with the classifier's scope and the taxonomy in effect
+func resultCacheKey(classifier Classifier, title, description string) string {
+	scope := classifier.Name()
+	if scoped, ok := classifier.(cacheScoped); ok {
+		scope = scoped.CacheScope()
+	}
+	taxonomy := model.CurrentTaxonomy()
+
+	hash := sha256.New()
+	for _, part := range []string{
+		scope,
+		strings.Join(taxonomy.Values(model.TaxonomySeverity), ","),
+		strings.Join(taxonomy.Values(model.TaxonomyCategory), ","),
+		normalizeIncidentText(title),
+		normalizeIncidentText(description),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"incident-management/database"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNormalizeIncidentText(t *testing.T) {
+	cases := []struct {
+		a, b string
+	}{
+		{"Disk  FULL on db-12", "disk full on db-7"},
+		{"Job 3f2a9c1e-8b7d-4e6f-a5b4-c3d2e1f0a9b8 failed", "Job 0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d failed"},
+		{"Pod crashloop in deploy 7f9c2e41ab", "pod crashloop in deploy 1c5e8a0d44"},
+		{"Backup failed at 2024-03-01T09:00:12Z", "backup failed at 2024-03-02 23:15"},
+		{"Cron missed its 09:00 run", "cron missed its 17:30:05 run"},
+		{"Node 10.0.3.17 unreachable", "node 192.168.1.4:9100 unreachable"},
+	}
+	for _, tc := range cases {
+		if normalizeIncidentText(tc.a) != normalizeIncidentText(tc.b) {
+			t.Errorf("Expected %q and %q to normalize alike, got %q and %q",
+				tc.a, tc.b, normalizeIncidentText(tc.a), normalizeIncidentText(tc.b))
+		}
+	}
+	// Numbers that are not identifiers change what the incident is about
+	for _, tc := range []struct {
+		a, b string
+	}{
+		{"Disk full", "Disk slow"},
+		{"Volume at 100%", "Volume at 60%"},
+		{"Checkout returns HTTP 500", "Checkout returns HTTP 404"},
+		{"Latency above 50ms", "Latency above 5000ms"},
+	} {
+		if normalizeIncidentText(tc.a) == normalizeIncidentText(tc.b) {
+			t.Errorf("Expected %q and %q to normalize differently", tc.a, tc.b)
+		}
+	}
+}
+
+func TestCachingClassifier_ServesRepeatedIncidents(t *testing.T) {
+	var calls int32
+	chat := newFakeChatServer(t, `{"severity": "high", "category": "hardware"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		chat.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	service := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	cache := NewResultCache(CacheConfig{Size: 10, TTL: time.Hour})
+	classifier := NewCachingClassifier(service, cache)
+	ctx := context.Background()
+
+	first, err := classifier.Classify(ctx, "Disk full on db-1", "Volume /data at 100% on 10.0.0.1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := classifier.Classify(ctx, "disk full on DB-2", "Volume /data at  100% on 10.0.0.2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 provider call, got %d", got)
+	}
+	if first.Cached || !second.Cached {
+		t.Errorf("Expected only the second result to be cached, got %v and %v", first.Cached, second.Cached)
+	}
+	if second.Severity != "high" || second.Category != "hardware" || second.PromptVersion != first.PromptVersion {
+		t.Errorf("Expected the cached result to match the first, got %+v", second)
+	}
+	if second.Usage.TotalTokens != 0 || first.Usage.TotalTokens == 0 {
+		t.Errorf("Expected tokens to be counted only for the provider call, got %d and %d",
+			first.Usage.TotalTokens, second.Usage.TotalTokens)
+	}
+	// The raw output and redactions describe the first incident's provider call only
+	if first.RawResponse == "" || len(first.Redactions) == 0 {
+		t.Errorf("Expected the provider call to report its output and redactions, got %+v", first)
+	}
+	if second.RawResponse != "" || len(second.Redactions) != 0 {
+		t.Errorf("Expected the cached result to carry no raw output or redactions, got %q and %v",
+			second.RawResponse, second.Redactions)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 || stats.HitRate != 0.5 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+
+	// A different prompt version is a different cache scope
+	service.prompt = defaultPrompt(ProviderOpenAI)
+	if _, err := classifier.Classify(ctx, "Disk full on db-3", "Volume /data at 99%"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected a prompt change to miss the cache, got %d provider calls", got)
+	}
+}
+
+func TestCachingClassifier_SkipsFallbackResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"message": "invalid model"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cache := NewResultCache(CacheConfig{Size: 10, TTL: time.Hour})
+	classifier := NewCachingClassifier(NewOpenAICompatibleService(server.URL+"/v1", "", "test-model"), cache)
+
+	for i := 0; i < 2; i++ {
+		result, err := classifier.Classify(context.Background(), "Router down", "Core router is not forwarding packets")
+		if err != nil {
+			t.Fatalf("Expected fallback result, got error %v", err)
+		}
+		if !result.Fallback || result.Cached {
+			t.Errorf("Expected an uncached fallback result, got fallback %v cached %v", result.Fallback, result.Cached)
+		}
+	}
+	if stats := cache.Stats(); stats.Size != 0 || stats.Hits != 0 {
+		t.Errorf("Expected fallback results not to be cached, got %+v", stats)
+	}
+}
+
+func TestResultCache_EvictionAndExpiry(t *testing.T) {
+	cache := NewResultCache(CacheConfig{Size: 2, TTL: time.Minute})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.Put("a", &AIAnalysisResult{Severity: "low"})
+	cache.Put("b", &AIAnalysisResult{Severity: "medium"})
+	cache.Get("a") // a is now the most recently used
+	cache.Put("c", &AIAnalysisResult{Severity: "high"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if result, ok := cache.Get("a"); !ok || result.Severity != "low" {
+		t.Errorf("Expected 'a' to survive eviction, got %v %v", result, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get("c"); ok {
+		t.Error("Expected entry to expire after the TTL")
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Size != 1 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+}
+
+func TestResultCache_PersistentTier(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	key := resultCacheKey(NewDefaultRuleClassifier(), t.Name(), "persistent tier")
+	writer := NewResultCache(CacheConfig{Size: 10, TTL: time.Hour, Persistent: true})
+	writer.Put(key, &AIAnalysisResult{Severity: "high", Category: "security", Provider: ProviderOpenAI, PromptVersion: "classify-v3"})
+
+	// A new cache, as after a restart, finds the result in the database
+	reader := NewResultCache(CacheConfig{Size: 10, TTL: time.Hour, Persistent: true})
+	result, ok := reader.Get(key)
+	if !ok {
+		t.Fatal("Expected a hit from the persistent tier")
+	}
+	if result.Severity != "high" || result.Category != "security" || result.PromptVersion != "classify-v3" {
+		t.Errorf("Unexpected cached result: %+v", result)
+	}
+	if _, ok := reader.Get(key); !ok {
+		t.Fatal("Expected a second hit from memory")
+	}
+	if stats := reader.Stats(); stats.PersistentHits != 1 || stats.Hits != 1 || !stats.Persistent {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+
+	// Expired entries are not served
+	reader.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, ok := reader.Get(key); ok {
+		t.Error("Expected expired entry to miss")
+	}
+}
+
+func TestSharedResultCache_KeyedByConfig(t *testing.T) {
+	small := sharedResultCache(CacheConfig{Size: 3, TTL: time.Minute})
+	if again := sharedResultCache(CacheConfig{Size: 3, TTL: time.Minute}); again != small {
+		t.Error("Expected the same config to share one cache")
+	}
+
+	large := sharedResultCache(CacheConfig{Size: 7, TTL: time.Hour})
+	if large == small {
+		t.Fatal("Expected a different config to get its own cache")
+	}
+	if stats := ClassificationCacheStats(); stats.Capacity != 7 || stats.TTL != time.Hour.String() {
+		t.Errorf("Expected the stats of the latest config, got %+v", stats)
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"incident-management/database"
+	"incident-management/model"
+	"testing"
+)
+
+func TestClassifyIncident_RecordsClassification(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	server := newFakeChatServer(t, `{"severity": "high", "category": "network", "confidence": 0.82, "rationale": "Packet loss between regions"}`)
+	service := NewClassificationService(NewOpenAICompatibleService(server.URL+"/v1", "", "test-model"))
+
+	incident, err := NewIncidentService().CreateIncident(model.Incident{
+		Title:       "Classification record incident",
+		Description: "Packet loss between regions",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	if _, err := service.GetLatestClassification(incident.ID); err != ErrNotClassified {
+		t.Fatalf("Expected ErrNotClassified before classification, got %v", err)
+	}
+
+	classified, record, err := service.ClassifyIncident(context.Background(), incident.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if classified.AISeverity != "high" || classified.AICategory != "network" {
+		t.Errorf("Expected high/network, got %s/%s", classified.AISeverity, classified.AICategory)
+	}
+
+	latest, err := service.GetLatestClassification(incident.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if latest.ID != record.ID {
+		t.Errorf("Expected latest record %s, got %s", record.ID, latest.ID)
+	}
+	if latest.Provider != ProviderOpenAICompatible || latest.Model != "test-model" {
+		t.Errorf("Unexpected provider/model: %s/%s", latest.Provider, latest.Model)
+	}
+	if latest.PromptVersion != "classify-compact-v1" {
+		t.Errorf("Expected prompt version classify-compact-v1, got %s", latest.PromptVersion)
+	}
+	if latest.Confidence != 0.82 || latest.Rationale != "Packet loss between regions" {
+		t.Errorf("Unexpected confidence/rationale: %v/%s", latest.Confidence, latest.Rationale)
+	}
+	if latest.RawResponse == "" {
+		t.Error("Expected raw response to be stored")
+	}
+	if latest.PromptTokens != 120 || latest.CompletionTokens != 30 || latest.TotalTokens != 150 {
+		t.Errorf("Unexpected token usage: %d/%d/%d", latest.PromptTokens, latest.CompletionTokens, latest.TotalTokens)
+	}
+	if latest.Fallback {
+		t.Errorf("Expected no fallback, got reason %q", latest.FallbackReason)
+	}
+}
+
+func TestOverrideClassification(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	service := NewClassificationService(NewDefaultRuleClassifier())
+	incident, err := NewIncidentService().CreateIncident(model.Incident{
+		Title:       "Override feedback incident",
+		Description: "Certificate expired on the login page",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	// At least one value is required, and values must be valid
+	if _, _, err := service.OverrideClassification(incident.ID, "", "", "no values", "tester"); err == nil {
+		t.Error("Expected an error without severity or category")
+	}
+	if _, _, err := service.OverrideClassification(incident.ID, "urgent", "", "bad value", "tester"); err == nil {
+		t.Error("Expected an error for an invalid severity")
+	}
+
+	updated, override, err := service.OverrideClassification(incident.ID, "high", "security", "Expired certificate blocks logins", "tester")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.HumanSeverity != "high" || updated.HumanCategory != "security" {
+		t.Errorf("Expected human values high/security, got %s/%s", updated.HumanSeverity, updated.HumanCategory)
+	}
+	if updated.AISeverity != "medium" || updated.AICategory != "software" {
+		t.Errorf("Expected AI values to be kept, got %s/%s", updated.AISeverity, updated.AICategory)
+	}
+	if override.AISeverity != "medium" || override.Actor != "tester" {
+		t.Errorf("Unexpected override record: %+v", override)
+	}
+
+	// A later override of one field keeps the other
+	if _, _, err := service.OverrideClassification(incident.ID, "medium", "", "Only some users affected", "tester"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	overrides, err := service.GetOverrides(incident.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(overrides) != 2 {
+		t.Fatalf("Expected 2 overrides, got %d", len(overrides))
+	}
+	if overrides[1].HumanSeverity != "medium" || overrides[1].HumanCategory != "security" {
+		t.Errorf("Unexpected second override: %+v", overrides[1])
+	}
+
+	// A reclassification does not clobber the human values
+	if _, _, err := service.ClassifyIncident(context.Background(), incident.ID); err != nil {
+		t.Fatalf("Failed to classify incident: %v", err)
+	}
+
+	examples, err := service.FeedbackExamples()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var example *FeedbackExample
+	for i := range examples {
+		if examples[i].IncidentID == incident.ID {
+			example = &examples[i]
+		}
+	}
+	if example == nil {
+		t.Fatal("Expected the overridden incident in the feedback export")
+	}
+	if example.Severity != "medium" || example.Category != "security" {
+		t.Errorf("Expected labels medium/security, got %s/%s", example.Severity, example.Category)
+	}
+}
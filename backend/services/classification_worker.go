@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WorkerConfig controls the classification worker pool
+type WorkerConfig struct {
+	// Concurrency is the number of jobs processed in parallel
+	Concurrency int
+	// PollInterval is how often idle workers check the queue
+	PollInterval time.Duration
+	// MaxAttempts is how many times a job runs before it is marked failed
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles on every attempt
+	BaseBackoff time.Duration
+	// MaxBackoff caps the retry delay
+	MaxBackoff time.Duration
+	// JobTimeout bounds a single classification attempt
+	JobTimeout time.Duration
+}
+
+// DefaultWorkerConfig returns the default worker pool settings
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		Concurrency:  2,
+		PollInterval: time.Second,
+		MaxAttempts:  5,
+		BaseBackoff:  2 * time.Second,
+		MaxBackoff:   5 * time.Minute,
+		JobTimeout:   30 * time.Second,
+	}
+}
+
+// WorkerConfigFromEnv reads AI_WORKER_CONCURRENCY, AI_WORKER_POLL_INTERVAL,
+// AI_JOB_MAX_ATTEMPTS and AI_JOB_TIMEOUT on top of the defaults
+func WorkerConfigFromEnv() WorkerConfig {
+	config := DefaultWorkerConfig()
+	if value, err := strconv.Atoi(os.Getenv("AI_WORKER_CONCURRENCY")); err == nil && value > 0 {
+		config.Concurrency = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("AI_WORKER_POLL_INTERVAL")); err == nil && value > 0 {
+		config.PollInterval = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("AI_JOB_MAX_ATTEMPTS")); err == nil && value > 0 {
+		config.MaxAttempts = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("AI_JOB_TIMEOUT")); err == nil && value > 0 {
+		config.JobTimeout = value
+	}
+	return config
+}
+
+// ClassificationWorker processes the classification queue with a pool of goroutines
+type ClassificationWorker struct {
+	config         WorkerConfig
+	jobs           *repository.ClassificationJobRepository
+	classification *ClassificationService
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewClassificationWorker creates a worker pool that classifies incidents with the given classifier
+func NewClassificationWorker(classifier Classifier, config WorkerConfig) *ClassificationWorker {
+	return &ClassificationWorker{
+		config:         config,
+		jobs:           repository.NewClassificationJobRepository(),
+		classification: NewClassificationService(classifier),
+	}
+}
+
+// Start recovers jobs interrupted by a previous shutdown and launches the worker goroutines.
+// Only one worker pool should run against a database at a time.
+func (w *ClassificationWorker) Start(ctx context.Context) {
+	if requeued, err := w.jobs.RequeueRunning(); err != nil {
+		log.Println("Failed to requeue interrupted classification jobs:", err)
+	} else if requeued > 0 {
+		log.Printf("Requeued %d interrupted classification jobs", requeued)
+	}
+	if enqueued, err := w.jobs.EnqueueMissing(w.config.MaxAttempts); err != nil {
+		log.Println("Failed to enqueue pending incidents:", err)
+	} else if enqueued > 0 {
+		log.Printf("Enqueued %d pending incidents for classification", enqueued)
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	for i := 0; i < w.config.Concurrency; i++ {
+		w.wg.Add(1)
+		go w.run(ctx)
+	}
+}
+
+// Stop signals the workers to exit and waits for in-flight jobs to finish
+func (w *ClassificationWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// run processes jobs until the context is cancelled, sleeping when the queue is empty
+func (w *ClassificationWorker) run(ctx context.Context) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		processed, err := w.processNext(ctx)
+		if err != nil {
+			log.Println("Classification worker error:", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processNext claims and runs a single job, reporting whether one was available
+func (w *ClassificationWorker) processNext(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	job, err := w.jobs.ClaimNext(time.Now())
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, w.config.JobTimeout)
+	defer cancel()
+
+	_, _, err = w.classification.ClassifyIncident(jobCtx, job.IncidentID)
+	switch {
+	case err == nil:
+		return true, w.jobs.Complete(job)
+	case errors.Is(err, repository.ErrNotFound):
+		// The incident was deleted before it could be classified
+		return true, w.jobs.Fail(job, err)
+	case job.Attempts < w.maxAttempts(job):
+		return true, w.jobs.Retry(job, time.Now().Add(w.backoff(job.Attempts)), err)
+	default:
+		log.Printf("Classification of incident %s failed after %d attempts: %v", job.IncidentID, job.Attempts, err)
+		if markErr := w.classification.MarkFailed(job.IncidentID); markErr != nil {
+			log.Println("Failed to mark incident classification as failed:", markErr)
+		}
+		return true, w.jobs.Fail(job, err)
+	}
+}
+
+// maxAttempts returns the attempt limit of a job, defaulting to the worker configuration
+func (w *ClassificationWorker) maxAttempts(job *model.ClassificationJob) int {
+	if job.MaxAttempts > 0 {
+		return job.MaxAttempts
+	}
+	return w.config.MaxAttempts
+}
+
+// backoff returns the delay before retrying after the given number of attempts
+func (w *ClassificationWorker) backoff(attempts int) time.Duration {
+	delay := w.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.config.MaxBackoff {
+			return w.config.MaxBackoff
+		}
+	}
+	return delay
+}
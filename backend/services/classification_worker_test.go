@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubClassifier fails a configurable number of times per incident title before succeeding
+type stubClassifier struct {
+	mu       sync.Mutex
+	failures map[string]int
+	calls    map[string]int
+	result   AIAnalysisResult
+}
+
+func newStubClassifier(result AIAnalysisResult) *stubClassifier {
+	return &stubClassifier{
+		failures: make(map[string]int),
+		calls:    make(map[string]int),
+		result:   result,
+	}
+}
+
+func (c *stubClassifier) Name() string {
+	return "stub"
+}
+
+func (c *stubClassifier) Classify(ctx context.Context, title, description string) (*AIAnalysisResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[title]++
+	if c.calls[title] <= c.failures[title] {
+		return nil, errors.New("provider unavailable")
+	}
+	result := c.result
+	result.Provider = "stub"
+	return &result, nil
+}
+
+func testWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		Concurrency:  2,
+		PollInterval: 5 * time.Millisecond,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   5 * time.Millisecond,
+		JobTimeout:   time.Second,
+	}
+}
+
+// waitForJob polls until the incident's classification job has finished and returns the job
+func waitForJob(t *testing.T, incidentID string) model.ClassificationJob {
+	jobs := repository.NewClassificationJobRepository()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		list, err := jobs.ListByIncident(incidentID)
+		if err != nil {
+			t.Fatalf("Failed to list jobs: %v", err)
+		}
+		if len(list) == 1 && (list[0].Status == model.JobDone || list[0].Status == model.JobFailed) {
+			return list[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for incident %s to be classified", incidentID)
+	return model.ClassificationJob{}
+}
+
+func TestClassificationWorker_ClassifiesWithRetries(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	title := fmt.Sprintf("Worker retry incident %d", time.Now().UnixNano())
+	service := NewIncidentService()
+	created, err := service.CreateIncident(model.Incident{
+		Title:       title,
+		Description: "Classified on the second attempt",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create incident: %v", err)
+	}
+
+	classifier := newStubClassifier(AIAnalysisResult{Severity: "high", Category: "security"})
+	classifier.failures[title] = 1
+
+	worker := NewClassificationWorker(classifier, testWorkerConfig())
+	worker.Start(context.Background())
+	defer worker.Stop()
+
+	job := waitForJob(t, created.ID)
+	if job.Status != model.JobDone || job.Attempts != 2 {
+		t.Errorf("Expected done job after 2 attempts, got %+v", job)
+	}
+
+	incident, err := service.GetIncident(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload incident: %v", err)
+	}
+	if incident.ClassificationStatus != model.ClassificationCompleted {
+		t.Fatalf("Expected classification status 'completed', got '%s'", incident.ClassificationStatus)
+	}
+	if incident.AISeverity != "high" || incident.AICategory != "security" {
+		t.Errorf("Expected high/security, got %s/%s", incident.AISeverity, incident.AICategory)
+	}
+}
+
+func TestClassificationWorker_FailsAfterMaxAttempts(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	title := fmt.Sprintf("Worker failing incident %d", time.Now().UnixNano())
+	service := NewIncidentService()
+	created, err := service.CreateIncident(model.Incident{
+		Title:       title,
+		Description: "The provider never answers",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create incident: %v", err)
+	}
+
+	classifier := newStubClassifier(AIAnalysisResult{Severity: "low", Category: "network"})
+	classifier.failures[title] = 100
+
+	worker := NewClassificationWorker(classifier, testWorkerConfig())
+	worker.Start(context.Background())
+	defer worker.Stop()
+
+	job := waitForJob(t, created.ID)
+	if job.Status != model.JobFailed || job.Attempts != 3 {
+		t.Errorf("Expected failed job after 3 attempts, got %+v", job)
+	}
+
+	incident, err := service.GetIncident(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload incident: %v", err)
+	}
+	if incident.ClassificationStatus != model.ClassificationFailed {
+		t.Fatalf("Expected classification status 'failed', got '%s'", incident.ClassificationStatus)
+	}
+	if incident.AISeverity != "medium" || incident.AICategory != "software" {
+		t.Errorf("Expected default AI fields to be kept, got %s/%s", incident.AISeverity, incident.AICategory)
+	}
+}
+
+func TestClassificationWorker_Backoff(t *testing.T) {
+	worker := &ClassificationWorker{config: WorkerConfig{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if got := worker.backoff(i + 1); got != delay {
+			t.Errorf("Expected backoff %v after %d attempts, got %v", delay, i+1, got)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Supported classifier providers
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai_compatible"
+	ProviderRules            = "rules"
+)
+
+// Classifier determines the severity and category of an incident
+type Classifier interface {
+	// Name identifies the provider behind the classifier
+	Name() string
+	// Classify analyzes an incident's title and description
+	Classify(ctx context.Context, title, description string) (*AIAnalysisResult, error)
+}
+
+// ClassifierConfig selects and configures an AI provider for classification, summaries,
+// remediation suggestions and postmortem drafts
+type ClassifierConfig struct {
+	Provider string
+	APIKey   string
+	BaseURL  string
+	Model    string
+	// RulesFile is a YAML rule set for the rule-based classifier, which is also
+	// the offline mode and fallback of the LLM providers
+	RulesFile string
+	// PromptVersion selects the prompt template of the LLM providers; empty uses the provider default
+	PromptVersion string
+	// SummaryPromptVersion selects the incident summary prompt; empty uses summarize-v1
+	SummaryPromptVersion string
+	// RemediationPromptVersion selects the remediation suggestion prompt; empty uses remediate-v1
+	RemediationPromptVersion string
+	// PostmortemPromptVersion selects the postmortem draft prompt; empty uses postmortem-v1
+	PostmortemPromptVersion string
+	// PromptDir is a directory of additional .tmpl prompt files
+	PromptDir string
+	// FewShotExamples is the number of human-corrected incidents included in prompts
+	FewShotExamples int
+	// OutputMode selects tools, json_object or text output; empty uses the provider default
+	OutputMode string
+	// OutputRetries is how many times an answer that does not match the schema is re-requested
+	OutputRetries int
+	// CallPolicy bounds and retries provider calls; a zero Timeout uses the default policy
+	CallPolicy CallPolicy
+	// BreakerThreshold and BreakerCooldown configure the provider's circuit breaker; zero uses the defaults
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Cache configures the result cache of the LLM providers; a zero Size disables it
+	Cache CacheConfig
+	// Redaction controls what is removed from incident text before it reaches the provider
+	Redaction RedactionConfig
+	// Usage configures the price table and monthly budget of the LLM providers
+	Usage UsageConfig
+}
+
+// ClassifierConfigFromEnv reads the classifier configuration from the environment:
+// AI_PROVIDER, AI_API_KEY (or OPENAI_API_KEY), AI_BASE_URL, AI_MODEL, AI_RULES_FILE,
+// AI_PROMPT_VERSION, AI_SUMMARY_PROMPT_VERSION, AI_REMEDIATION_PROMPT_VERSION,
+// AI_POSTMORTEM_PROMPT_VERSION, AI_PROMPT_DIR, AI_PROMPT_EXAMPLES, AI_OUTPUT_MODE, AI_OUTPUT_RETRIES,
+// AI_CALL_TIMEOUT, AI_CALL_RETRIES, AI_BREAKER_THRESHOLD, AI_BREAKER_COOLDOWN,
+// AI_CACHE_SIZE, AI_CACHE_TTL, AI_CACHE_PERSISTENT, the AI_REDACTION settings, AI_PRICE_FILE and AI_MONTHLY_BUDGET
+func ClassifierConfigFromEnv() ClassifierConfig {
+	config := ClassifierConfig{
+		Provider:                 strings.ToLower(strings.TrimSpace(os.Getenv("AI_PROVIDER"))),
+		APIKey:                   os.Getenv("AI_API_KEY"),
+		BaseURL:                  os.Getenv("AI_BASE_URL"),
+		Model:                    os.Getenv("AI_MODEL"),
+		RulesFile:                os.Getenv("AI_RULES_FILE"),
+		PromptVersion:            os.Getenv("AI_PROMPT_VERSION"),
+		SummaryPromptVersion:     os.Getenv("AI_SUMMARY_PROMPT_VERSION"),
+		RemediationPromptVersion: os.Getenv("AI_REMEDIATION_PROMPT_VERSION"),
+		PostmortemPromptVersion:  os.Getenv("AI_POSTMORTEM_PROMPT_VERSION"),
+		PromptDir:                os.Getenv("AI_PROMPT_DIR"),
+		FewShotExamples:          DefaultFewShotExamples,
+		OutputMode:               strings.ToLower(strings.TrimSpace(os.Getenv("AI_OUTPUT_MODE"))),
+		OutputRetries:            DefaultOutputRetries,
+		CallPolicy:               DefaultCallPolicy(),
+		Cache:                    CacheConfig{Size: DefaultCacheSize, TTL: DefaultCacheTTL},
+		Redaction:                RedactionConfigFromEnv(),
+		Usage:                    UsageConfigFromEnv(),
+	}
+	if value, err := strconv.Atoi(os.Getenv("AI_PROMPT_EXAMPLES")); err == nil && value >= 0 {
+		config.FewShotExamples = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("AI_OUTPUT_RETRIES")); err == nil && value >= 0 {
+		config.OutputRetries = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("AI_CALL_TIMEOUT")); err == nil && value > 0 {
+		config.CallPolicy.Timeout = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("AI_CALL_RETRIES")); err == nil && value >= 0 {
+		config.CallPolicy.MaxRetries = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("AI_BREAKER_THRESHOLD")); err == nil && value > 0 {
+		config.BreakerThreshold = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("AI_BREAKER_COOLDOWN")); err == nil && value > 0 {
+		config.BreakerCooldown = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("AI_CACHE_SIZE")); err == nil && value >= 0 {
+		config.Cache.Size = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("AI_CACHE_TTL")); err == nil && value > 0 {
+		config.Cache.TTL = value
+	}
+	if value, err := strconv.ParseBool(os.Getenv("AI_CACHE_PERSISTENT")); err == nil {
+		config.Cache.Persistent = value
+	}
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if config.Provider == "" {
+		config.Provider = ProviderOpenAI
+	}
+	if config.Model == "" {
+		config.Model = openai.GPT3Dot5Turbo
+	}
+	return config
+}
+
+// NewClassifier creates the classifier selected by the configuration
+func NewClassifier(config ClassifierConfig) (Classifier, error) {
+	rules, err := newRuleClassifierFromFile(config.RulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules from %s: %v", config.RulesFile, err)
+	}
+
+	if config.Provider == ProviderRules {
+		return rules, nil
+	}
+	service, err := newAIServiceFromConfig(config, rules)
+	if err != nil {
+		return nil, err
+	}
+	return withResultCache(service, config.Cache), nil
+}
+
+// newAIServiceFromConfig creates the LLM-backed service of the configured provider
+func newAIServiceFromConfig(config ClassifierConfig, fallback Classifier) (*AIService, error) {
+	var service *AIService
+	switch config.Provider {
+	case ProviderOpenAI:
+		service = newOpenAIService(config.APIKey, config.Model)
+	case ProviderOpenAICompatible:
+		if config.BaseURL == "" {
+			return nil, fmt.Errorf("provider %s requires AI_BASE_URL", ProviderOpenAICompatible)
+		}
+		service = NewOpenAICompatibleService(config.BaseURL, config.APIKey, config.Model)
+	default:
+		return nil, fmt.Errorf("unknown AI provider '%s' (valid providers: %s, %s, %s)",
+			config.Provider, ProviderOpenAI, ProviderOpenAICompatible, ProviderRules)
+	}
+	if err := configureAIService(service, config, fallback); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// configureAIService applies the fallback, redaction, accounting, resilience, output and prompt
+// settings to an LLM-backed service
+func configureAIService(service *AIService, config ClassifierConfig, fallback Classifier) error {
+	redactor, err := NewRedactor(config.Redaction)
+	if err != nil {
+		return err
+	}
+	if err := service.usage.configure(config.Usage); err != nil {
+		return err
+	}
+	service.redactor = redactor
+	service.fallback = fallback
+	service.fewShot = config.FewShotExamples
+	service.outputRetries = config.OutputRetries
+	if config.CallPolicy.Timeout > 0 {
+		service.policy = config.CallPolicy
+	}
+	service.breaker.configure(config.BreakerThreshold, config.BreakerCooldown)
+	if config.OutputMode != "" {
+		if !validOutputMode(config.OutputMode) {
+			return fmt.Errorf("unknown output mode '%s' (valid modes: %s, %s, %s)",
+				config.OutputMode, OutputModeTools, OutputModeJSONObject, OutputModeText)
+		}
+		service.outputMode = config.OutputMode
+	}
+	if config.PromptVersion == "" && config.SummaryPromptVersion == "" && config.RemediationPromptVersion == "" &&
+		config.PostmortemPromptVersion == "" && config.PromptDir == "" {
+		return nil
+	}
+
+	prompt, err := LoadPromptTemplate(firstNonEmpty(config.PromptVersion, service.prompt.Version), config.PromptDir)
+	if err != nil {
+		return err
+	}
+	service.prompt = prompt
+
+	summaryPrompt, err := LoadPromptTemplate(firstNonEmpty(config.SummaryPromptVersion, service.summaryPrompt.Version), config.PromptDir)
+	if err != nil {
+		return err
+	}
+	service.summaryPrompt = summaryPrompt
+
+	remediationPrompt, err := LoadPromptTemplate(firstNonEmpty(config.RemediationPromptVersion, service.remediationPrompt.Version), config.PromptDir)
+	if err != nil {
+		return err
+	}
+	service.remediationPrompt = remediationPrompt
+
+	postmortemPrompt, err := LoadPromptTemplate(firstNonEmpty(config.PostmortemPromptVersion, service.postmortemPrompt.Version), config.PromptDir)
+	if err != nil {
+		return err
+	}
+	service.postmortemPrompt = postmortemPrompt
+	return nil
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// withResultCache wraps an LLM-backed service with the process-wide result cache, if enabled
+func withResultCache(service *AIService, config CacheConfig) Classifier {
+	if config.Size <= 0 {
+		return service
+	}
+	return NewCachingClassifier(service, sharedResultCache(config))
+}
+
+// NewClassifierFromEnv creates the configured classifier, falling back to the
+// rule-based classifier when the configuration is invalid
+func NewClassifierFromEnv() Classifier {
+	classifier, err := NewClassifier(ClassifierConfigFromEnv())
+	if err != nil {
+		log.Println("Invalid AI classifier configuration, using built-in rule-based classifier:", err)
+		return NewDefaultRuleClassifier()
+	}
+	return classifier
+}
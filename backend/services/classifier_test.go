@@ -0,0 +1,65 @@
+package services
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNewClassifier(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           ClassifierConfig
+		expectedProvider string
+		expectError      bool
+	}{
+		{"OpenAI", ClassifierConfig{Provider: ProviderOpenAI, Model: "gpt-3.5-turbo"}, ProviderOpenAI, false},
+		{"OpenAI-compatible", ClassifierConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:11434/v1", Model: "llama3"}, ProviderOpenAICompatible, false},
+		{"OpenAI-compatible without base URL", ClassifierConfig{Provider: ProviderOpenAICompatible}, "", true},
+		{"Rules", ClassifierConfig{Provider: ProviderRules}, ProviderRules, false},
+		{"Unknown provider", ClassifierConfig{Provider: "magic"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classifier, err := NewClassifier(tt.config)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if classifier.Name() != tt.expectedProvider {
+				t.Errorf("Expected provider '%s', got '%s'", tt.expectedProvider, classifier.Name())
+			}
+		})
+	}
+}
+
+func TestClassifierConfigFromEnv(t *testing.T) {
+	for _, key := range []string{"AI_PROVIDER", "AI_API_KEY", "AI_BASE_URL", "AI_MODEL"} {
+		original, present := os.LookupEnv(key)
+		if present {
+			defer os.Setenv(key, original)
+		} else {
+			defer os.Unsetenv(key)
+		}
+	}
+
+	os.Setenv("AI_PROVIDER", "OpenAI_Compatible")
+	os.Setenv("AI_BASE_URL", "http://localhost:8000/v1")
+	os.Unsetenv("AI_MODEL")
+
+	config := ClassifierConfigFromEnv()
+	if config.Provider != ProviderOpenAICompatible {
+		t.Errorf("Expected provider '%s', got '%s'", ProviderOpenAICompatible, config.Provider)
+	}
+	if config.BaseURL != "http://localhost:8000/v1" {
+		t.Errorf("Expected base URL to be read, got '%s'", config.BaseURL)
+	}
+	if config.Model == "" {
+		t.Error("Expected a default model")
+	}
+}
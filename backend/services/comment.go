@@ -0,0 +1,263 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"incident-management/repository"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxCommentLength caps the length of a comment body in characters
+const MaxCommentLength = 10000
+
+// Kinds of entries in an incident's activity timeline
+const (
+	ActivityCreated    = "created"
+	ActivityComment    = "comment"
+	ActivityTransition = "transition"
+	ActivityOverride   = "override"
+	ActivityOccurrence = "occurrence"
+	ActivityChange     = "change"
+)
+
+// ActivityTypes lists the kinds of activity entries, in the order entries at the same time are listed
+var ActivityTypes = []string{ActivityCreated, ActivityTransition, ActivityChange, ActivityOverride, ActivityOccurrence, ActivityComment}
+
+// activityChangeSkipped lists the fields whose changes the timeline shows as transitions,
+// overrides or occurrences rather than as field changes
+var activityChangeSkipped = map[string]bool{
+	"status":           true,
+	"human_severity":   true,
+	"human_category":   true,
+	"occurrence_count": true,
+}
+
+// ErrNoComment is returned when an incident has no comment with the requested ID
+var ErrNoComment = errors.New("comment not found")
+
+// ActivityEntry is one event in an incident's activity timeline
+type ActivityEntry struct {
+	Type  string    `json:"type"`
+	At    time.Time `json:"at"`
+	Actor string    `json:"actor,omitempty"`
+	// Text describes the event in one line
+	Text string `json:"text"`
+	// Data is the record behind the event, e.g. the comment or the status transition
+	Data interface{} `json:"data,omitempty"`
+}
+
+// CommentService manages the comments of incidents and their activity timeline
+type CommentService struct {
+	incidents   *repository.IncidentRepository
+	comments    *repository.CommentRepository
+	transitions *repository.TransitionRepository
+	overrides   *repository.OverrideRepository
+	changes     *repository.ChangeRepository
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService() *CommentService {
+	return &CommentService{
+		incidents:   repository.NewIncidentRepository(),
+		comments:    repository.NewCommentRepository(),
+		transitions: repository.NewTransitionRepository(),
+		overrides:   repository.NewOverrideRepository(),
+		changes:     repository.NewChangeRepository(),
+	}
+}
+
+// AddComment stores a comment on an incident
+func (s *CommentService) AddComment(incidentID, body, author string) (*model.Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, &ValidationError{Details: map[string]string{"body": "body is required"}}
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return nil, &ValidationError{Details: map[string]string{"body": fmt.Sprintf("body must be at most %d characters", MaxCommentLength)}}
+	}
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+
+	comment := &model.Comment{IncidentID: incidentID, Body: body, Author: author}
+	if err := s.comments.Create(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetComments retrieves the comments of an incident, oldest first
+func (s *CommentService) GetComments(incidentID string) ([]model.Comment, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+	return s.comments.ListByIncident(incidentID)
+}
+
+// DeleteComment removes a comment from an incident
+func (s *CommentService) DeleteComment(incidentID, commentID string) error {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return err
+	}
+	err := s.comments.Delete(incidentID, commentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNoComment
+	}
+	return err
+}
+
+// GetActivity merges the incident's creation, comments, status transitions, field changes,
+// classification overrides and repeat reports into one timeline, oldest first. types restricts the timeline
+// to some kinds of entries; empty includes all of them.
+func (s *CommentService) GetActivity(incidentID string, types []string) ([]ActivityEntry, error) {
+	include := make(map[string]bool)
+	for _, kind := range types {
+		if activityOrder(kind) < 0 {
+			return nil, &ValidationError{Details: map[string]string{
+				"type": fmt.Sprintf("unknown activity type '%s' (valid types: %s)", kind, strings.Join(ActivityTypes, ", ")),
+			}}
+		}
+		include[kind] = true
+	}
+	included := func(kind string) bool { return len(include) == 0 || include[kind] }
+
+	incident, err := s.incidents.GetByID(incidentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ActivityEntry
+	if included(ActivityCreated) {
+		entries = append(entries, ActivityEntry{Type: ActivityCreated, At: incident.CreatedAt, Text: "opened: " + incident.Title})
+	}
+	if included(ActivityComment) {
+		comments, err := s.comments.ListByIncident(incidentID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			entries = append(entries, ActivityEntry{Type: ActivityComment, At: comments[i].CreatedAt, Actor: comments[i].Author, Text: commentText(comments[i]), Data: comments[i]})
+		}
+	}
+	if included(ActivityTransition) {
+		transitions, err := s.transitions.ListByIncident(incidentID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range transitions {
+			entries = append(entries, ActivityEntry{Type: ActivityTransition, At: transitions[i].CreatedAt, Actor: transitions[i].Actor, Text: transitionText(transitions[i]), Data: transitions[i]})
+		}
+	}
+	if included(ActivityChange) {
+		changes, err := s.changes.ListByIncident(incidentID, "", "")
+		if err != nil {
+			return nil, err
+		}
+		for i := range changes {
+			if activityChangeSkipped[changes[i].Field] {
+				continue
+			}
+			entries = append(entries, ActivityEntry{Type: ActivityChange, At: changes[i].CreatedAt, Actor: changes[i].Actor, Text: changeText(changes[i]), Data: changes[i]})
+		}
+	}
+	if included(ActivityOverride) {
+		overrides, err := s.overrides.ListByIncident(incidentID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range overrides {
+			entries = append(entries, ActivityEntry{Type: ActivityOverride, At: overrides[i].CreatedAt, Actor: overrides[i].Actor, Text: overrideText(overrides[i]), Data: overrides[i]})
+		}
+	}
+	if included(ActivityOccurrence) {
+		occurrences, err := s.incidents.ListOccurrences(incidentID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range occurrences {
+			entries = append(entries, ActivityEntry{Type: ActivityOccurrence, At: occurrences[i].CreatedAt, Text: "reported again: " + occurrences[i].Title, Data: occurrences[i]})
+		}
+	}
+
+	sortActivity(entries)
+	if entries == nil {
+		entries = []ActivityEntry{}
+	}
+	return entries, nil
+}
+
+// sortActivity orders entries by time; entries at the same time follow ActivityTypes
+func sortActivity(entries []ActivityEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].At.Equal(entries[j].At) {
+			return entries[i].At.Before(entries[j].At)
+		}
+		return activityOrder(entries[i].Type) < activityOrder(entries[j].Type)
+	})
+}
+
+// activityOrder returns the position of an activity type in ActivityTypes, or -1 if it is unknown
+func activityOrder(kind string) int {
+	for i, known := range ActivityTypes {
+		if known == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+// transitionText describes a status transition in one line
+func transitionText(transition model.StatusTransition) string {
+	text := fmt.Sprintf("%s, %s to %s", transition.Action, transition.FromStatus, transition.ToStatus)
+	if transition.Actor != "" {
+		text += " by " + transition.Actor
+	}
+	if transition.Reason != "" {
+		text += " (" + transition.Reason + ")"
+	}
+	return text
+}
+
+// commentText describes a comment in one line, shortening long comments
+func commentText(comment model.Comment) string {
+	text := "comment"
+	if comment.Author != "" {
+		text += " by " + comment.Author
+	}
+	return text + ": " + leadSentences(comment.Body, 3, 300)
+}
+
+// changeText describes a field change in one line; descriptions are too long to quote
+func changeText(change model.IncidentChange) string {
+	text := change.Field + " changed"
+	if change.Field != "description" {
+		text += fmt.Sprintf(" from %s to %s", change.OldValue, change.NewValue)
+	}
+	if change.Actor != "" {
+		text += " by " + change.Actor
+	}
+	return text
+}
+
+// overrideText describes a classification override in one line
+func overrideText(override model.ClassificationOverride) string {
+	var changes []string
+	if override.HumanSeverity != "" {
+		changes = append(changes, "severity "+override.HumanSeverity)
+	}
+	if override.HumanCategory != "" {
+		changes = append(changes, "category "+override.HumanCategory)
+	}
+	text := "classification overridden to " + strings.Join(changes, " and ")
+	if override.Actor != "" {
+		text += " by " + override.Actor
+	}
+	if override.Reason != "" {
+		text += " (" + override.Reason + ")"
+	}
+	return text
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"strings"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	incidents := NewIncidentService()
+	incident, err := incidents.CreateIncident(model.Incident{
+		Title:       "Comment test incident",
+		Description: "Nightly export did not run",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	service := NewCommentService()
+	var validationErr *ValidationError
+	if _, err := service.AddComment(incident.ID, "   ", "alice"); !errors.As(err, &validationErr) {
+		t.Errorf("Expected a blank comment to be rejected, got %v", err)
+	}
+	if _, err := service.AddComment(incident.ID, strings.Repeat("ж", MaxCommentLength+1), "alice"); !errors.As(err, &validationErr) {
+		t.Errorf("Expected a comment over %d characters to be rejected, got %v", MaxCommentLength, err)
+	}
+	longest, err := service.AddComment(incident.ID, strings.Repeat("ж", MaxCommentLength), "alice")
+	if err != nil {
+		t.Fatalf("Expected a comment of %d multi-byte characters to be accepted, got %v", MaxCommentLength, err)
+	}
+	if err := service.DeleteComment(incident.ID, longest.ID); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if _, err := service.AddComment("non-existent-id", "Looking", "alice"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown incident, got %v", err)
+	}
+
+	first, err := service.AddComment(incident.ID, "  Checked the scheduler logs  ", "alice")
+	if err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	if first.Body != "Checked the scheduler logs" || first.Author != "alice" {
+		t.Errorf("Unexpected comment %+v", first)
+	}
+	if _, err := incidents.TransitionIncident(incident.ID, "start", "", "bob"); err != nil {
+		t.Fatalf("Failed to transition incident: %v", err)
+	}
+	second, err := service.AddComment(incident.ID, "Cron daemon was stopped", "bob")
+	if err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+
+	comments, err := service.GetComments(incident.ID)
+	if err != nil || len(comments) != 2 || comments[0].ID != first.ID {
+		t.Fatalf("Expected both comments oldest first, got %v %v", comments, err)
+	}
+
+	activity, err := service.GetActivity(incident.ID, nil)
+	if err != nil {
+		t.Fatalf("Failed to get activity: %v", err)
+	}
+	var types []string
+	for _, entry := range activity {
+		types = append(types, entry.Type)
+	}
+	if got := strings.Join(types, ","); got != "created,comment,transition,comment" {
+		t.Errorf("Expected the entries in chronological order, got %s", got)
+	}
+	if activity[1].Actor != "alice" || activity[2].Text != "start, open to in_progress by bob" {
+		t.Errorf("Unexpected entries %+v", activity)
+	}
+
+	onlyComments, err := service.GetActivity(incident.ID, []string{ActivityComment})
+	if err != nil || len(onlyComments) != 2 {
+		t.Errorf("Expected 2 comment entries, got %v %v", onlyComments, err)
+	}
+	if _, err := service.GetActivity(incident.ID, []string{"email"}); !errors.As(err, &validationErr) {
+		t.Errorf("Expected an unknown type to be rejected, got %v", err)
+	}
+
+	// Comments feed the summary timeline
+	summary, err := NewSummaryService(NewLocalSummarizer()).GenerateSummary(context.Background(), incident.ID, "alice")
+	if err != nil {
+		t.Fatalf("Failed to generate summary: %v", err)
+	}
+	var timeline []string
+	if err := json.Unmarshal(summary.Timeline, &timeline); err != nil {
+		t.Fatalf("Failed to decode timeline: %v", err)
+	}
+	if len(timeline) != 4 || !strings.HasSuffix(timeline[3], "comment by bob: Cron daemon was stopped.") {
+		t.Errorf("Expected the comments in the summary timeline, got %v", timeline)
+	}
+
+	if err := service.DeleteComment(incident.ID, second.ID); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if err := service.DeleteComment(incident.ID, second.ID); !errors.Is(err, ErrNoComment) {
+		t.Errorf("Expected ErrNoComment, got %v", err)
+	}
+}
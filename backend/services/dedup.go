@@ -0,0 +1,43 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"incident-management/model"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultDedupWindow is how long after its last report an open incident absorbs repeat reports
+const DefaultDedupWindow = 10 * time.Minute
+
+// dedupStatuses are the statuses of incidents that repeat reports can be attached to
+var dedupStatuses = []string{"open", "in_progress"}
+
+// DedupConfig controls the deduplication of incoming incidents
+type DedupConfig struct {
+	// Window is how long after its last report an incident absorbs repeat reports; zero disables deduplication
+	Window time.Duration
+}
+
+// DedupConfigFromEnv reads INCIDENT_DEDUP_WINDOW
+func DedupConfigFromEnv() DedupConfig {
+	config := DedupConfig{Window: DefaultDedupWindow}
+	if value, err := time.ParseDuration(os.Getenv("INCIDENT_DEDUP_WINDOW")); err == nil && value >= 0 {
+		config.Window = value
+	}
+	return config
+}
+
+// incidentFingerprint identifies repeat reports of the same problem. The explicit dedup key
+// wins; otherwise reports match on their title with only case and whitespace folded. Numbers
+// and identifiers are kept, so "db-01 down" and "db-02 down" stay separate incidents.
+func incidentFingerprint(incident *model.Incident) string {
+	source := "title:" + strings.Join(strings.Fields(strings.ToLower(incident.Title)), " ")
+	if key := strings.TrimSpace(incident.DedupKey); key != "" {
+		source = "key:" + key
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// DefaultEmbeddingModel is the embedding model of the hosted OpenAI API
+	DefaultEmbeddingModel = string(openai.SmallEmbedding3)
+	// LocalEmbeddingDimensions is the size of the hashed TF-IDF vectors of the local embedder
+	LocalEmbeddingDimensions = 1024
+)
+
+// ErrEmbeddingUnavailable is returned when the embedding provider cannot embed text
+var ErrEmbeddingUnavailable = errors.New("embedding provider unavailable")
+
+// Embedder turns incident text into vectors for similarity search
+type Embedder interface {
+	// Name identifies the provider behind the embedder
+	Name() string
+	// Model identifies the vector space; vectors of different models are never compared
+	Model() string
+	// Embed returns one vector per text
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbeddingConfig selects and configures an embedding provider
+type EmbeddingConfig struct {
+	Provider string
+	APIKey   string
+	BaseURL  string
+	Model    string
+	// Redaction controls what is removed from incident text before it reaches the provider
+	Redaction RedactionConfig
+}
+
+// EmbeddingConfigFromEnv reads the embedding configuration from AI_EMBEDDING_PROVIDER and
+// AI_EMBEDDING_MODEL. The provider defaults to AI_PROVIDER, and to local when that is the
+// rule engine or no OpenAI key is set; AI_API_KEY, AI_BASE_URL and redaction are shared with classification.
+func EmbeddingConfigFromEnv() EmbeddingConfig {
+	classifier := ClassifierConfigFromEnv()
+	config := EmbeddingConfig{
+		Provider:  strings.ToLower(strings.TrimSpace(os.Getenv("AI_EMBEDDING_PROVIDER"))),
+		APIKey:    classifier.APIKey,
+		BaseURL:   classifier.BaseURL,
+		Model:     os.Getenv("AI_EMBEDDING_MODEL"),
+		Redaction: classifier.Redaction,
+	}
+	if config.Provider == "" {
+		config.Provider = classifier.Provider
+		if config.Provider == ProviderRules || (config.Provider == ProviderOpenAI && config.APIKey == "") {
+			config.Provider = ProviderLocal
+		}
+	}
+	if config.Model == "" && config.Provider == ProviderOpenAI {
+		config.Model = DefaultEmbeddingModel
+	}
+	return config
+}
+
+// NewEmbedder creates the embedder selected by the configuration
+func NewEmbedder(config EmbeddingConfig) (Embedder, error) {
+	if config.Provider == ProviderLocal {
+		return NewLocalEmbedder(), nil
+	}
+	redactor, err := NewRedactor(config.Redaction)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Provider {
+	case ProviderOpenAI:
+		client := openai.NewClient(config.APIKey)
+		return &OpenAIEmbedder{client: client, provider: ProviderOpenAI, model: config.Model, redactor: redactor, usage: usageMeter()}, nil
+	case ProviderOpenAICompatible:
+		if config.BaseURL == "" {
+			return nil, fmt.Errorf("embedding provider %s requires AI_BASE_URL", ProviderOpenAICompatible)
+		}
+		if config.Model == "" {
+			return nil, fmt.Errorf("embedding provider %s requires AI_EMBEDDING_MODEL", ProviderOpenAICompatible)
+		}
+		clientConfig := openai.DefaultConfig(config.APIKey)
+		clientConfig.BaseURL = config.BaseURL
+		return &OpenAIEmbedder{client: openai.NewClientWithConfig(clientConfig), provider: ProviderOpenAICompatible, model: config.Model, redactor: redactor, usage: usageMeter()}, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider '%s' (valid providers: %s, %s, %s)",
+			config.Provider, ProviderOpenAI, ProviderOpenAICompatible, ProviderLocal)
+	}
+}
+
+// NewEmbedderFromEnv creates the configured embedder, falling back to the local
+// embedder when the configuration is invalid
+func NewEmbedderFromEnv() Embedder {
+	embedder, err := NewEmbedder(EmbeddingConfigFromEnv())
+	if err != nil {
+		log.Println("Invalid embedding configuration, using local TF-IDF embeddings:", err)
+		return NewLocalEmbedder()
+	}
+	return embedder
+}
+
+// OpenAIEmbedder embeds text with an OpenAI or OpenAI-compatible embeddings endpoint
+type OpenAIEmbedder struct {
+	client   *openai.Client
+	provider string
+	model    string
+	// redactor removes sensitive values from the text before it is sent to the provider
+	redactor *Redactor
+	// usage records the tokens and cost of every call
+	usage *UsageMeter
+}
+
+// Name identifies the provider behind the embedder
+func (e *OpenAIEmbedder) Name() string {
+	return e.provider
+}
+
+// Model identifies the embedding model
+func (e *OpenAIEmbedder) Model() string {
+	return e.model
+}
+
+// Embed embeds texts in a single request. Nothing is sent once the monthly budget is spent.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := e.usage.Allow(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrEmbeddingUnavailable, e.provider, err)
+	}
+	inputs := make([]string, len(texts))
+	for i, text := range texts {
+		inputs[i] = e.redactor.Scope().Redact(text)
+	}
+	resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: inputs,
+		Model: openai.EmbeddingModel(e.model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrEmbeddingUnavailable, e.provider, err)
+	}
+	e.usage.Record(e.provider, firstNonEmpty(string(resp.Model), e.model), OperationEmbed, openai.Usage{
+		PromptTokens: resp.Usage.PromptTokens,
+		TotalTokens:  resp.Usage.TotalTokens,
+	})
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("%w: %s returned %d embeddings for %d texts", ErrEmbeddingUnavailable, e.provider, len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("%w: %s returned an embedding for unknown input %d", ErrEmbeddingUnavailable, e.provider, item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// budgetSpent reports whether embedder is a paid embedder whose monthly budget is spent.
+// Stored embeddings are then left as they are: the background indexer waits for the next
+// month, and similarity queries that need a new vector compare local vectors computed for
+// the query only, so the stored paid vectors never have to be paid for again.
+func budgetSpent(embedder Embedder) bool {
+	paid, ok := embedder.(*OpenAIEmbedder)
+	return ok && paid.usage.Allow() != nil
+}
+
+// LocalEmbedder embeds text as hashed, log-scaled term frequencies. Inverse document
+// frequencies depend on the whole corpus, so they are applied when vectors are compared.
+type LocalEmbedder struct {
+	dimensions int
+}
+
+// NewLocalEmbedder creates the built-in TF-IDF embedder
+func NewLocalEmbedder() *LocalEmbedder {
+	return &LocalEmbedder{dimensions: LocalEmbeddingDimensions}
+}
+
+// Name identifies the local embedder
+func (e *LocalEmbedder) Name() string {
+	return ProviderLocal
+}
+
+// Model identifies the hashed vector space
+func (e *LocalEmbedder) Model() string {
+	return fmt.Sprintf("tfidf-hash-%d", e.dimensions)
+}
+
+// Embed returns the L2-normalized term frequency vector of each text
+func (e *LocalEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		counts := make(map[uint32]int)
+		for _, term := range embeddingTerms(text) {
+			hash := fnv.New32a()
+			hash.Write([]byte(term))
+			counts[hash.Sum32()%uint32(e.dimensions)]++
+		}
+
+		vector := make([]float32, e.dimensions)
+		for index, count := range counts {
+			vector[index] = float32(1 + math.Log(float64(count)))
+		}
+		normalizeVector(vector)
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// embeddingStopWords are frequent words that say nothing about an incident
+var embeddingStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "has": true, "have": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "this": true, "to": true, "was": true, "were": true,
+	"with": true, "not": true, "no": true, "all": true, "after": true,
+}
+
+// embeddingTerms splits text into lowercase words, without stop words and single characters
+func embeddingTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if len(word) > 1 && !embeddingStopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// normalizeVector scales a vector to unit length in place
+func normalizeVector(vector []float32) {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors of equal length
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// applyIDF weights the local term frequency vectors by inverse document frequency over the
+// query and its candidates, turning them into TF-IDF vectors
+func applyIDF(query []float32, candidates [][]float32) {
+	documents := append([][]float32{query}, candidates...)
+	frequencies := make([]int, len(query))
+	for _, vector := range documents {
+		for i, value := range vector {
+			if i < len(frequencies) && value != 0 {
+				frequencies[i]++
+			}
+		}
+	}
+
+	weights := make([]float32, len(query))
+	for i, frequency := range frequencies {
+		weights[i] = float32(math.Log(float64(len(documents)+1)/float64(frequency+1)) + 1)
+	}
+	for _, vector := range documents {
+		for i := range vector {
+			if i < len(weights) {
+				vector[i] *= weights[i]
+			}
+		}
+	}
+}
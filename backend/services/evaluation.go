@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"incident-management/model"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EvaluationReport summarizes how well a classifier reproduces a labeled corpus
+type EvaluationReport struct {
+	Provider string `json:"provider"`
+	Examples int    `json:"examples"`
+	// Errors counts examples the classifier failed on; they are excluded from the metrics
+	Errors    int `json:"errors"`
+	Fallbacks int `json:"fallbacks"`
+	// ExactMatch is the share of examples with both severity and category correct
+	ExactMatch float64       `json:"exact_match"`
+	Severity   LabelMetrics  `json:"severity"`
+	Category   LabelMetrics  `json:"category"`
+	Latency    LatencyStats  `json:"latency"`
+	Mistakes   []EvalMistake `json:"mistakes,omitempty"`
+}
+
+// LabelMetrics holds the accuracy, per-class scores and confusion matrix of one label
+type LabelMetrics struct {
+	Accuracy float64        `json:"accuracy"`
+	Classes  []ClassMetrics `json:"classes"`
+	// Confusion counts predictions by expected label, then predicted label
+	Confusion map[string]map[string]int `json:"confusion"`
+	// Labels lists every label seen, in taxonomy order followed by unknown labels
+	Labels []string `json:"labels"`
+}
+
+// ClassMetrics holds the precision and recall of a single class
+type ClassMetrics struct {
+	Label     string  `json:"label"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Support   int     `json:"support"`
+}
+
+// LatencyStats summarizes classifier call durations
+type LatencyStats struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	Max  time.Duration `json:"max"`
+}
+
+// EvalMistake is a single example the classifier got wrong or failed on
+type EvalMistake struct {
+	IncidentID        string `json:"incident_id,omitempty"`
+	Title             string `json:"title"`
+	ExpectedSeverity  string `json:"expected_severity"`
+	PredictedSeverity string `json:"predicted_severity,omitempty"`
+	ExpectedCategory  string `json:"expected_category"`
+	PredictedCategory string `json:"predicted_category,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// evalOutcome is the result of classifying one example
+type evalOutcome struct {
+	result  *AIAnalysisResult
+	err     error
+	latency time.Duration
+}
+
+// ReadFeedbackExamples parses a JSON Lines corpus of labeled incidents, as written by the
+// feedback export. Blank lines are skipped; every example needs a title, severity and category.
+func ReadFeedbackExamples(r io.Reader) ([]FeedbackExample, error) {
+	var examples []FeedbackExample
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var example FeedbackExample
+		if err := json.Unmarshal([]byte(text), &example); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if example.Title == "" || example.Severity == "" || example.Category == "" {
+			return nil, fmt.Errorf("line %d: title, severity and category are required", line)
+		}
+		examples = append(examples, example)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return examples, nil
+}
+
+// EvaluateClassifier runs the classifier over labeled examples using up to concurrency
+// parallel calls and compares its output with the labels
+func EvaluateClassifier(ctx context.Context, classifier Classifier, examples []FeedbackExample, concurrency int) *EvaluationReport {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	outcomes := make([]evalOutcome, len(examples))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				started := time.Now()
+				result, err := classifier.Classify(ctx, examples[i].Title, examples[i].Description)
+				outcomes[i] = evalOutcome{result: result, err: err, latency: time.Since(started)}
+			}
+		}()
+	}
+	for i := range examples {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	report := &EvaluationReport{Provider: classifier.Name(), Examples: len(examples)}
+	var expectedSeverities, predictedSeverities, expectedCategories, predictedCategories []string
+	var latencies []time.Duration
+	exact := 0
+
+	for i, outcome := range outcomes {
+		example := examples[i]
+		latencies = append(latencies, outcome.latency)
+		if outcome.err != nil {
+			report.Errors++
+			report.Mistakes = append(report.Mistakes, EvalMistake{
+				IncidentID:       example.IncidentID,
+				Title:            example.Title,
+				ExpectedSeverity: example.Severity,
+				ExpectedCategory: example.Category,
+				Error:            outcome.err.Error(),
+			})
+			continue
+		}
+
+		result := outcome.result
+		if result.Fallback {
+			report.Fallbacks++
+		}
+		expectedSeverities = append(expectedSeverities, example.Severity)
+		predictedSeverities = append(predictedSeverities, result.Severity)
+		expectedCategories = append(expectedCategories, example.Category)
+		predictedCategories = append(predictedCategories, result.Category)
+
+		if result.Severity == example.Severity && result.Category == example.Category {
+			exact++
+			continue
+		}
+		report.Mistakes = append(report.Mistakes, EvalMistake{
+			IncidentID:        example.IncidentID,
+			Title:             example.Title,
+			ExpectedSeverity:  example.Severity,
+			PredictedSeverity: result.Severity,
+			ExpectedCategory:  example.Category,
+			PredictedCategory: result.Category,
+		})
+	}
+
+	taxonomy := model.CurrentTaxonomy()
+	report.Severity = labelMetrics(expectedSeverities, predictedSeverities, taxonomy.Values(model.TaxonomySeverity))
+	report.Category = labelMetrics(expectedCategories, predictedCategories, taxonomy.Values(model.TaxonomyCategory))
+	if scored := len(expectedSeverities); scored > 0 {
+		report.ExactMatch = float64(exact) / float64(scored)
+	}
+	report.Latency = latencyStats(latencies)
+	return report
+}
+
+// labelMetrics computes accuracy, per-class precision/recall and the confusion matrix of one label
+func labelMetrics(expected, predicted []string, known []string) LabelMetrics {
+	metrics := LabelMetrics{Confusion: make(map[string]map[string]int)}
+
+	seen := make(map[string]bool)
+	for _, label := range append(append([]string{}, expected...), predicted...) {
+		seen[label] = true
+	}
+	for _, label := range known {
+		if seen[label] {
+			metrics.Labels = append(metrics.Labels, label)
+			delete(seen, label)
+		}
+	}
+	var unknown []string
+	for label := range seen {
+		unknown = append(unknown, label)
+	}
+	sort.Strings(unknown)
+	metrics.Labels = append(metrics.Labels, unknown...)
+
+	correct := 0
+	for i := range expected {
+		if metrics.Confusion[expected[i]] == nil {
+			metrics.Confusion[expected[i]] = make(map[string]int)
+		}
+		metrics.Confusion[expected[i]][predicted[i]]++
+		if expected[i] == predicted[i] {
+			correct++
+		}
+	}
+	if len(expected) > 0 {
+		metrics.Accuracy = float64(correct) / float64(len(expected))
+	}
+
+	for _, label := range metrics.Labels {
+		truePositives := metrics.Confusion[label][label]
+		support, predictedCount := 0, 0
+		for _, count := range metrics.Confusion[label] {
+			support += count
+		}
+		for _, row := range metrics.Confusion {
+			predictedCount += row[label]
+		}
+
+		class := ClassMetrics{Label: label, Support: support}
+		if predictedCount > 0 {
+			class.Precision = float64(truePositives) / float64(predictedCount)
+		}
+		if support > 0 {
+			class.Recall = float64(truePositives) / float64(support)
+		}
+		if class.Precision+class.Recall > 0 {
+			class.F1 = 2 * class.Precision * class.Recall / (class.Precision + class.Recall)
+		}
+		metrics.Classes = append(metrics.Classes, class)
+	}
+	return metrics
+}
+
+// latencyStats computes the mean, median, 95th percentile and maximum of the durations
+func latencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	return LatencyStats{
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(sorted, 0.50),
+		P95:  percentile(sorted, 0.95),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReadFeedbackExamples(t *testing.T) {
+	corpus := `{"title": "Router down", "description": "No packets", "severity": "high", "category": "network"}
+
+{"title": "Typo", "description": "Footer typo", "severity": "low", "category": "software"}
+`
+	examples, err := ReadFeedbackExamples(strings.NewReader(corpus))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(examples) != 2 || examples[1].Category != "software" {
+		t.Fatalf("Unexpected examples: %+v", examples)
+	}
+
+	if _, err := ReadFeedbackExamples(strings.NewReader(`{"title": "Unlabeled"}`)); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected a line-numbered error for a missing label, got %v", err)
+	}
+	if _, err := ReadFeedbackExamples(strings.NewReader("not json")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestEvaluateClassifier(t *testing.T) {
+	// The stub always answers high/network and fails for one title
+	classifier := newStubClassifier(AIAnalysisResult{Severity: "high", Category: "network"})
+	classifier.failures["Broken"] = 1
+
+	examples := []FeedbackExample{
+		{Title: "Router down", Severity: "high", Category: "network"},
+		{Title: "Switch down", Severity: "high", Category: "network"},
+		{Title: "Typo", Severity: "low", Category: "software"},
+		{Title: "Disk failed", Severity: "high", Category: "hardware"},
+		{Title: "Broken", Severity: "low", Category: "network"},
+	}
+
+	report := EvaluateClassifier(context.Background(), classifier, examples, 2)
+
+	if report.Examples != 5 || report.Errors != 1 {
+		t.Fatalf("Expected 5 examples and 1 error, got %d and %d", report.Examples, report.Errors)
+	}
+	if report.Severity.Accuracy != 0.75 {
+		t.Errorf("Expected severity accuracy 0.75, got %v", report.Severity.Accuracy)
+	}
+	if report.Category.Accuracy != 0.5 {
+		t.Errorf("Expected category accuracy 0.5, got %v", report.Category.Accuracy)
+	}
+	if report.ExactMatch != 0.5 {
+		t.Errorf("Expected exact match 0.5, got %v", report.ExactMatch)
+	}
+
+	// Labels follow taxonomy order and only include labels that occurred
+	if got := strings.Join(report.Category.Labels, ","); got != "network,software,hardware" {
+		t.Errorf("Unexpected category labels: %s", got)
+	}
+	if report.Category.Confusion["hardware"]["network"] != 1 {
+		t.Errorf("Expected hardware to be confused with network once, got %v", report.Category.Confusion)
+	}
+
+	for _, class := range report.Category.Classes {
+		switch class.Label {
+		case "network":
+			if class.Precision != 0.5 || class.Recall != 1 || class.Support != 2 {
+				t.Errorf("Unexpected network metrics: %+v", class)
+			}
+		case "software":
+			if class.Precision != 0 || class.Recall != 0 || class.Support != 1 {
+				t.Errorf("Unexpected software metrics: %+v", class)
+			}
+		}
+	}
+
+	if len(report.Mistakes) != 3 {
+		t.Errorf("Expected 3 mistakes including the error, got %d", len(report.Mistakes))
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"incident-management/model"
+	"strconv"
+	"strings"
+)
+
+// Actor recorded for changes made by deduplication
+const dedupActor = "dedup"
+
+// trackedField is an incident field whose changes are recorded in the incident's history
+type trackedField struct {
+	name  string
+	value func(*model.Incident) string
+}
+
+// trackedFields lists the incident fields whose changes are recorded, in the order changes
+// made together are recorded
+var trackedFields = []trackedField{
+	{"title", func(i *model.Incident) string { return i.Title }},
+	{"description", func(i *model.Incident) string { return i.Description }},
+	{"status", func(i *model.Incident) string { return i.Status }},
+	{"priority", func(i *model.Incident) string { return i.Priority }},
+	{"ai_severity", func(i *model.Incident) string { return i.AISeverity }},
+	{"ai_category", func(i *model.Incident) string { return i.AICategory }},
+	{"human_severity", func(i *model.Incident) string { return i.HumanSeverity }},
+	{"human_category", func(i *model.Incident) string { return i.HumanCategory }},
+	{"occurrence_count", func(i *model.Incident) string { return strconv.Itoa(i.OccurrenceCount) }},
+}
+
+// HistoryFields lists the names of the incident fields whose changes are recorded
+var HistoryFields = func() []string {
+	names := make([]string, len(trackedFields))
+	for i, field := range trackedFields {
+		names[i] = field.name
+	}
+	return names
+}()
+
+// HistorySources lists the sources an incident change can come from
+var HistorySources = []string{model.ChangeSourceAPI, model.ChangeSourceAI, model.ChangeSourceAutomation}
+
+// diffIncident returns a change for every tracked field that differs between two versions of an incident
+func diffIncident(before, after *model.Incident, actor, source string) []model.IncidentChange {
+	var changes []model.IncidentChange
+	for _, field := range trackedFields {
+		oldValue, newValue := field.value(before), field.value(after)
+		if oldValue == newValue {
+			continue
+		}
+		changes = append(changes, model.IncidentChange{
+			IncidentID: after.ID,
+			Field:      field.name,
+			OldValue:   oldValue,
+			NewValue:   newValue,
+			Actor:      actor,
+			Source:     source,
+		})
+	}
+	return changes
+}
+
+// dedupChanges records the changes deduplication makes to an incident it attaches a repeat report to
+func dedupChanges(before, after *model.Incident) []model.IncidentChange {
+	return diffIncident(before, after, dedupActor, model.ChangeSourceAutomation)
+}
+
+// validateHistoryFilter checks the field and source an incident's history is filtered by
+func validateHistoryFilter(field, source string) error {
+	details := make(map[string]string)
+	if field != "" && !containsString(HistoryFields, field) {
+		details["field"] = fmt.Sprintf("unknown field '%s' (valid fields: %s)", field, strings.Join(HistoryFields, ", "))
+	}
+	if source != "" && !containsString(HistorySources, source) {
+		details["source"] = fmt.Sprintf("unknown source '%s' (valid sources: %s)", source, strings.Join(HistorySources, ", "))
+	}
+	if len(details) > 0 {
+		return &ValidationError{Details: details}
+	}
+	return nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, known := range values {
+		if known == value {
+			return true
+		}
+	}
+	return false
+}
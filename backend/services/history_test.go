@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"testing"
+	"time"
+)
+
+func TestIncidentHistory(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	incidents := NewIncidentService()
+	incidents.dedup = DedupConfig{Window: time.Hour}
+	incident, err := incidents.CreateIncident(model.Incident{
+		Title:       "Quokka billing run stalled",
+		Description: "Invoices were not generated",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	history, err := incidents.GetHistory(incident.ID, "", "")
+	if err != nil || len(history) != 0 {
+		t.Fatalf("Expected no history for a new incident, got %v %v", history, err)
+	}
+
+	if _, err := incidents.PatchIncident(incident.ID, []byte(`{"priority": "critical", "title": "Quokka billing run stalled"}`), "alice"); err != nil {
+		t.Fatalf("Failed to patch incident: %v", err)
+	}
+	if _, err := incidents.TransitionIncident(incident.ID, "start", "", "bob"); err != nil {
+		t.Fatalf("Failed to transition incident: %v", err)
+	}
+	classification := NewClassificationService(newStubClassifier(AIAnalysisResult{Provider: "stub", Severity: "critical", Category: "security"}))
+	if _, _, err := classification.ClassifyIncident(context.Background(), incident.ID); err != nil {
+		t.Fatalf("Failed to classify incident: %v", err)
+	}
+	if _, _, err := classification.OverrideClassification(incident.ID, "high", "", "", "carol"); err != nil {
+		t.Fatalf("Failed to override classification: %v", err)
+	}
+	repeat, err := incidents.CreateIncident(model.Incident{
+		Title:       "Quokka billing run stalled",
+		Description: "Invoices were not generated",
+	})
+	if err != nil || !repeat.Deduplicated {
+		t.Fatalf("Expected the repeat report to be deduplicated, got %+v %v", repeat, err)
+	}
+
+	history, err = incidents.GetHistory(incident.ID, "", "")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	expected := []model.IncidentChange{
+		{Field: "priority", OldValue: "medium", NewValue: "critical", Actor: "alice", Source: model.ChangeSourceAPI},
+		{Field: "status", OldValue: "open", NewValue: "in_progress", Actor: "bob", Source: model.ChangeSourceAPI},
+		{Field: "ai_severity", OldValue: "medium", NewValue: "critical", Actor: "stub", Source: model.ChangeSourceAI},
+		{Field: "ai_category", OldValue: "software", NewValue: "security", Actor: "stub", Source: model.ChangeSourceAI},
+		{Field: "human_severity", OldValue: "", NewValue: "high", Actor: "carol", Source: model.ChangeSourceAPI},
+		{Field: "occurrence_count", OldValue: "1", NewValue: "2", Actor: "dedup", Source: model.ChangeSourceAutomation},
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), history)
+	}
+	for i, want := range expected {
+		got := history[i]
+		if got.Field != want.Field || got.OldValue != want.OldValue || got.NewValue != want.NewValue || got.Actor != want.Actor || got.Source != want.Source {
+			t.Errorf("Change %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	priority, err := incidents.GetHistory(incident.ID, "priority", "")
+	if err != nil || len(priority) != 1 || priority[0].Actor != "alice" {
+		t.Errorf("Expected the priority change by alice, got %v %v", priority, err)
+	}
+	ai, err := incidents.GetHistory(incident.ID, "", model.ChangeSourceAI)
+	if err != nil || len(ai) != 2 {
+		t.Errorf("Expected 2 changes from the classifier, got %v %v", ai, err)
+	}
+
+	var validationErr *ValidationError
+	if _, err := incidents.GetHistory(incident.ID, "fingerprint", ""); !errors.As(err, &validationErr) {
+		t.Errorf("Expected an untracked field to be rejected, got %v", err)
+	}
+	if _, err := incidents.GetHistory(incident.ID, "", "email"); !errors.As(err, &validationErr) {
+		t.Errorf("Expected an unknown source to be rejected, got %v", err)
+	}
+	if _, err := incidents.GetHistory("non-existent-id", "", ""); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown incident, got %v", err)
+	}
+
+	activity, err := NewCommentService().GetActivity(incident.ID, []string{ActivityChange})
+	if err != nil {
+		t.Fatalf("Failed to get activity: %v", err)
+	}
+	if len(activity) != 3 || activity[0].Text != "priority changed from medium to critical by alice" {
+		t.Errorf("Expected the priority and AI changes in the activity, got %+v", activity)
+	}
+
+	if err := incidents.DeleteIncident(incident.ID); err != nil {
+		t.Fatalf("Failed to delete incident: %v", err)
+	}
+	if remaining, _ := incidents.changes.ListByIncident(incident.ID, "", ""); len(remaining) != 0 {
+		t.Errorf("Expected the history to be deleted with the incident, got %d changes", len(remaining))
+	}
+}
+
+// editingClassifier runs edit while the incident is being classified, like a responder
+// editing it while the model answers
+type editingClassifier struct {
+	Classifier
+	edit func()
+}
+
+func (c *editingClassifier) Classify(ctx context.Context, title, description string) (*AIAnalysisResult, error) {
+	c.edit()
+	return c.Classifier.Classify(ctx, title, description)
+}
+
+func TestIncidentHistory_EditDuringClassification(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	incidents := NewIncidentService()
+	incident, err := incidents.CreateIncident(model.Incident{
+		Title:       "Wombat queue consumers stuck",
+		Description: "Order events are not processed",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	classifier := &editingClassifier{
+		Classifier: newStubClassifier(AIAnalysisResult{Provider: "stub", Severity: "critical", Category: "security"}),
+		edit: func() {
+			edited := *incident
+			edited.AISeverity = "low"
+			if _, err := incidents.UpdateIncident(incident.ID, edited, "alice"); err != nil {
+				t.Fatalf("Failed to edit incident: %v", err)
+			}
+		},
+	}
+	if _, _, err := NewClassificationService(classifier).ClassifyIncident(context.Background(), incident.ID); err != nil {
+		t.Fatalf("Failed to classify incident: %v", err)
+	}
+
+	history, err := incidents.GetHistory(incident.ID, "ai_severity", model.ChangeSourceAI)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("Expected one AI severity change, got %v %v", history, err)
+	}
+	if history[0].OldValue != "low" || history[0].NewValue != "critical" {
+		t.Errorf("Expected the change from the edited value, got %+v", history[0])
+	}
+}
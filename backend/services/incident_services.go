@@ -1,25 +1,45 @@
 package services
 
 import (
+	"encoding/json"
 	"incident-management/model"
 	"incident-management/repository"
-	"log"
+	"incident-management/utils"
+	"time"
 )
 
 type IncidentService struct {
-	repo *repository.IncidentRepository
-	ai   *AIService
+	repo        *repository.IncidentRepository
+	transitions *repository.TransitionRepository
+	changes     *repository.ChangeRepository
+	lifecycle   *Lifecycle
+	dedup       DedupConfig
+}
+
+// ValidationError reports field-level validation failures detected by the service layer
+type ValidationError struct {
+	Details map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed"
 }
 
 // NewIncidentService creates a new incident service
 func NewIncidentService() *IncidentService {
 	return &IncidentService{
-		repo: repository.NewIncidentRepository(),
-		ai:   NewAIService(),
+		repo:        repository.NewIncidentRepository(),
+		transitions: repository.NewTransitionRepository(),
+		changes:     repository.NewChangeRepository(),
+		lifecycle:   DefaultLifecycle(),
+		dedup:       DedupConfigFromEnv(),
 	}
 }
 
-// CreateIncident creates a new incident with AI analysis
+// CreateIncident stores a new incident and queues it for asynchronous AI classification.
+// Until a worker classifies it, the incident is pending and its AI fields hold default values.
+// A repeat report of an open incident within the dedup window is attached to that incident
+// as an occurrence instead; the existing incident is returned with Deduplicated set.
 func (s *IncidentService) CreateIncident(incident model.Incident) (*model.Incident, error) {
 	// Set default values if not provided
 	if incident.Status == "" {
@@ -28,28 +48,236 @@ func (s *IncidentService) CreateIncident(incident model.Incident) (*model.Incide
 	if incident.Priority == "" {
 		incident.Priority = "medium"
 	}
+	taxonomy := model.CurrentTaxonomy()
+	incident.AISeverity = taxonomy.Default(model.TaxonomySeverity)
+	incident.AICategory = taxonomy.Default(model.TaxonomyCategory)
+	incident.ClassificationStatus = model.ClassificationPending
+	incident.HumanSeverity = ""
+	incident.HumanCategory = ""
+	incident.Fingerprint = incidentFingerprint(&incident)
+	incident.OccurrenceCount = 1
+	incident.LastOccurrenceAt = time.Now()
+	incident.Deduplicated = false
 
-	// Use AI to analyze the incident and determine severity and category
-	aiResult, err := s.ai.AnalyzeIncident(incident.Title, incident.Description)
-	if err != nil {
-		// If AI analysis fails, use default value
-		log.Println("AI analysis failed, using default values", err)
-		incident.AISeverity = "medium"
-		incident.AICategory = "software"
-	} else {
-		incident.AISeverity = aiResult.Severity
-		incident.AICategory = aiResult.Category
+	job := &model.ClassificationJob{
+		Status: model.JobQueued,
+		RunAt:  time.Now(),
+	}
+	if s.dedup.Window <= 0 {
+		if err := s.repo.CreateWithJob(&incident, job); err != nil {
+			return nil, err
+		}
+		return &incident, nil
 	}
 
-	err = s.repo.Create(&incident)
+	occurrence := &model.IncidentOccurrence{
+		Title:       incident.Title,
+		Description: incident.Description,
+		Priority:    incident.Priority,
+		DedupKey:    incident.DedupKey,
+		CreatedAt:   incident.LastOccurrenceAt,
+	}
+	stored, attached, err := s.repo.CreateOrAttach(&incident, job, dedupStatuses, incident.LastOccurrenceAt.Add(-s.dedup.Window), occurrence, dedupChanges)
 	if err != nil {
 		return nil, err
 	}
-
-	return &incident, nil
+	stored.Deduplicated = attached
+	return stored, nil
 }
 
 // GetAllIncidents retrieves all incidents
 func (s *IncidentService) GetAllIncidents() ([]model.Incident, error) {
 	return s.repo.GetAll()
 }
+
+// ListIncidents retrieves a filtered, sorted page of incidents
+func (s *IncidentService) ListIncidents(filter repository.IncidentFilter) (*repository.IncidentPage, error) {
+	if validationErrors := filter.Validate(); validationErrors != nil {
+		return nil, &ValidationError{Details: validationErrors}
+	}
+	return s.repo.List(filter)
+}
+
+// SearchIncidents runs a full-text search over incident titles and descriptions
+func (s *IncidentService) SearchIncidents(query string, limit int) ([]repository.SearchResult, error) {
+	if limit <= 0 {
+		limit = repository.DefaultPageSize
+	}
+	if limit > repository.MaxPageSize {
+		limit = repository.MaxPageSize
+	}
+	return s.repo.Search(query, limit)
+}
+
+// GetIncident retrieves a single incident by ID
+func (s *IncidentService) GetIncident(id string) (*model.Incident, error) {
+	return s.repo.GetByID(id)
+}
+
+// UpdateIncident replaces the mutable fields of an existing incident.
+// Empty status, priority and AI fields keep their current values.
+func (s *IncidentService) UpdateIncident(id string, incident model.Incident, actor string) (*model.Incident, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	incident.ID = existing.ID
+	incident.CreatedAt = existing.CreatedAt
+	incident.ClassificationStatus = existing.ClassificationStatus
+	incident.HumanSeverity = existing.HumanSeverity
+	incident.HumanCategory = existing.HumanCategory
+	keepDedupState(existing, &incident)
+	if incident.Status == "" {
+		incident.Status = existing.Status
+	}
+	if incident.Priority == "" {
+		incident.Priority = existing.Priority
+	}
+	if incident.AISeverity == "" {
+		incident.AISeverity = existing.AISeverity
+	}
+	if incident.AICategory == "" {
+		incident.AICategory = existing.AICategory
+	}
+
+	if err := s.save(existing, &incident, actor); err != nil {
+		return nil, err
+	}
+
+	return &incident, nil
+}
+
+// PatchIncident applies a JSON Merge Patch document to an existing incident
+func (s *IncidentService) PatchIncident(id string, patch []byte, actor string) (*model.Incident, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := utils.ApplyMergePatch(original, patch)
+	if err != nil {
+		return nil, &ValidationError{Details: map[string]string{"patch": err.Error()}}
+	}
+
+	var incident model.Incident
+	if err := json.Unmarshal(merged, &incident); err != nil {
+		return nil, &ValidationError{Details: map[string]string{"patch": err.Error()}}
+	}
+
+	// Identity, timestamps, classification state, overrides and dedup state cannot be changed through a patch
+	incident.ID = existing.ID
+	incident.CreatedAt = existing.CreatedAt
+	incident.ClassificationStatus = existing.ClassificationStatus
+	incident.HumanSeverity = existing.HumanSeverity
+	incident.HumanCategory = existing.HumanCategory
+	keepDedupState(existing, &incident)
+
+	if validationErrors := utils.ValidateAndGetErrors(&incident); validationErrors != nil {
+		return nil, &ValidationError{Details: validationErrors}
+	}
+
+	if err := s.save(existing, &incident, actor); err != nil {
+		return nil, err
+	}
+
+	return &incident, nil
+}
+
+// DeleteIncident removes an incident by ID
+func (s *IncidentService) DeleteIncident(id string) error {
+	return s.repo.Delete(id)
+}
+
+// TransitionIncident performs a lifecycle action on an incident and records it
+func (s *IncidentService) TransitionIncident(id, action, reason, actor string) (*model.Incident, error) {
+	incident, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *incident
+
+	transition, err := s.lifecycle.CheckAction(incident.Status, action)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.StatusTransition{
+		IncidentID: incident.ID,
+		Action:     transition.Action,
+		FromStatus: incident.Status,
+		ToStatus:   transition.To,
+		Reason:     reason,
+		Actor:      actor,
+	}
+	incident.Status = transition.To
+
+	if err := s.transitions.Apply(incident, record, diffIncident(&before, incident, actor, model.ChangeSourceAPI)); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// GetOccurrences retrieves the repeat reports attached to an incident
+func (s *IncidentService) GetOccurrences(id string) ([]model.IncidentOccurrence, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListOccurrences(id)
+}
+
+// GetTransitions retrieves the status history of an incident
+func (s *IncidentService) GetTransitions(id string) ([]model.StatusTransition, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.transitions.ListByIncident(id)
+}
+
+// GetHistory retrieves the field changes of an incident, oldest first, optionally only those
+// of one field or from one source
+func (s *IncidentService) GetHistory(id, field, source string) ([]model.IncidentChange, error) {
+	if err := validateHistoryFilter(field, source); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.changes.ListByIncident(id, field, source)
+}
+
+// keepDedupState carries the dedup key and occurrence counters over to an edited incident and
+// recomputes its fingerprint, so later reports with an edited title are deduplicated against it
+func keepDedupState(existing, incident *model.Incident) {
+	incident.DedupKey = existing.DedupKey
+	incident.Fingerprint = incidentFingerprint(incident)
+	incident.OccurrenceCount = existing.OccurrenceCount
+	incident.LastOccurrenceAt = existing.LastOccurrenceAt
+}
+
+// save persists an edited incident and its field changes, enforcing the lifecycle when the status changed
+func (s *IncidentService) save(existing, incident *model.Incident, actor string) error {
+	changes := diffIncident(existing, incident, actor, model.ChangeSourceAPI)
+	if incident.Status == existing.Status {
+		return s.repo.Update(incident, changes)
+	}
+
+	transition, err := s.lifecycle.CheckStatusChange(existing.Status, incident.Status)
+	if err != nil {
+		return err
+	}
+
+	return s.transitions.Apply(incident, &model.StatusTransition{
+		IncidentID: incident.ID,
+		Action:     transition.Action,
+		FromStatus: existing.Status,
+		ToStatus:   incident.Status,
+		Actor:      actor,
+	}, changes)
+}
@@ -3,8 +3,12 @@ package services
 import (
 	"incident-management/database"
 	"incident-management/model"
+	"incident-management/repository"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewIncidentService(t *testing.T) {
@@ -15,8 +19,8 @@ func TestNewIncidentService(t *testing.T) {
 	if service.repo == nil {
 		t.Fatal("Expected repository to be created, got nil")
 	}
-	if service.ai == nil {
-		t.Fatal("Expected AI service to be created, got nil")
+	if service.transitions == nil {
+		t.Fatal("Expected transition repository to be created, got nil")
 	}
 }
 
@@ -53,7 +57,12 @@ func TestCreateIncident_WithDefaults(t *testing.T) {
 		t.Errorf("Expected priority 'medium', got '%s'", createdIncident.Priority)
 	}
 
-	// Check that AI fields were set (should be defaults when no API key)
+	// Classification happens asynchronously, so the incident starts out pending
+	if createdIncident.ClassificationStatus != model.ClassificationPending {
+		t.Errorf("Expected classification status 'pending', got '%s'", createdIncident.ClassificationStatus)
+	}
+
+	// Check that AI fields were set (defaults until a worker classifies the incident)
 	if createdIncident.AISeverity != "medium" {
 		t.Errorf("Expected AI severity 'medium', got '%s'", createdIncident.AISeverity)
 	}
@@ -171,6 +180,326 @@ func TestGetAllIncidents(t *testing.T) {
 	}
 }
 
+func TestUpdateAndPatchIncident(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	service := NewIncidentService()
+
+	created, err := service.CreateIncident(model.Incident{
+		Title:       "Service Update Incident",
+		Description: "Incident used to test updates",
+		Priority:    "low",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	// PUT keeps existing values for omitted status and priority
+	updated, err := service.UpdateIncident(created.ID, model.Incident{
+		Title:       "Service Update Incident (edited)",
+		Description: "Updated description",
+	}, "tester")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Title != "Service Update Incident (edited)" {
+		t.Errorf("Expected updated title, got '%s'", updated.Title)
+	}
+	if updated.Priority != "low" {
+		t.Errorf("Expected priority 'low' to be kept, got '%s'", updated.Priority)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Expected CreatedAt to be preserved")
+	}
+
+	// PATCH only touches the supplied fields
+	patched, err := service.PatchIncident(created.ID, []byte(`{"priority": "high", "id": "ignored"}`), "tester")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if patched.Priority != "high" {
+		t.Errorf("Expected priority 'high', got '%s'", patched.Priority)
+	}
+	if patched.Title != "Service Update Incident (edited)" {
+		t.Errorf("Expected title to be unchanged, got '%s'", patched.Title)
+	}
+	if patched.ID != created.ID {
+		t.Errorf("Expected ID to be immutable, got '%s'", patched.ID)
+	}
+
+	// Removing a required field fails validation
+	_, err = service.PatchIncident(created.ID, []byte(`{"title": null}`), "tester")
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("Expected ValidationError, got %v", err)
+	}
+
+	// Unknown IDs are reported as not found
+	_, err = service.PatchIncident("00000000-0000-4000-8000-000000000000", []byte(`{"priority": "high"}`), "tester")
+	if err != repository.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDeleteIncident(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	service := NewIncidentService()
+
+	created, err := service.CreateIncident(model.Incident{
+		Title:       "Service Delete Incident",
+		Description: "Incident used to test deletes",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	if err := service.DeleteIncident(created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.GetIncident(created.ID); err != repository.ErrNotFound {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestTransitionIncident(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	service := NewIncidentService()
+
+	created, err := service.CreateIncident(model.Incident{
+		Title:       "Service Transition Incident",
+		Description: "Incident used to test the lifecycle",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	for _, action := range []string{"start", "resolve", "close"} {
+		if _, err := service.TransitionIncident(created.ID, action, "", "tester"); err != nil {
+			t.Fatalf("Expected action '%s' to succeed, got %v", action, err)
+		}
+	}
+
+	// Closed incidents cannot be reopened by editing the status
+	_, err = service.PatchIncident(created.ID, []byte(`{"status": "open"}`), "tester")
+	if _, ok := err.(*TransitionError); !ok {
+		t.Fatalf("Expected TransitionError, got %v", err)
+	}
+
+	reopened, err := service.TransitionIncident(created.ID, "reopen", "Issue came back", "tester")
+	if err != nil {
+		t.Fatalf("Expected reopen to succeed, got %v", err)
+	}
+	if reopened.Status != "open" {
+		t.Errorf("Expected status 'open', got '%s'", reopened.Status)
+	}
+
+	transitions, err := service.GetTransitions(created.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(transitions) != 4 {
+		t.Fatalf("Expected 4 transitions, got %d", len(transitions))
+	}
+	last := transitions[len(transitions)-1]
+	if last.Action != "reopen" || last.Reason != "Issue came back" || last.Actor != "tester" {
+		t.Errorf("Unexpected reopen record: %+v", last)
+	}
+}
+
+func TestPatchIncident_StatusChangeRefreshesSearch(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	service := NewIncidentService()
+
+	created, err := service.CreateIncident(model.Incident{
+		Title:       "Narwhal queue backlog",
+		Description: "Incident used to test search after a status change",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+	t.Cleanup(func() { service.DeleteIncident(created.ID) })
+
+	// A status change saves the incident through the transition repository
+	if _, err := service.PatchIncident(created.ID, []byte(`{"title": "Pangolin queue backlog", "status": "in_progress"}`), "tester"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	results, err := service.SearchIncidents("pangolin", 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].Incident.ID != created.ID {
+		t.Fatalf("Expected the patched incident, got %d results", len(results))
+	}
+	if results[0].Incident.Status != "in_progress" {
+		t.Errorf("Expected status 'in_progress', got '%s'", results[0].Incident.Status)
+	}
+	if !strings.Contains(results[0].TitleHighlight, "Pangolin") {
+		t.Errorf("Expected the new title to be highlighted, got '%s'", results[0].TitleHighlight)
+	}
+
+	results, err = service.SearchIncidents("narwhal", 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected the old title to be dropped from the index, got %d results", len(results))
+	}
+}
+
+func TestCreateIncident_Deduplication(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	service := NewIncidentService()
+	service.dedup = DedupConfig{Window: time.Hour}
+
+	first, err := service.CreateIncident(model.Incident{
+		Title:       "Zebra API latency above 900ms on host 12",
+		Description: "p99 latency alert",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+	if first.Deduplicated || first.OccurrenceCount != 1 || first.Fingerprint == "" {
+		t.Fatalf("Expected a new incident with one occurrence, got %+v", first)
+	}
+
+	// The same alert differing only in case and whitespace is a repeat
+	repeat, err := service.CreateIncident(model.Incident{
+		Title:       "zebra API  latency above 900ms on host 12 ",
+		Description: "p99 latency alert",
+		Priority:    "high",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !repeat.Deduplicated || repeat.ID != first.ID || repeat.OccurrenceCount != 2 {
+		t.Fatalf("Expected the report to be attached to %s, got %+v", first.ID, repeat)
+	}
+	occurrences, err := service.GetOccurrences(first.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(occurrences) != 1 || occurrences[0].Priority != "high" {
+		t.Errorf("Expected one recorded occurrence, got %+v", occurrences)
+	}
+
+	// The same alert for another host is a different outage
+	otherHost, err := service.CreateIncident(model.Incident{
+		Title:       "Zebra API latency above 900ms on host 14",
+		Description: "p99 latency alert",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if otherHost.Deduplicated || otherHost.ID == first.ID {
+		t.Errorf("Expected a separate incident for another host, got %+v", otherHost)
+	}
+
+	// An explicit dedup key matches regardless of the title
+	keyed, err := service.CreateIncident(model.Incident{Title: "Zebra disk alert", Description: "Disk full", DedupKey: "alert-zebra-1"})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+	sameKey, err := service.CreateIncident(model.Incident{Title: "Zebra disk almost full", Description: "Disk full", DedupKey: "alert-zebra-1"})
+	if err != nil || sameKey.ID != keyed.ID {
+		t.Errorf("Expected reports with the same dedup key to be grouped, got %+v %v", sameKey, err)
+	}
+
+	// Resolved incidents do not absorb new reports
+	if _, err := service.TransitionIncident(first.ID, "resolve", "", "tester"); err != nil {
+		t.Fatalf("Failed to resolve incident: %v", err)
+	}
+	afterResolve, err := service.CreateIncident(model.Incident{Title: "Zebra API latency above 900ms on host 12", Description: "p99 latency alert"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if afterResolve.Deduplicated || afterResolve.ID == first.ID {
+		t.Errorf("Expected a new incident after the first was resolved")
+	}
+
+	// Reports outside the window start a new incident
+	service.dedup = DedupConfig{Window: time.Nanosecond}
+	time.Sleep(time.Millisecond)
+	late, err := service.CreateIncident(model.Incident{Title: "Zebra disk alert", Description: "Disk full", DedupKey: "alert-zebra-1"})
+	if err != nil || late.Deduplicated {
+		t.Errorf("Expected a new incident outside the dedup window, got %+v %v", late, err)
+	}
+
+	// Editing an incident keeps its dedup state
+	updated, err := service.PatchIncident(keyed.ID, []byte(`{"title": "Zebra disk full", "occurrence_count": 99}`), "tester")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.OccurrenceCount != 2 || updated.DedupKey != "alert-zebra-1" || updated.Fingerprint != keyed.Fingerprint {
+		t.Errorf("Expected the dedup state to be kept, got %+v", updated)
+	}
+
+	// Reports matching an edited title are attached to the edited incident
+	service.dedup = DedupConfig{Window: time.Hour}
+	if _, err := service.PatchIncident(otherHost.ID, []byte(`{"title": "Zebra API latency above 900ms on host 14 (eu-west)"}`), "tester"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	retitled, err := service.CreateIncident(model.Incident{Title: "Zebra API latency above 900ms on host 14 (eu-west)", Description: "p99 latency alert"})
+	if err != nil || !retitled.Deduplicated || retitled.ID != otherHost.ID {
+		t.Errorf("Expected the report to match the edited title, got %+v %v", retitled, err)
+	}
+
+	// Concurrent reports of a new incident create it once
+	const reports = 8
+	var wg sync.WaitGroup
+	ids := make(chan string, reports)
+	for i := 0; i < reports; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stored, err := service.CreateIncident(model.Incident{Title: "Zebra queue stalled", Description: "Consumer lag alert"})
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			ids <- stored.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	distinct := map[string]bool{}
+	for id := range ids {
+		distinct[id] = true
+	}
+	if len(distinct) != 1 {
+		t.Fatalf("Expected concurrent reports to share one incident, got %d", len(distinct))
+	}
+	for id := range distinct {
+		stalled, err := service.GetIncident(id)
+		if err != nil || stalled.OccurrenceCount != reports {
+			t.Errorf("Expected %d occurrences, got %+v %v", reports, stalled, err)
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Clean up test database before running tests
 	os.Remove("incidents.db")
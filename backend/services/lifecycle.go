@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownAction is returned when a transition action is not defined by the lifecycle
+var ErrUnknownAction = errors.New("unknown transition action")
+
+// Transition describes an action that moves an incident between statuses
+type Transition struct {
+	Action string
+	From   []string
+	To     string
+	// ExplicitOnly transitions can only be performed through the transitions endpoint,
+	// never implicitly by editing the status field
+	ExplicitOnly bool
+}
+
+// TransitionError is returned when a transition is not allowed from the current status
+type TransitionError struct {
+	Action         string
+	From           string
+	To             string
+	AllowedActions []string
+}
+
+func (e *TransitionError) Error() string {
+	allowed := "none"
+	if len(e.AllowedActions) > 0 {
+		allowed = strings.Join(e.AllowedActions, ", ")
+	}
+	if e.Action != "" {
+		return fmt.Sprintf("action '%s' is not allowed from status '%s' (allowed actions: %s)", e.Action, e.From, allowed)
+	}
+	return fmt.Sprintf("status cannot change from '%s' to '%s' directly (allowed actions: %s)", e.From, e.To, allowed)
+}
+
+// Lifecycle is the state machine governing incident status changes
+type Lifecycle struct {
+	transitions []Transition
+}
+
+// NewLifecycle creates a lifecycle from a set of transitions
+func NewLifecycle(transitions []Transition) *Lifecycle {
+	return &Lifecycle{transitions: transitions}
+}
+
+// DefaultLifecycle returns the standard incident lifecycle:
+// open -> in_progress -> resolved -> closed, with reopen as the only way back
+func DefaultLifecycle() *Lifecycle {
+	return NewLifecycle([]Transition{
+		{Action: "start", From: []string{"open"}, To: "in_progress"},
+		{Action: "stop", From: []string{"in_progress"}, To: "open"},
+		{Action: "resolve", From: []string{"open", "in_progress"}, To: "resolved"},
+		{Action: "close", From: []string{"resolved"}, To: "closed"},
+		{Action: "reopen", From: []string{"resolved", "closed"}, To: "open", ExplicitOnly: true},
+	})
+}
+
+// Actions returns the names of all actions defined by the lifecycle
+func (l *Lifecycle) Actions() []string {
+	actions := make([]string, 0, len(l.transitions))
+	for _, transition := range l.transitions {
+		actions = append(actions, transition.Action)
+	}
+	return actions
+}
+
+// AllowedActions returns the actions that can be performed from the given status
+func (l *Lifecycle) AllowedActions(from string) []string {
+	var actions []string
+	for _, transition := range l.transitions {
+		if transition.allowsFrom(from) {
+			actions = append(actions, transition.Action)
+		}
+	}
+	return actions
+}
+
+// CheckAction validates that an action can be performed from the given status
+func (l *Lifecycle) CheckAction(from, action string) (*Transition, error) {
+	for i := range l.transitions {
+		transition := &l.transitions[i]
+		if transition.Action != action {
+			continue
+		}
+		if !transition.allowsFrom(from) {
+			return nil, &TransitionError{
+				Action:         action,
+				From:           from,
+				To:             transition.To,
+				AllowedActions: l.AllowedActions(from),
+			}
+		}
+		return transition, nil
+	}
+	return nil, fmt.Errorf("%w: '%s' (valid actions: %s)", ErrUnknownAction, action, strings.Join(l.Actions(), ", "))
+}
+
+// CheckStatusChange validates an implicit status change made by editing the incident.
+// Explicit-only transitions such as reopen are rejected here.
+func (l *Lifecycle) CheckStatusChange(from, to string) (*Transition, error) {
+	for i := range l.transitions {
+		transition := &l.transitions[i]
+		if transition.To == to && transition.allowsFrom(from) && !transition.ExplicitOnly {
+			return transition, nil
+		}
+	}
+	return nil, &TransitionError{
+		From:           from,
+		To:             to,
+		AllowedActions: l.AllowedActions(from),
+	}
+}
+
+// allowsFrom reports whether the transition can start from the given status
+func (t *Transition) allowsFrom(status string) bool {
+	for _, from := range t.From {
+		if from == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLifecycle_CheckAction(t *testing.T) {
+	lifecycle := DefaultLifecycle()
+
+	tests := []struct {
+		name        string
+		from        string
+		action      string
+		expectedTo  string
+		expectError bool
+	}{
+		{"Start open incident", "open", "start", "in_progress", false},
+		{"Resolve in progress incident", "in_progress", "resolve", "resolved", false},
+		{"Close resolved incident", "resolved", "close", "closed", false},
+		{"Reopen closed incident", "closed", "reopen", "open", false},
+		{"Close open incident", "open", "close", "", true},
+		{"Reopen open incident", "open", "reopen", "", true},
+		{"Start closed incident", "closed", "start", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transition, err := lifecycle.CheckAction(tt.from, tt.action)
+			if tt.expectError {
+				var transitionErr *TransitionError
+				if !errors.As(err, &transitionErr) {
+					t.Fatalf("Expected TransitionError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if transition.To != tt.expectedTo {
+				t.Errorf("Expected target status '%s', got '%s'", tt.expectedTo, transition.To)
+			}
+		})
+	}
+}
+
+func TestLifecycle_UnknownAction(t *testing.T) {
+	_, err := DefaultLifecycle().CheckAction("open", "escalate")
+	if !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("Expected ErrUnknownAction, got %v", err)
+	}
+}
+
+func TestLifecycle_CheckStatusChange(t *testing.T) {
+	lifecycle := DefaultLifecycle()
+
+	if _, err := lifecycle.CheckStatusChange("open", "resolved"); err != nil {
+		t.Errorf("Expected open -> resolved to be allowed, got %v", err)
+	}
+
+	// Reopening must go through the explicit reopen action
+	if _, err := lifecycle.CheckStatusChange("closed", "open"); err == nil {
+		t.Error("Expected closed -> open to be rejected as an implicit change")
+	}
+
+	if _, err := lifecycle.CheckStatusChange("open", "closed"); err == nil {
+		t.Error("Expected open -> closed to be rejected")
+	}
+}
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var (
+	// ErrNoPostmortem is returned when an incident has no postmortem draft yet
+	ErrNoPostmortem = errors.New("incident has no postmortem yet")
+	// ErrIncidentNotResolved is returned when a postmortem is requested for an incident that is still active
+	ErrIncidentNotResolved = errors.New("postmortems can only be drafted for resolved or closed incidents")
+)
+
+// postmortemPlaceholder marks a section the incident owner must complete
+const postmortemPlaceholder = "To be completed by the incident owner."
+
+// PostmortemWriter drafts postmortems of resolved incidents
+type PostmortemWriter interface {
+	// Name identifies the provider behind the writer
+	Name() string
+	// DraftPostmortem writes the sections of a postmortem from an incident and its history
+	DraftPostmortem(ctx context.Context, input SummaryInput) (*PostmortemResult, error)
+}
+
+// PostmortemSections are the parts of a postmortem document
+type PostmortemSections struct {
+	Summary             string   `json:"summary"`
+	Impact              string   `json:"impact"`
+	Timeline            []string `json:"timeline"`
+	ContributingFactors []string `json:"contributing_factors"`
+	ActionItems         []string `json:"action_items"`
+}
+
+// PostmortemResult is a drafted postmortem
+type PostmortemResult struct {
+	Sections      PostmortemSections `json:"sections"`
+	Provider      string             `json:"provider"`
+	Model         string             `json:"model,omitempty"`
+	PromptVersion string             `json:"prompt_version,omitempty"`
+	Usage         openai.Usage       `json:"usage"`
+	// Fallback is set when the template-only writer stood in for the configured provider
+	Fallback       bool   `json:"fallback"`
+	FallbackReason string `json:"fallback_reason,omitempty"`
+	// Redactions counts the values removed from the text sent to the provider
+	Redactions RedactionReport `json:"redactions,omitempty"`
+}
+
+// PostmortemPromptData is the data available to postmortem prompt templates
+type PostmortemPromptData struct {
+	SummaryPromptData
+	// ResolvedAt and Duration are empty when the history does not record the resolution
+	ResolvedAt string
+	Duration   string
+	// Occurrences is the number of times the incident was reported
+	Occurrences int
+}
+
+// postmortemOutput is the postmortem the model must return
+type postmortemOutput struct {
+	Summary             *string  `json:"summary"`
+	Impact              *string  `json:"impact"`
+	Timeline            []string `json:"timeline"`
+	ContributingFactors []string `json:"contributing_factors"`
+	ActionItems         []string `json:"action_items"`
+}
+
+// newPostmortemPromptData gathers the prompt data of a resolved incident
+func newPostmortemPromptData(input SummaryInput) PostmortemPromptData {
+	data := PostmortemPromptData{
+		SummaryPromptData: newSummaryPromptData(input),
+		Occurrences:       input.Incident.OccurrenceCount,
+	}
+	if resolvedAt, ok := resolutionTime(input.Transitions); ok {
+		data.ResolvedAt = formatTimelineTime(resolvedAt)
+		data.Duration = formatIncidentDuration(resolvedAt.Sub(input.Incident.CreatedAt))
+	}
+	return data
+}
+
+// resolutionTime returns when an incident was last resolved, according to its transitions
+func resolutionTime(transitions []model.StatusTransition) (time.Time, bool) {
+	var resolvedAt time.Time
+	found := false
+	for _, transition := range transitions {
+		if transition.ToStatus == "resolved" {
+			resolvedAt, found = transition.CreatedAt, true
+		}
+	}
+	return resolvedAt, found
+}
+
+// formatIncidentDuration formats a duration in days, hours and minutes, e.g. 1d 2h 5m
+func formatIncidentDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 1 {
+		return "less than a minute"
+	}
+	var parts []string
+	if days := minutes / (24 * 60); days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours := minutes / 60 % 24; hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes%60 > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes%60))
+	}
+	return strings.Join(parts, " ")
+}
+
+// RenderPostmortemMarkdown renders the sections of a postmortem as a Markdown document
+func RenderPostmortemMarkdown(incident model.Incident, sections PostmortemSections) string {
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "# Postmortem: %s\n\n", incident.Title)
+	fmt.Fprintf(&doc, "**Status:** %s | **Priority:** %s | **Opened:** %s\n\n",
+		incident.Status, incident.Priority, formatTimelineTime(incident.CreatedAt))
+
+	fmt.Fprintf(&doc, "## Summary\n\n%s\n\n", sections.Summary)
+	fmt.Fprintf(&doc, "## Impact\n\n%s\n\n", sections.Impact)
+	writeMarkdownList(&doc, "Timeline", "- ", sections.Timeline)
+	writeMarkdownList(&doc, "Contributing Factors", "- ", sections.ContributingFactors)
+	writeMarkdownList(&doc, "Action Items", "- [ ] ", sections.ActionItems)
+	return strings.TrimRight(doc.String(), "\n") + "\n"
+}
+
+// writeMarkdownList writes a section of list items, or the placeholder when there are none
+func writeMarkdownList(doc *strings.Builder, heading, bullet string, items []string) {
+	fmt.Fprintf(doc, "## %s\n\n", heading)
+	if len(items) == 0 {
+		fmt.Fprintf(doc, "%s\n\n", postmortemPlaceholder)
+		return
+	}
+	for _, item := range items {
+		fmt.Fprintf(doc, "%s%s\n", bullet, item)
+	}
+	doc.WriteString("\n")
+}
+
+// LocalPostmortemWriter fills the postmortem sections from the incident fields and history
+// without a model. It is deterministic, so the same history always gives the same draft.
+// It is the offline mode and fallback of the LLM providers.
+type LocalPostmortemWriter struct{}
+
+// NewLocalPostmortemWriter creates the built-in template-only postmortem writer
+func NewLocalPostmortemWriter() *LocalPostmortemWriter {
+	return &LocalPostmortemWriter{}
+}
+
+// Name identifies the local postmortem writer
+func (l *LocalPostmortemWriter) Name() string {
+	return ProviderLocal
+}
+
+// DraftPostmortem restates the incident and its history; the contributing factors are the
+// reasons given when it was resolved. Action items are left for the incident owner.
+func (l *LocalPostmortemWriter) DraftPostmortem(ctx context.Context, input SummaryInput) (*PostmortemResult, error) {
+	data := newPostmortemPromptData(input)
+
+	summary := strings.TrimRight(data.Title, ".") + "."
+	if lead := leadSentences(data.Description, 2, 300); lead != "" {
+		summary += " " + lead
+	}
+	if data.Duration != "" {
+		summary += fmt.Sprintf(" The incident was resolved after %s.", data.Duration)
+	}
+
+	impact := fmt.Sprintf("A %s priority incident", data.Priority)
+	if data.Severity != "" && data.Category != "" {
+		impact = fmt.Sprintf("A %s priority, %s severity %s incident", data.Priority, data.Severity, data.Category)
+	}
+	if data.Duration != "" {
+		impact += fmt.Sprintf(", active for %s", data.Duration)
+	}
+	impact += "."
+	if data.Occurrences > 1 {
+		impact += fmt.Sprintf(" It was reported %d times.", data.Occurrences)
+	}
+
+	timeline := []string{data.OpenedAt + ": opened"}
+	for _, event := range data.Events {
+		timeline = append(timeline, event.At+": "+event.Text)
+	}
+
+	return &PostmortemResult{
+		Sections: PostmortemSections{
+			Summary:             summary,
+			Impact:              impact,
+			Timeline:            timeline,
+			ContributingFactors: resolutionReasons(input.Transitions),
+			ActionItems:         []string{},
+		},
+		Provider: ProviderLocal,
+	}, nil
+}
+
+// DraftPostmortem drafts a postmortem with the chat completion API. The template-only
+// writer is used when no API key is configured, the monthly budget is spent, the
+// provider fails or its circuit breaker is open.
+func (s *AIService) DraftPostmortem(ctx context.Context, input SummaryInput) (*PostmortemResult, error) {
+	return callModel(ctx, s, modelTask[*PostmortemResult]{
+		operation: OperationPostmortem,
+		noun:      "postmortem",
+		prompt: func(redaction *RedactionScope) (string, error) {
+			data := newPostmortemPromptData(input)
+			data.redact(redaction)
+			return s.postmortemPrompt.Render(data)
+		},
+		request: func(prompt string) openai.ChatCompletionRequest {
+			return s.jsonRequest(prompt, 0.3)
+		},
+		parse: func(message openai.ChatCompletionMessage) (*PostmortemResult, error) {
+			return parsePostmortemOutput(message.Content)
+		},
+		answered: func(result *PostmortemResult, answer modelAnswer) {
+			result.Provider = answer.Provider
+			result.Model = answer.Model
+			result.PromptVersion = s.postmortemPrompt.Version
+			result.Usage = answer.Usage
+			result.Redactions = answer.Redactions
+		},
+		fallback: func(ctx context.Context, cause error) (*PostmortemResult, error) {
+			return s.postmortemWithFallback(ctx, input, cause)
+		},
+	})
+}
+
+// postmortemWithFallback drafts with the template-only writer
+func (s *AIService) postmortemWithFallback(ctx context.Context, input SummaryInput, cause error) (*PostmortemResult, error) {
+	result, err := NewLocalPostmortemWriter().DraftPostmortem(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Fallback = true
+	result.FallbackReason = cause.Error()
+	return result, nil
+}
+
+// parsePostmortemOutput strictly decodes a postmortem answer
+func parsePostmortemOutput(raw string) (*PostmortemResult, error) {
+	var output postmortemOutput
+	if err := decodeModelJSON(raw, &output); err != nil {
+		return nil, err
+	}
+	if output.Summary == nil || strings.TrimSpace(*output.Summary) == "" {
+		return nil, fmt.Errorf("%w: summary is missing", ErrInvalidModelOutput)
+	}
+	if output.Impact == nil || strings.TrimSpace(*output.Impact) == "" {
+		return nil, fmt.Errorf("%w: impact is missing", ErrInvalidModelOutput)
+	}
+
+	return &PostmortemResult{Sections: PostmortemSections{
+		Summary:             strings.TrimSpace(*output.Summary),
+		Impact:              strings.TrimSpace(*output.Impact),
+		Timeline:            nonEmptyLines(output.Timeline),
+		ContributingFactors: nonEmptyLines(output.ContributingFactors),
+		ActionItems:         nonEmptyLines(output.ActionItems),
+	}}, nil
+}
+
+// nonEmptyLines returns the trimmed lines that are not blank
+func nonEmptyLines(lines []string) []string {
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// NewPostmortemWriter creates the postmortem writer of the configured provider. The rules
+// provider has no language model, so it is served by the template-only writer.
+func NewPostmortemWriter(config ClassifierConfig) (PostmortemWriter, error) {
+	if config.Provider == ProviderRules {
+		return NewLocalPostmortemWriter(), nil
+	}
+	// Postmortems never classify, so the service needs no fallback classifier
+	return newAIServiceFromConfig(config, nil)
+}
+
+// NewPostmortemWriterFromEnv creates the configured postmortem writer, falling back to
+// the template-only writer when the configuration is invalid
+func NewPostmortemWriterFromEnv() PostmortemWriter {
+	writer, err := NewPostmortemWriter(ClassifierConfigFromEnv())
+	if err != nil {
+		log.Println("Invalid AI postmortem configuration, using template-only writer:", err)
+		return NewLocalPostmortemWriter()
+	}
+	return writer
+}
+
+// PostmortemService drafts and stores versioned postmortems of resolved incidents
+type PostmortemService struct {
+	incidents   *repository.IncidentRepository
+	transitions *repository.TransitionRepository
+	comments    *repository.CommentRepository
+	postmortems *repository.PostmortemRepository
+	writer      PostmortemWriter
+}
+
+// NewPostmortemService creates a new postmortem service
+func NewPostmortemService(writer PostmortemWriter) *PostmortemService {
+	return &PostmortemService{
+		incidents:   repository.NewIncidentRepository(),
+		transitions: repository.NewTransitionRepository(),
+		comments:    repository.NewCommentRepository(),
+		postmortems: repository.NewPostmortemRepository(),
+		writer:      writer,
+	}
+}
+
+// DraftPostmortem writes a new postmortem version of a resolved or closed incident
+func (s *PostmortemService) DraftPostmortem(ctx context.Context, incidentID, actor string) (*model.IncidentPostmortem, error) {
+	incident, err := s.incidents.GetByID(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(resolvedStatuses, incident.Status) {
+		return nil, ErrIncidentNotResolved
+	}
+	transitions, err := s.transitions.ListByIncident(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.comments.ListByIncident(incidentID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.writer.DraftPostmortem(ctx, SummaryInput{Incident: *incident, Transitions: transitions, Comments: comments})
+	if err != nil {
+		return nil, err
+	}
+
+	postmortem := newPostmortemRecord(incident, actor, result)
+	if err := s.postmortems.Create(postmortem); err != nil {
+		return nil, err
+	}
+	return postmortem, nil
+}
+
+// GetPostmortem retrieves a postmortem version of an incident; version 0 is the latest
+func (s *PostmortemService) GetPostmortem(incidentID string, version int) (*model.IncidentPostmortem, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+
+	var postmortem *model.IncidentPostmortem
+	var err error
+	if version > 0 {
+		postmortem, err = s.postmortems.GetVersion(incidentID, version)
+	} else {
+		postmortem, err = s.postmortems.GetLatest(incidentID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNoPostmortem
+	}
+	return postmortem, err
+}
+
+// GetPostmortems retrieves every postmortem version of an incident, newest first
+func (s *PostmortemService) GetPostmortems(incidentID string) ([]model.IncidentPostmortem, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+	return s.postmortems.ListByIncident(incidentID)
+}
+
+// newPostmortemRecord converts a postmortem result into a stored version
+func newPostmortemRecord(incident *model.Incident, actor string, result *PostmortemResult) *model.IncidentPostmortem {
+	sections, _ := json.Marshal(result.Sections)
+	postmortem := &model.IncidentPostmortem{
+		IncidentID:       incident.ID,
+		Markdown:         RenderPostmortemMarkdown(*incident, result.Sections),
+		Sections:         sections,
+		Provider:         result.Provider,
+		Model:            result.Model,
+		PromptVersion:    result.PromptVersion,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		Fallback:         result.Fallback,
+		FallbackReason:   result.FallbackReason,
+		Actor:            actor,
+	}
+	if len(result.Redactions) > 0 {
+		postmortem.Redactions, _ = json.Marshal(result.Redactions)
+	}
+	return postmortem
+}
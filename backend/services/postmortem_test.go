@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalPostmortemWriter(t *testing.T) {
+	opened := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	input := SummaryInput{
+		Incident: model.Incident{
+			Title:           "Checkout API returning 500s",
+			Description:     "Payments fail for all EU customers.",
+			Status:          "resolved",
+			Priority:        "high",
+			AISeverity:      "high",
+			AICategory:      "software",
+			OccurrenceCount: 3,
+			CreatedAt:       opened,
+		},
+		Transitions: []model.StatusTransition{
+			{Action: "start", FromStatus: "open", ToStatus: "in_progress", Actor: "alice", CreatedAt: opened.Add(10 * time.Minute)},
+			{Action: "resolve", FromStatus: "in_progress", ToStatus: "resolved", Reason: "Rolled back release 42", Actor: "alice", CreatedAt: opened.Add(135 * time.Minute)},
+		},
+	}
+
+	result, err := NewLocalPostmortemWriter().DraftPostmortem(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sections := result.Sections
+	if !strings.Contains(sections.Summary, "resolved after 2h 15m") {
+		t.Errorf("Expected the time to resolve in the summary, got %q", sections.Summary)
+	}
+	if sections.Impact != "A high priority, high severity software incident, active for 2h 15m. It was reported 3 times." {
+		t.Errorf("Unexpected impact %q", sections.Impact)
+	}
+	if len(sections.Timeline) != 3 || len(sections.ContributingFactors) != 1 || sections.ContributingFactors[0] != "Rolled back release 42" {
+		t.Errorf("Unexpected sections %+v", sections)
+	}
+
+	markdown := RenderPostmortemMarkdown(input.Incident, sections)
+	for _, want := range []string{"# Postmortem: Checkout API returning 500s", "## Impact", "## Timeline\n\n- 2024-03-01 09:00 UTC: opened", "## Action Items\n\n" + postmortemPlaceholder} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+
+	// The template-only mode is deterministic
+	again, _ := NewLocalPostmortemWriter().DraftPostmortem(context.Background(), input)
+	if RenderPostmortemMarkdown(input.Incident, again.Sections) != markdown {
+		t.Error("Expected the same draft for the same history")
+	}
+}
+
+func TestAIServiceDraftPostmortem(t *testing.T) {
+	server := newFakeChatServer(t, `{"summary": "Payments failed after a release.", "impact": "EU checkout was down for two hours.", "timeline": ["09:00 release"], "contributing_factors": ["No canary"], "action_items": ["Add a canary stage", " "]}`)
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+
+	result, err := aiService.DraftPostmortem(context.Background(), SummaryInput{Incident: model.Incident{Title: "Checkout down", Status: "resolved"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fallback {
+		t.Fatalf("Expected model draft, got fallback: %s", result.FallbackReason)
+	}
+	if result.PromptVersion != DefaultPostmortemPromptVersion || result.Usage.TotalTokens != 150 {
+		t.Errorf("Unexpected provenance %+v", result)
+	}
+	if len(result.Sections.ActionItems) != 1 || result.Sections.Impact != "EU checkout was down for two hours." {
+		t.Errorf("Unexpected sections %+v", result.Sections)
+	}
+
+	// A draft without an impact does not match the schema
+	invalid := newFakeChatServer(t, `{"summary": "Payments failed."}`)
+	aiService = NewOpenAICompatibleService(invalid.URL+"/v1", "", "test-model")
+	result, err = aiService.DraftPostmortem(context.Background(), SummaryInput{Incident: model.Incident{Title: "Checkout down", Status: "resolved"}})
+	if err != nil {
+		t.Fatalf("Expected fallback draft, got error %v", err)
+	}
+	if !result.Fallback || result.Provider != ProviderLocal {
+		t.Errorf("Expected local fallback, got provider %s fallback %v", result.Provider, result.Fallback)
+	}
+}
+
+func TestDraftPostmortem_Versions(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	incidents := NewIncidentService()
+	incident, err := incidents.CreateIncident(model.Incident{
+		Title:       "Postmortem versions incident",
+		Description: "Message broker disk full",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	service := NewPostmortemService(NewLocalPostmortemWriter())
+	if _, err := service.DraftPostmortem(context.Background(), incident.ID, "alice"); !errors.Is(err, ErrIncidentNotResolved) {
+		t.Fatalf("Expected ErrIncidentNotResolved for an open incident, got %v", err)
+	}
+	if _, err := service.GetPostmortem(incident.ID, 0); !errors.Is(err, ErrNoPostmortem) {
+		t.Fatalf("Expected ErrNoPostmortem, got %v", err)
+	}
+
+	if _, err := incidents.TransitionIncident(incident.ID, "resolve", "Expanded the broker volume", "bob"); err != nil {
+		t.Fatalf("Failed to resolve incident: %v", err)
+	}
+	first, err := service.DraftPostmortem(context.Background(), incident.ID, "alice")
+	if err != nil {
+		t.Fatalf("Failed to draft postmortem: %v", err)
+	}
+	second, err := service.DraftPostmortem(context.Background(), incident.ID, "alice")
+	if err != nil {
+		t.Fatalf("Failed to redraft postmortem: %v", err)
+	}
+	if first.Version != 1 || second.Version != 2 {
+		t.Errorf("Expected versions 1 and 2, got %d and %d", first.Version, second.Version)
+	}
+	if !strings.Contains(first.Markdown, "- Expanded the broker volume") || first.Provider != ProviderLocal {
+		t.Errorf("Expected the resolution as a contributing factor, got:\n%s", first.Markdown)
+	}
+
+	latest, err := service.GetPostmortem(incident.ID, 0)
+	if err != nil || latest.Version != 2 {
+		t.Errorf("Expected latest version 2, got %v %v", latest, err)
+	}
+	postmortems, err := service.GetPostmortems(incident.ID)
+	if err != nil || len(postmortems) != 2 || postmortems[0].Version != 2 {
+		t.Errorf("Expected both versions newest first, got %v %v", postmortems, err)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts/*.tmpl
+var builtinPrompts embed.FS
+
+const (
+	// DefaultPromptVersion is the prompt used when a provider has no default of its own
+	DefaultPromptVersion = "classify-v3"
+	// DefaultSummaryPromptVersion is the prompt used to write incident summaries
+	DefaultSummaryPromptVersion = "summarize-v1"
+	// DefaultRemediationPromptVersion is the prompt used to suggest remediations
+	DefaultRemediationPromptVersion = "remediate-v1"
+	// DefaultPostmortemPromptVersion is the prompt used to draft postmortems
+	DefaultPostmortemPromptVersion = "postmortem-v1"
+	// DefaultFewShotExamples is the number of human-corrected incidents included in prompts
+	DefaultFewShotExamples = 3
+)
+
+// defaultPromptVersions selects the prompt of each provider unless AI_PROMPT_VERSION is set
+var defaultPromptVersions = map[string]string{
+	ProviderOpenAI:           "classify-v3",
+	ProviderOpenAICompatible: "classify-compact-v1",
+}
+
+// PromptTemplate is a versioned text/template that renders a prompt. The version is the
+// template's file name without the .tmpl extension; its prefix selects the kind of prompt.
+type PromptTemplate struct {
+	Version  string
+	template *template.Template
+}
+
+// PromptData is the data available to classification prompt templates
+type PromptData struct {
+	Title       string
+	Description string
+	Severities  []model.TaxonomyTerm
+	Categories  []model.TaxonomyTerm
+	// Examples are recent human-corrected incidents for few-shot prompting
+	Examples []FeedbackExample
+}
+
+// promptFuncs are the helper functions available to prompt templates
+var promptFuncs = template.FuncMap{
+	"join": strings.Join,
+	// values returns the values of taxonomy terms
+	"values": func(terms []model.TaxonomyTerm) []string {
+		values := make([]string, len(terms))
+		for i, term := range terms {
+			values[i] = term.Value
+		}
+		return values
+	},
+	// choices formats taxonomy terms as "a", "b", or "c"
+	"choices": func(terms []model.TaxonomyTerm) string {
+		values := make([]string, len(terms))
+		for i, term := range terms {
+			values[i] = term.Value
+		}
+		return quoteChoices(values)
+	},
+}
+
+// ParsePromptTemplate parses a prompt template with the given version
+func ParsePromptTemplate(version, text string) (*PromptTemplate, error) {
+	tmpl, err := template.New(version).Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	prompt := &PromptTemplate{Version: version, template: tmpl}
+
+	// Render sample data so broken templates fail at startup rather than on the first incident
+	if _, err := prompt.Render(promptSample(version)); err != nil {
+		return nil, err
+	}
+	return prompt, nil
+}
+
+// promptSample returns sample data of the kind of prompt the version names
+func promptSample(version string) interface{} {
+	summary := SummaryPromptData{
+		Title:       "Sample",
+		Description: "Sample",
+		Status:      "open",
+		Priority:    "medium",
+		Severity:    "low",
+		Category:    "network",
+		OpenedAt:    "2024-01-01 00:00 UTC",
+		Events:      []TimelineEvent{{At: "2024-01-01 00:05 UTC", Text: "Example"}},
+	}
+	if strings.HasPrefix(version, "summarize-") {
+		return summary
+	}
+	if strings.HasPrefix(version, "postmortem-") {
+		summary.Status = "resolved"
+		return PostmortemPromptData{SummaryPromptData: summary, ResolvedAt: "2024-01-01 01:00 UTC", Duration: "1h", Occurrences: 2}
+	}
+	if strings.HasPrefix(version, "remediate-") {
+		return RemediationPromptData{
+			Title:       "Sample",
+			Description: "Sample",
+			Status:      "open",
+			Severity:    "low",
+			Category:    "network",
+			Past:        []PastIncidentPromptData{{Title: "Example", Description: "Example", Category: "network", Similarity: "0.80", Resolution: "Example"}},
+		}
+	}
+
+	taxonomy := model.CurrentTaxonomy()
+	return PromptData{
+		Title:       "Sample",
+		Description: "Sample",
+		Severities:  taxonomy.Severities,
+		Categories:  taxonomy.Categories,
+		Examples:    []FeedbackExample{{Title: "Example", Description: "Example", Severity: "low", Category: "network"}},
+	}
+}
+
+// LoadPromptTemplate loads a prompt version from dir, falling back to the built-in prompts
+func LoadPromptTemplate(version, dir string) (*PromptTemplate, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, version+".tmpl"))
+		if err == nil {
+			return ParsePromptTemplate(version, string(data))
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	data, err := builtinPrompts.ReadFile("prompts/" + version + ".tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("unknown prompt version '%s' (available: %s)", version, strings.Join(PromptVersions(dir), ", "))
+	}
+	return ParsePromptTemplate(version, string(data))
+}
+
+// PromptVersions lists the built-in prompt versions and those found in dir
+func PromptVersions(dir string) []string {
+	seen := make(map[string]bool)
+	entries, _ := builtinPrompts.ReadDir("prompts")
+	for _, entry := range entries {
+		seen[strings.TrimSuffix(entry.Name(), ".tmpl")] = true
+	}
+	if dir != "" {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		for _, match := range matches {
+			seen[strings.TrimSuffix(filepath.Base(match), ".tmpl")] = true
+		}
+	}
+
+	versions := make([]string, 0, len(seen))
+	for version := range seen {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// defaultPrompt returns the built-in default classification prompt of a provider
+func defaultPrompt(provider string) *PromptTemplate {
+	version, ok := defaultPromptVersions[provider]
+	if !ok {
+		version = DefaultPromptVersion
+	}
+	return builtinPrompt(version)
+}
+
+// builtinPrompt returns a built-in prompt, which must be valid
+func builtinPrompt(version string) *PromptTemplate {
+	prompt, err := LoadPromptTemplate(version, "")
+	if err != nil {
+		panic(fmt.Sprintf("built-in prompt %s is invalid: %v", version, err))
+	}
+	return prompt
+}
+
+// Render executes the template with the given data
+func (p *PromptTemplate) Render(data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := p.template.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %v", p.Version, err)
+	}
+	return buf.String(), nil
+}
+
+// recentFeedbackExamples returns the most recently corrected incidents for few-shot prompting,
+// or none when no database is available
+func recentFeedbackExamples(limit int) ([]FeedbackExample, error) {
+	if limit <= 0 || database.GetDB() == nil {
+		return nil, nil
+	}
+	incidents, err := repository.NewOverrideRepository().ListRecentlyOverridden(limit)
+	if err != nil {
+		return nil, err
+	}
+	examples := make([]FeedbackExample, 0, len(incidents))
+	for i := range incidents {
+		examples = append(examples, newFeedbackExample(&incidents[i]))
+	}
+	return examples, nil
+}
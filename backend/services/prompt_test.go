@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"incident-management/model"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPromptTemplate(t *testing.T) {
+	for _, version := range PromptVersions("") {
+		if _, err := LoadPromptTemplate(version, ""); err != nil {
+			t.Errorf("Expected built-in prompt %s to load, got %v", version, err)
+		}
+	}
+
+	if _, err := LoadPromptTemplate("classify-v0", ""); err == nil || !strings.Contains(err.Error(), DefaultPromptVersion) {
+		t.Errorf("Expected an unknown version error listing the available prompts, got %v", err)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "custom-v1.tmpl"), []byte(`Pick one of {{ join (values .Categories) "/" }} for {{ .Title }}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "broken-v1.tmpl"), []byte(`{{ .Unknown }}`), 0o644)
+
+	prompt, err := LoadPromptTemplate("custom-v1", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if prompt.Version != "custom-v1" {
+		t.Errorf("Expected version custom-v1, got %s", prompt.Version)
+	}
+	rendered, _ := prompt.Render(PromptData{Title: "Router down", Categories: model.CurrentTaxonomy().Categories})
+	if rendered != "Pick one of network/software/hardware/security for Router down" {
+		t.Errorf("Unexpected rendered prompt: %q", rendered)
+	}
+
+	// Templates that fail to render are rejected when loaded
+	if _, err := LoadPromptTemplate("broken-v1", dir); err == nil {
+		t.Error("Expected an error for a template referencing an unknown field")
+	}
+}
+
+func TestClassify_RendersPromptWithFewShotExamples(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		prompt = request.Messages[0].Content
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": `{"severity": "low", "category": "hardware"}`}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	service := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	service.prompt, _ = LoadPromptTemplate("classify-v3", "")
+	service.fewShot = 1
+	service.examples = func(limit int) ([]FeedbackExample, error) {
+		return []FeedbackExample{
+			{Title: "Printer jammed", Description: "Paper stuck", Severity: "low", Category: "hardware"},
+			{Title: "Fan noise", Description: "Loud fan in rack 3", Severity: "low", Category: "hardware"},
+		}, nil
+	}
+
+	result, err := service.Classify(context.Background(), "Printer jammed", "Paper stuck")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.PromptVersion != "classify-v3" {
+		t.Errorf("Expected prompt version classify-v3, got %s", result.PromptVersion)
+	}
+
+	// The incident being classified is never its own example
+	if strings.Count(prompt, "Printer jammed") != 1 {
+		t.Errorf("Expected the incident to appear once, got prompt:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Incident Title: Fan noise") {
+		t.Errorf("Expected the few-shot example in the prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, `Choose from "low", "medium", or "high"`) {
+		t.Errorf("Expected the taxonomy in the prompt, got:\n%s", prompt)
+	}
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReclassifyRateLimit is the default number of classifier calls per second of a bulk job
+	DefaultReclassifyRateLimit = 2.0
+	// MaxReclassifyRateLimit caps the rate a bulk job may request
+	MaxReclassifyRateLimit = 50.0
+)
+
+// ErrJobNotRunning is returned when cancelling a bulk job that has already finished
+var ErrJobNotRunning = errors.New("reclassification job is not running")
+
+// ReclassifyOptions selects the incidents of a bulk reclassification and how it runs
+type ReclassifyOptions struct {
+	Filter repository.IncidentFilter
+	// DryRun classifies the incidents and counts changed labels without saving anything
+	DryRun bool
+	// RateLimit is the number of classifier calls per second; zero uses the default
+	RateLimit float64
+	Actor     string
+}
+
+// reclassifyFilter is the stored form of the incident filter of a bulk job
+type reclassifyFilter struct {
+	Status        []string   `json:"status,omitempty"`
+	Priority      []string   `json:"priority,omitempty"`
+	AISeverity    []string   `json:"ai_severity,omitempty"`
+	AICategory    []string   `json:"ai_category,omitempty"`
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+}
+
+// BulkReclassifier re-runs the classifier over filtered sets of incidents in the background
+type BulkReclassifier struct {
+	classification *ClassificationService
+	incidents      *repository.IncidentRepository
+	jobs           *repository.ReclassificationJobRepository
+	defaultRate    float64
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewBulkReclassifier creates a bulk reclassifier; defaultRate applies to jobs that do not set a rate limit
+func NewBulkReclassifier(classification *ClassificationService, defaultRate float64) *BulkReclassifier {
+	if defaultRate <= 0 {
+		defaultRate = DefaultReclassifyRateLimit
+	}
+	return &BulkReclassifier{
+		classification: classification,
+		incidents:      repository.NewIncidentRepository(),
+		jobs:           repository.NewReclassificationJobRepository(),
+		defaultRate:    defaultRate,
+		running:        make(map[string]context.CancelFunc),
+	}
+}
+
+// ReclassifyRateLimitFromEnv reads AI_RECLASSIFY_RATE_LIMIT, falling back to the default
+func ReclassifyRateLimitFromEnv() float64 {
+	if value, err := strconv.ParseFloat(os.Getenv("AI_RECLASSIFY_RATE_LIMIT"), 64); err == nil && value > 0 {
+		return value
+	}
+	return DefaultReclassifyRateLimit
+}
+
+// FailInterruptedReclassifications marks bulk jobs left unfinished by a previous process as failed
+func FailInterruptedReclassifications() error {
+	count, err := repository.NewReclassificationJobRepository().FailUnfinished("interrupted by a server restart")
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("Marked %d interrupted reclassification jobs as failed", count)
+	}
+	return nil
+}
+
+// Start validates the options, records a new bulk job and runs it in the background
+func (b *BulkReclassifier) Start(options ReclassifyOptions) (*model.ReclassificationJob, error) {
+	if options.RateLimit == 0 {
+		options.RateLimit = b.defaultRate
+	}
+	if options.RateLimit < 0 || options.RateLimit > MaxReclassifyRateLimit {
+		return nil, &ValidationError{Details: map[string]string{
+			"rate_limit": fmt.Sprintf("rate_limit must be between 0 and %g", MaxReclassifyRateLimit),
+		}}
+	}
+
+	filter := options.Filter
+	filter.SortBy = "created_at"
+	filter.SortDesc = false
+	filter.Limit = repository.MaxPageSize
+	filter.Cursor = ""
+	if validationErrors := filter.Validate(); validationErrors != nil {
+		return nil, &ValidationError{Details: validationErrors}
+	}
+
+	stored, err := json.Marshal(reclassifyFilter{
+		Status:        filter.Status,
+		Priority:      filter.Priority,
+		AISeverity:    filter.AISeverity,
+		AICategory:    filter.AICategory,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.ReclassificationJob{
+		Status:    model.ReclassificationQueued,
+		Filter:    stored,
+		DryRun:    options.DryRun,
+		RateLimit: options.RateLimit,
+		Actor:     options.Actor,
+	}
+	if err := b.jobs.Create(job); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.mu.Lock()
+	b.running[job.ID] = cancel
+	b.mu.Unlock()
+
+	snapshot := *job
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer b.finish(job.ID)
+		b.run(ctx, job, filter)
+	}()
+
+	return &snapshot, nil
+}
+
+// GetJob retrieves a bulk job with its progress
+func (b *BulkReclassifier) GetJob(id string) (*model.ReclassificationJob, error) {
+	return b.jobs.GetByID(id)
+}
+
+// ListJobs retrieves the most recent bulk jobs
+func (b *BulkReclassifier) ListJobs() ([]model.ReclassificationJob, error) {
+	return b.jobs.List(repository.DefaultPageSize)
+}
+
+// Cancel stops a running bulk job; incidents already reclassified keep their new labels
+func (b *BulkReclassifier) Cancel(id string) error {
+	if _, err := b.jobs.GetByID(id); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	cancel, ok := b.running[id]
+	b.mu.Unlock()
+	if !ok {
+		return ErrJobNotRunning
+	}
+	cancel()
+	return nil
+}
+
+// Wait blocks until every bulk job started by this reclassifier has finished
+func (b *BulkReclassifier) Wait() {
+	b.wg.Wait()
+}
+
+// finish releases the cancel function of a job once its goroutine exits
+func (b *BulkReclassifier) finish(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cancel, ok := b.running[id]; ok {
+		cancel()
+		delete(b.running, id)
+	}
+}
+
+// run pages through the matching incidents, classifying one per rate limit tick
+func (b *BulkReclassifier) run(ctx context.Context, job *model.ReclassificationJob, filter repository.IncidentFilter) {
+	started := time.Now()
+	job.Status = model.ReclassificationRunning
+	job.StartedAt = &started
+	b.save(job)
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / job.RateLimit))
+	defer ticker.Stop()
+
+	first := true
+	for {
+		page, err := b.incidents.List(filter)
+		if err != nil {
+			b.complete(job, model.ReclassificationFailed, err.Error())
+			return
+		}
+		if first {
+			job.Total = page.Total
+			first = false
+		}
+
+		for i := range page.Incidents {
+			select {
+			case <-ctx.Done():
+				b.complete(job, model.ReclassificationCancelled, "")
+				return
+			case <-ticker.C:
+			}
+
+			b.reclassify(ctx, job, &page.Incidents[i])
+			b.save(job)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	b.complete(job, model.ReclassificationCompleted, "")
+}
+
+// reclassify classifies a single incident and updates the job counters
+func (b *BulkReclassifier) reclassify(ctx context.Context, job *model.ReclassificationJob, incident *model.Incident) {
+	job.Processed++
+
+	result, err := b.classification.ai.Classify(ctx, incident.Title, incident.Description)
+	if err != nil {
+		job.Failed++
+		job.LastError = fmt.Sprintf("incident %s: %v", incident.ID, err)
+		return
+	}
+
+	severityChanged := result.Severity != incident.AISeverity
+	categoryChanged := result.Category != incident.AICategory
+	if severityChanged {
+		job.SeverityChanged++
+	}
+	if categoryChanged {
+		job.CategoryChanged++
+	}
+	if severityChanged || categoryChanged {
+		job.Changed++
+	}
+
+	if job.DryRun {
+		return
+	}
+	if err := b.classification.classifications.Record(newClassificationRecord(incident.ID, result), classificationChanges(result)); err != nil {
+		job.Failed++
+		job.LastError = fmt.Sprintf("incident %s: %v", incident.ID, err)
+	}
+}
+
+// complete records the final state of a job
+func (b *BulkReclassifier) complete(job *model.ReclassificationJob, status, cause string) {
+	finished := time.Now()
+	job.Status = status
+	job.FinishedAt = &finished
+	if cause != "" {
+		job.LastError = cause
+	}
+	b.save(job)
+}
+
+// save persists job progress; failures are logged because the job keeps running
+func (b *BulkReclassifier) save(job *model.ReclassificationJob) {
+	if err := b.jobs.Save(job); err != nil {
+		log.Printf("Failed to save reclassification job %s: %v", job.ID, err)
+	}
+}
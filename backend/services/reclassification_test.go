@@ -0,0 +1,122 @@
+package services
+
+import (
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"testing"
+	"time"
+)
+
+func TestBulkReclassifier_DryRunAndApply(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	classifier := newStubClassifier(AIAnalysisResult{Severity: "high", Category: "network"})
+	bulk := NewBulkReclassifier(NewClassificationService(classifier), MaxReclassifyRateLimit)
+	incidents := NewIncidentService()
+
+	since := time.Now().Add(-time.Second)
+	var created []*model.Incident
+	for i := 0; i < 3; i++ {
+		incident, err := incidents.CreateIncident(model.Incident{
+			Title:       "Bulk reclassify incident " + t.Name() + string(rune('a'+i)),
+			Description: "Incident used to test bulk reclassification",
+			Priority:    "critical",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create test incident: %v", err)
+		}
+		created = append(created, incident)
+	}
+	filter := repository.IncidentFilter{Priority: []string{"critical"}, CreatedAfter: &since}
+
+	// A dry run counts changes without saving them
+	job, err := bulk.Start(ReclassifyOptions{Filter: filter, DryRun: true, Actor: "tester"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bulk.Wait()
+
+	finished, err := bulk.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if finished.Status != model.ReclassificationCompleted {
+		t.Fatalf("Expected status completed, got %s (%s)", finished.Status, finished.LastError)
+	}
+	if finished.Total != 3 || finished.Processed != 3 || finished.Changed != 3 || finished.SeverityChanged != 3 || finished.CategoryChanged != 3 {
+		t.Errorf("Unexpected dry run progress: %+v", finished)
+	}
+	unchanged, _ := incidents.GetIncident(created[0].ID)
+	if unchanged.AISeverity != "medium" || unchanged.AICategory != "software" {
+		t.Errorf("Expected dry run to leave labels unchanged, got %s/%s", unchanged.AISeverity, unchanged.AICategory)
+	}
+
+	// A real run saves the new labels
+	job, err = bulk.Start(ReclassifyOptions{Filter: filter, Actor: "tester"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bulk.Wait()
+
+	finished, _ = bulk.GetJob(job.ID)
+	if finished.Status != model.ReclassificationCompleted || finished.Processed != 3 {
+		t.Fatalf("Unexpected job: %+v", finished)
+	}
+	for _, incident := range created {
+		updated, _ := incidents.GetIncident(incident.ID)
+		if updated.AISeverity != "high" || updated.AICategory != "network" {
+			t.Errorf("Expected incident %s to be reclassified, got %s/%s", incident.ID, updated.AISeverity, updated.AICategory)
+		}
+	}
+
+	// Running it again changes nothing
+	job, _ = bulk.Start(ReclassifyOptions{Filter: filter, DryRun: true})
+	bulk.Wait()
+	finished, _ = bulk.GetJob(job.ID)
+	if finished.Changed != 0 {
+		t.Errorf("Expected no changes on a second run, got %d", finished.Changed)
+	}
+}
+
+func TestBulkReclassifier_CancelAndValidation(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	bulk := NewBulkReclassifier(NewClassificationService(NewDefaultRuleClassifier()), DefaultReclassifyRateLimit)
+
+	if _, err := bulk.Start(ReclassifyOptions{RateLimit: MaxReclassifyRateLimit + 1}); err == nil {
+		t.Error("Expected an error for a rate limit above the maximum")
+	}
+
+	if _, err := NewIncidentService().CreateIncident(model.Incident{
+		Title:       "Bulk cancel incident",
+		Description: "Incident used to test cancelling a bulk job",
+	}); err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	job, err := bulk.Start(ReclassifyOptions{DryRun: true, RateLimit: 0.01})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := bulk.Cancel(job.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bulk.Wait()
+
+	cancelled, _ := bulk.GetJob(job.ID)
+	if cancelled.Status != model.ReclassificationCancelled {
+		t.Errorf("Expected status cancelled, got %s", cancelled.Status)
+	}
+	if err := bulk.Cancel(job.ID); err != ErrJobNotRunning {
+		t.Errorf("Expected ErrJobNotRunning, got %v", err)
+	}
+}
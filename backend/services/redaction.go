@@ -0,0 +1,302 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in detectors of sensitive values in incident text
+const (
+	DetectorEmail       = "email"
+	DetectorIPv4        = "ipv4"
+	DetectorIPv6        = "ipv6"
+	DetectorCreditCard  = "credit_card"
+	DetectorBearerToken = "bearer_token"
+)
+
+// builtinDetectorNames lists the built-in detectors in the order they run. Tokens run
+// first because they may contain text that looks like the other values.
+var builtinDetectorNames = []string{DetectorBearerToken, DetectorEmail, DetectorCreditCard, DetectorIPv6, DetectorIPv4}
+
+// RedactionPattern is a custom detector, e.g. of customer names. Keywords match as whole
+// words; patterns are case-insensitive regular expressions.
+type RedactionPattern struct {
+	Name     string   `yaml:"name"`
+	Keywords []string `yaml:"keywords"`
+	Patterns []string `yaml:"patterns"`
+}
+
+// RedactionConfig controls what is removed from incident text before it is sent to an external provider
+type RedactionConfig struct {
+	// Enabled turns redaction on
+	Enabled bool
+	// Detectors are the built-in detectors to run; empty runs all of them
+	Detectors []string
+	// PatternsFile is a YAML file of custom patterns, read when the redactor is created
+	PatternsFile string
+	// Patterns are custom detectors in addition to those of PatternsFile
+	Patterns []RedactionPattern
+}
+
+// RedactionReport counts the redacted values per detector. The values themselves are never kept.
+type RedactionReport map[string]int
+
+// RedactionConfigFromEnv reads AI_REDACTION, AI_REDACTION_DETECTORS and AI_REDACTION_FILE.
+// Redaction is on unless AI_REDACTION is false.
+func RedactionConfigFromEnv() RedactionConfig {
+	config := RedactionConfig{Enabled: true, PatternsFile: os.Getenv("AI_REDACTION_FILE")}
+	if value, err := strconv.ParseBool(os.Getenv("AI_REDACTION")); err == nil {
+		config.Enabled = value
+	}
+	for _, name := range strings.Split(os.Getenv("AI_REDACTION_DETECTORS"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			config.Detectors = append(config.Detectors, name)
+		}
+	}
+	return config
+}
+
+// Redactor replaces sensitive values in text with placeholders such as [EMAIL_1].
+// A nil Redactor leaves text unchanged.
+type Redactor struct {
+	detectors []redactionDetector
+}
+
+// redactionDetector finds one kind of sensitive value. When the pattern has a group, only
+// the group is replaced; valid, if set, rejects matches that are not really sensitive.
+type redactionDetector struct {
+	name    string
+	pattern *regexp.Regexp
+	valid   func(value string) bool
+}
+
+// builtinDetectors are the detectors that ship with the service, by name
+var builtinDetectors = map[string]redactionDetector{
+	DetectorBearerToken: {
+		name:    DetectorBearerToken,
+		pattern: regexp.MustCompile(`(?i)\bbearer\s+([a-z0-9\-._~+/]+=*)`),
+	},
+	DetectorEmail: {
+		name:    DetectorEmail,
+		pattern: regexp.MustCompile(`(?i)\b[a-z0-9._%+\-]+@[a-z0-9\-]+(?:\.[a-z0-9\-]+)*\.[a-z]{2,}\b`),
+	},
+	DetectorCreditCard: {
+		name:    DetectorCreditCard,
+		pattern: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		valid:   luhnValid,
+	},
+	DetectorIPv6: {
+		name:    DetectorIPv6,
+		pattern: regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}(?:[0-9a-f]{1,4}|(?:\d{1,3}\.){3}\d{1,3})?`),
+		valid:   validIPv6,
+	},
+	DetectorIPv4: {
+		name:    DetectorIPv4,
+		pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+		valid:   func(value string) bool { return net.ParseIP(value) != nil },
+	},
+}
+
+// NewRedactor creates a redactor from the configuration. It returns nil when redaction is disabled.
+func NewRedactor(config RedactionConfig) (*Redactor, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	names := config.Detectors
+	if len(names) == 0 {
+		names = builtinDetectorNames
+	}
+	enabled := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := builtinDetectors[name]; !ok {
+			return nil, fmt.Errorf("unknown redaction detector '%s' (valid detectors: %s)", name, strings.Join(builtinDetectorNames, ", "))
+		}
+		enabled[name] = true
+	}
+	redactor := &Redactor{}
+	for _, name := range builtinDetectorNames {
+		if enabled[name] {
+			redactor.detectors = append(redactor.detectors, builtinDetectors[name])
+		}
+	}
+
+	patterns := config.Patterns
+	if config.PatternsFile != "" {
+		filePatterns, err := LoadRedactionPatterns(config.PatternsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redaction patterns from %s: %v", config.PatternsFile, err)
+		}
+		patterns = append(filePatterns, patterns...)
+	}
+	// Custom patterns run last so they cannot break up the values of the built-in detectors
+	for i, pattern := range patterns {
+		if pattern.Name == "" {
+			pattern.Name = fmt.Sprintf("custom-%d", i+1)
+		}
+		if len(pattern.Keywords) > 0 {
+			keywords := make([]string, 0, len(pattern.Keywords))
+			for _, keyword := range pattern.Keywords {
+				keywords = append(keywords, regexp.QuoteMeta(keyword))
+			}
+			redactor.detectors = append(redactor.detectors, redactionDetector{
+				name:    pattern.Name,
+				pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(keywords, "|") + `)\b`),
+			})
+		}
+		for _, expression := range pattern.Patterns {
+			compiled, err := regexp.Compile("(?i)" + expression)
+			if err != nil {
+				return nil, fmt.Errorf("redaction pattern '%s' has an invalid pattern %q: %v", pattern.Name, expression, err)
+			}
+			redactor.detectors = append(redactor.detectors, redactionDetector{name: pattern.Name, pattern: compiled})
+		}
+		if len(pattern.Keywords) == 0 && len(pattern.Patterns) == 0 {
+			return nil, fmt.Errorf("redaction pattern '%s' must have at least one keyword or pattern", pattern.Name)
+		}
+	}
+	return redactor, nil
+}
+
+// LoadRedactionPatterns reads custom redaction patterns from a YAML file with a top-level patterns list
+func LoadRedactionPatterns(path string) ([]RedactionPattern, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Patterns []RedactionPattern `yaml:"patterns"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid redaction patterns: %v", err)
+	}
+	return file.Patterns, nil
+}
+
+// Scope starts redacting the texts of one provider request. Within a scope, the same
+// value always gets the same placeholder, so the model can still tell values apart.
+func (r *Redactor) Scope() *RedactionScope {
+	return &RedactionScope{redactor: r, placeholders: make(map[string]string), counts: make(map[string]int)}
+}
+
+// RedactionScope redacts related texts consistently and reports what was redacted
+type RedactionScope struct {
+	redactor     *Redactor
+	placeholders map[string]string
+	// counts holds the number of distinct values per detector, for numbering placeholders
+	counts map[string]int
+	report RedactionReport
+}
+
+// Redact returns text with every detected value replaced by its placeholder
+func (s *RedactionScope) Redact(text string) string {
+	if s.redactor == nil {
+		return text
+	}
+	for _, detector := range s.redactor.detectors {
+		text = s.apply(detector, text)
+	}
+	return text
+}
+
+// Report returns the number of values redacted per detector, or nil if nothing was redacted
+func (s *RedactionScope) Report() RedactionReport {
+	return s.report
+}
+
+// apply replaces the values one detector finds in text
+func (s *RedactionScope) apply(detector redactionDetector, text string) string {
+	matches := detector.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if len(match) >= 4 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+		value := text[start:end]
+		if detector.valid != nil && !detector.valid(value) {
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(s.placeholder(detector.name, value))
+		last = end
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// placeholder returns the placeholder of a value, numbering new values per detector
+func (s *RedactionScope) placeholder(detector, value string) string {
+	if s.report == nil {
+		s.report = make(RedactionReport)
+	}
+	s.report[detector]++
+
+	key := detector + "\x00" + value
+	if placeholder, ok := s.placeholders[key]; ok {
+		return placeholder
+	}
+	s.counts[detector]++
+	label := strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(detector))
+	placeholder := fmt.Sprintf("[%s_%d]", label, s.counts[detector])
+	s.placeholders[key] = placeholder
+	return placeholder
+}
+
+// luhnValid reports whether a digit sequence, possibly grouped by spaces or dashes,
+// passes the Luhn checksum used by payment card numbers
+func luhnValid(value string) bool {
+	sum, digits := 0, 0
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if digits%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// validIPv6 accepts IPv6 addresses with at least two non-empty groups, so that
+// scope operators such as std::map and times such as 12:30:45 are left alone
+func validIPv6(value string) bool {
+	ip := net.ParseIP(value)
+	if ip == nil || !strings.Contains(value, ":") {
+		return false
+	}
+	groups := 0
+	for _, group := range strings.Split(value, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+	return groups >= 2
+}
+
+// defaultRedactor returns a redactor running every built-in detector
+func defaultRedactor() *Redactor {
+	redactor, err := NewRedactor(RedactionConfig{Enabled: true})
+	if err != nil {
+		panic("services: invalid built-in redaction detectors: " + err.Error())
+	}
+	return redactor
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultRemediationNeighbors is the number of similar resolved incidents a suggestion draws on
+const DefaultRemediationNeighbors = 3
+
+// resolvedStatuses are the statuses of incidents that have been resolved
+var resolvedStatuses = []string{"resolved", "closed"}
+
+// ErrNoSuggestion is returned when an incident has no remediation suggestion with the requested ID
+var ErrNoSuggestion = errors.New("remediation suggestion not found")
+
+// Remediator suggests probable causes and next steps for incidents
+type Remediator interface {
+	// Name identifies the provider behind the remediator
+	Name() string
+	// Suggest proposes causes and steps for an incident from similar resolved incidents
+	Suggest(ctx context.Context, input RemediationInput) (*RemediationResult, error)
+}
+
+// RemediationInput is an incident and the resolved incidents that resemble it
+type RemediationInput struct {
+	Incident model.Incident
+	// Past are the most similar resolved incidents, most similar first
+	Past []PastIncident
+}
+
+// PastIncident is a resolved incident similar to the one being remediated
+type PastIncident struct {
+	Incident model.Incident
+	Score    float64
+	// Resolution is what the responders noted when resolving or closing the incident
+	Resolution string
+}
+
+// ProbableCause is a suggested root cause with the suggester's confidence in it, from 0 to 1
+type ProbableCause struct {
+	Cause      string  `json:"cause"`
+	Confidence float64 `json:"confidence"`
+}
+
+// RemediationSource identifies a past incident a suggestion drew on
+type RemediationSource struct {
+	IncidentID string  `json:"incident_id"`
+	Title      string  `json:"title"`
+	Status     string  `json:"status"`
+	Score      float64 `json:"score"`
+}
+
+// RemediationResult is a generated remediation suggestion
+type RemediationResult struct {
+	Causes        []ProbableCause `json:"causes"`
+	Steps         []string        `json:"steps"`
+	Provider      string          `json:"provider"`
+	Model         string          `json:"model,omitempty"`
+	PromptVersion string          `json:"prompt_version,omitempty"`
+	Usage         openai.Usage    `json:"usage"`
+	// Fallback is set when the local remediator stood in for the configured provider
+	Fallback       bool   `json:"fallback"`
+	FallbackReason string `json:"fallback_reason,omitempty"`
+	// Redactions counts the values removed from the text sent to the provider
+	Redactions RedactionReport `json:"redactions,omitempty"`
+}
+
+// RemediationPromptData is the data available to remediation prompt templates
+type RemediationPromptData struct {
+	Title       string
+	Description string
+	Status      string
+	Severity    string
+	Category    string
+	// Past are the similar resolved incidents, most similar first
+	Past []PastIncidentPromptData
+}
+
+// PastIncidentPromptData is a similar resolved incident as it appears in prompts
+type PastIncidentPromptData struct {
+	Title       string
+	Description string
+	Category    string
+	// Similarity is the score formatted with two decimals
+	Similarity string
+	Resolution string
+}
+
+// remediationOutput is the suggestion the model must return
+type remediationOutput struct {
+	Causes []struct {
+		Cause      *string  `json:"cause"`
+		Confidence *float64 `json:"confidence"`
+	} `json:"causes"`
+	Steps []string `json:"steps"`
+}
+
+// newRemediationPromptData gathers the prompt data of an incident and its past incidents
+func newRemediationPromptData(input RemediationInput) RemediationPromptData {
+	incident := input.Incident
+	data := RemediationPromptData{
+		Title:       incident.Title,
+		Description: incident.Description,
+		Status:      incident.Status,
+		Severity:    incident.EffectiveSeverity(),
+		Category:    incident.EffectiveCategory(),
+		Past:        make([]PastIncidentPromptData, 0, len(input.Past)),
+	}
+	for _, past := range input.Past {
+		data.Past = append(data.Past, PastIncidentPromptData{
+			Title:       past.Incident.Title,
+			Description: past.Incident.Description,
+			Category:    past.Incident.EffectiveCategory(),
+			Similarity:  strconv.FormatFloat(past.Score, 'f', 2, 64),
+			Resolution:  past.Resolution,
+		})
+	}
+	return data
+}
+
+// redact replaces sensitive values in the free-text fields of the prompt data
+func (data *RemediationPromptData) redact(redaction *RedactionScope) {
+	data.Title = redaction.Redact(data.Title)
+	data.Description = redaction.Redact(data.Description)
+	for i := range data.Past {
+		data.Past[i].Title = redaction.Redact(data.Past[i].Title)
+		data.Past[i].Description = redaction.Redact(data.Past[i].Description)
+		data.Past[i].Resolution = redaction.Redact(data.Past[i].Resolution)
+	}
+}
+
+// resolutionReasons lists the reasons given when an incident was resolved or closed
+func resolutionReasons(transitions []model.StatusTransition) []string {
+	reasons := []string{}
+	for _, transition := range transitions {
+		if containsString(resolvedStatuses, transition.ToStatus) && strings.TrimSpace(transition.Reason) != "" {
+			reasons = append(reasons, strings.TrimSpace(transition.Reason))
+		}
+	}
+	return reasons
+}
+
+// LocalRemediator suggests the causes and fixes of the similar resolved incidents without
+// a model. It is the offline mode and fallback of the LLM providers.
+type LocalRemediator struct{}
+
+// NewLocalRemediator creates the built-in remediator
+func NewLocalRemediator() *LocalRemediator {
+	return &LocalRemediator{}
+}
+
+// Name identifies the local remediator
+func (l *LocalRemediator) Name() string {
+	return ProviderLocal
+}
+
+// Suggest proposes each similar incident as a probable recurrence, as confident as it is
+// similar, and the fixes noted when those incidents were resolved as next steps
+func (l *LocalRemediator) Suggest(ctx context.Context, input RemediationInput) (*RemediationResult, error) {
+	result := &RemediationResult{
+		Causes:   []ProbableCause{},
+		Steps:    []string{},
+		Provider: ProviderLocal,
+	}
+	for _, past := range input.Past {
+		cause := fmt.Sprintf("Recurrence of %q", past.Incident.Title)
+		if category := past.Incident.EffectiveCategory(); category != "" {
+			cause += " (" + category + ")"
+		}
+		result.Causes = append(result.Causes, ProbableCause{Cause: cause, Confidence: math.Round(past.Score*100) / 100})
+		if past.Resolution != "" {
+			result.Steps = append(result.Steps, fmt.Sprintf("Check whether the fix of %q applies: %s", past.Incident.Title, past.Resolution))
+		}
+	}
+	if len(result.Steps) == 0 {
+		result.Steps = append(result.Steps, "No similar incident was resolved with notes; review recent changes to the affected systems")
+	}
+	return result, nil
+}
+
+// Suggest proposes causes and steps with the chat completion API. The local remediator is
+// used when no API key is configured, the monthly budget is spent, the provider fails or
+// its circuit breaker is open.
+func (s *AIService) Suggest(ctx context.Context, input RemediationInput) (*RemediationResult, error) {
+	return callModel(ctx, s, modelTask[*RemediationResult]{
+		operation: OperationRemediate,
+		noun:      "remediation",
+		prompt: func(redaction *RedactionScope) (string, error) {
+			data := newRemediationPromptData(input)
+			data.redact(redaction)
+			return s.remediationPrompt.Render(data)
+		},
+		request: func(prompt string) openai.ChatCompletionRequest {
+			return s.jsonRequest(prompt, 0.2)
+		},
+		parse: func(message openai.ChatCompletionMessage) (*RemediationResult, error) {
+			return parseRemediationOutput(message.Content)
+		},
+		answered: func(result *RemediationResult, answer modelAnswer) {
+			result.Provider = answer.Provider
+			result.Model = answer.Model
+			result.PromptVersion = s.remediationPrompt.Version
+			result.Usage = answer.Usage
+			result.Redactions = answer.Redactions
+		},
+		fallback: func(ctx context.Context, cause error) (*RemediationResult, error) {
+			return s.suggestWithFallback(ctx, input, cause)
+		},
+	})
+}
+
+// suggestWithFallback suggests with the local remediator
+func (s *AIService) suggestWithFallback(ctx context.Context, input RemediationInput, cause error) (*RemediationResult, error) {
+	result, err := NewLocalRemediator().Suggest(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Fallback = true
+	result.FallbackReason = cause.Error()
+	return result, nil
+}
+
+// parseRemediationOutput strictly decodes a remediation answer
+func parseRemediationOutput(raw string) (*RemediationResult, error) {
+	var output remediationOutput
+	if err := decodeModelJSON(raw, &output); err != nil {
+		return nil, err
+	}
+
+	result := &RemediationResult{Causes: []ProbableCause{}, Steps: []string{}}
+	for i, cause := range output.Causes {
+		if cause.Cause == nil || strings.TrimSpace(*cause.Cause) == "" {
+			return nil, fmt.Errorf("%w: cause %d is missing", ErrInvalidModelOutput, i+1)
+		}
+		if cause.Confidence == nil || *cause.Confidence < 0 || *cause.Confidence > 1 {
+			return nil, fmt.Errorf("%w: cause %d must have a confidence between 0 and 1", ErrInvalidModelOutput, i+1)
+		}
+		result.Causes = append(result.Causes, ProbableCause{Cause: strings.TrimSpace(*cause.Cause), Confidence: *cause.Confidence})
+	}
+	for _, step := range output.Steps {
+		if step = strings.TrimSpace(step); step != "" {
+			result.Steps = append(result.Steps, step)
+		}
+	}
+	if len(result.Steps) == 0 {
+		return nil, fmt.Errorf("%w: steps are missing", ErrInvalidModelOutput)
+	}
+	return result, nil
+}
+
+// NewRemediator creates the remediator of the configured provider. The rules provider
+// has no language model, so it is served by the local remediator.
+func NewRemediator(config ClassifierConfig) (Remediator, error) {
+	if config.Provider == ProviderRules {
+		return NewLocalRemediator(), nil
+	}
+	// Suggestions never classify, so the service needs no fallback classifier
+	return newAIServiceFromConfig(config, nil)
+}
+
+// NewRemediatorFromEnv creates the configured remediator, falling back to the
+// local remediator when the configuration is invalid
+func NewRemediatorFromEnv() Remediator {
+	remediator, err := NewRemediator(ClassifierConfigFromEnv())
+	if err != nil {
+		log.Println("Invalid AI remediator configuration, using local remediator:", err)
+		return NewLocalRemediator()
+	}
+	return remediator
+}
+
+// RemediationService suggests remediations from similar resolved incidents and keeps
+// the responders' feedback on them
+type RemediationService struct {
+	incidents   *repository.IncidentRepository
+	transitions *repository.TransitionRepository
+	suggestions *repository.RemediationRepository
+	similarity  *SimilarityService
+	remediator  Remediator
+}
+
+// NewRemediationService creates a new remediation service
+func NewRemediationService(remediator Remediator, embedder Embedder) *RemediationService {
+	return &RemediationService{
+		incidents:   repository.NewIncidentRepository(),
+		transitions: repository.NewTransitionRepository(),
+		suggestions: repository.NewRemediationRepository(),
+		similarity:  NewSimilarityService(embedder),
+		remediator:  remediator,
+	}
+}
+
+// SuggestRemediation finds the incident's most similar resolved incidents and stores
+// a new suggestion drawn from them
+func (s *RemediationService) SuggestRemediation(ctx context.Context, incidentID, actor string) (*model.RemediationSuggestion, error) {
+	incident, err := s.incidents.GetByID(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	similar, err := s.similarity.FindSimilarWithStatus(ctx, incidentID, DefaultRemediationNeighbors, 0, resolvedStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	input := RemediationInput{Incident: *incident, Past: make([]PastIncident, 0, len(similar))}
+	sources := make([]RemediationSource, 0, len(similar))
+	for _, match := range similar {
+		transitions, err := s.transitions.ListByIncident(match.Incident.ID)
+		if err != nil {
+			return nil, err
+		}
+		input.Past = append(input.Past, PastIncident{Incident: match.Incident, Score: match.Score, Resolution: strings.Join(resolutionReasons(transitions), "; ")})
+		sources = append(sources, RemediationSource{
+			IncidentID: match.Incident.ID,
+			Title:      match.Incident.Title,
+			Status:     match.Incident.Status,
+			Score:      match.Score,
+		})
+	}
+
+	result, err := s.remediator.Suggest(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := newRemediationRecord(incident.ID, actor, sources, result)
+	if err := s.suggestions.Create(suggestion); err != nil {
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// GetSuggestions retrieves every remediation suggestion of an incident, newest first
+func (s *RemediationService) GetSuggestions(incidentID string) ([]model.RemediationSuggestion, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+	return s.suggestions.ListByIncident(incidentID)
+}
+
+// RecordFeedback marks a suggestion as helpful or not; later feedback replaces earlier feedback
+func (s *RemediationService) RecordFeedback(incidentID, suggestionID string, helpful bool, comment, actor string) (*model.RemediationSuggestion, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+	suggestion, err := s.suggestions.Get(incidentID, suggestionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNoSuggestion
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	suggestion.Helpful = &helpful
+	suggestion.FeedbackComment = comment
+	suggestion.FeedbackActor = actor
+	suggestion.FeedbackAt = &now
+	if err := s.suggestions.SaveFeedback(suggestion); err != nil {
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// newRemediationRecord converts a remediation result into a stored suggestion
+func newRemediationRecord(incidentID, actor string, sources []RemediationSource, result *RemediationResult) *model.RemediationSuggestion {
+	causes, _ := json.Marshal(result.Causes)
+	steps, _ := json.Marshal(result.Steps)
+	sourceIncidents, _ := json.Marshal(sources)
+	suggestion := &model.RemediationSuggestion{
+		IncidentID:       incidentID,
+		Causes:           causes,
+		Steps:            steps,
+		SourceIncidents:  sourceIncidents,
+		Provider:         result.Provider,
+		Model:            result.Model,
+		PromptVersion:    result.PromptVersion,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		Fallback:         result.Fallback,
+		FallbackReason:   result.FallbackReason,
+		Actor:            actor,
+	}
+	if len(result.Redactions) > 0 {
+		suggestion.Redactions, _ = json.Marshal(result.Redactions)
+	}
+	return suggestion
+}
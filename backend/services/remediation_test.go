@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"incident-management/database"
+	"incident-management/model"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocalRemediator(t *testing.T) {
+	input := RemediationInput{
+		Incident: model.Incident{Title: "Checkout API returning 500s", Description: "Payments fail"},
+		Past: []PastIncident{
+			{Incident: model.Incident{Title: "Checkout 500s after deploy", AICategory: "software"}, Score: 0.8123, Resolution: "Rolled back release 42"},
+			{Incident: model.Incident{Title: "Checkout slow"}, Score: 0.41},
+		},
+	}
+
+	result, err := NewLocalRemediator().Suggest(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Causes) != 2 || result.Causes[0].Confidence != 0.81 || !strings.Contains(result.Causes[0].Cause, "(software)") {
+		t.Errorf("Expected a cause per past incident ranked by similarity, got %+v", result.Causes)
+	}
+	if len(result.Steps) != 1 || !strings.Contains(result.Steps[0], "Rolled back release 42") {
+		t.Errorf("Expected the resolution notes as steps, got %v", result.Steps)
+	}
+
+	empty, err := NewLocalRemediator().Suggest(context.Background(), RemediationInput{Incident: input.Incident})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(empty.Causes) != 0 || len(empty.Steps) != 1 {
+		t.Errorf("Expected a generic step without past incidents, got %+v", empty)
+	}
+}
+
+func TestAIServiceSuggest(t *testing.T) {
+	var prompt string
+	chat := newFakeChatServer(t, `{"causes": [{"cause": "Bad release", "confidence": 0.7}], "steps": ["Roll back", "Check error rates"]}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		prompt = string(body)
+		r.Body = io.NopCloser(strings.NewReader(prompt))
+		chat.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+
+	result, err := aiService.Suggest(context.Background(), RemediationInput{
+		Incident: model.Incident{Title: "Checkout down", Description: "Reported by ops@example.com"},
+		Past:     []PastIncident{{Incident: model.Incident{Title: "Checkout down last week"}, Score: 0.9, Resolution: "Rolled back"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fallback {
+		t.Fatalf("Expected model suggestion, got fallback: %s", result.FallbackReason)
+	}
+	if result.PromptVersion != DefaultRemediationPromptVersion || result.Model != "test-model" || result.Usage.TotalTokens != 150 {
+		t.Errorf("Unexpected provenance %+v", result)
+	}
+	if len(result.Causes) != 1 || result.Causes[0].Confidence != 0.7 || len(result.Steps) != 2 {
+		t.Errorf("Unexpected suggestion %+v", result)
+	}
+	if !strings.Contains(prompt, "Checkout down last week") || !strings.Contains(prompt, "Resolution: Rolled back") {
+		t.Errorf("Expected the past incident in the prompt, got %s", prompt)
+	}
+	if strings.Contains(prompt, "ops@example.com") || result.Redactions[DetectorEmail] != 1 {
+		t.Errorf("Expected the email to be redacted, got %v", result.Redactions)
+	}
+
+	// Answers outside the schema fall back to the local remediator
+	invalid := newFakeChatServer(t, `{"causes": [{"cause": "Bad release", "confidence": 7}], "steps": ["Roll back"]}`)
+	aiService = NewOpenAICompatibleService(invalid.URL+"/v1", "", "test-model")
+	aiService.outputRetries = 0
+	result, err = aiService.Suggest(context.Background(), RemediationInput{Incident: model.Incident{Title: "Checkout down"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Fallback || result.Provider != ProviderLocal {
+		t.Errorf("Expected a local fallback, got %+v", result)
+	}
+}
+
+func TestRemediationService(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	incidents := NewIncidentService()
+	create := func(title, description string) *model.Incident {
+		incident, err := incidents.CreateIncident(model.Incident{Title: title, Description: description})
+		if err != nil {
+			t.Fatalf("Failed to create test incident: %v", err)
+		}
+		return incident
+	}
+	current := create("Platypus cache evictions spiking", "Platypus cache evicting hot keys, latency up")
+	resolved := create("Platypus cache evictions spiking again", "Platypus cache evicting hot keys after resize")
+	if _, err := incidents.TransitionIncident(resolved.ID, "resolve", "Restored the cache memory limit", "alice"); err != nil {
+		t.Fatalf("Failed to resolve incident: %v", err)
+	}
+	open := create("Platypus cache evictions spiking once more", "Platypus cache evicting hot keys")
+
+	service := NewRemediationService(NewLocalRemediator(), NewLocalEmbedder())
+	if _, err := service.similarity.IndexPending(context.Background(), 1000); err != nil {
+		t.Fatalf("Failed to index incidents: %v", err)
+	}
+
+	suggestion, err := service.SuggestRemediation(context.Background(), current.ID, "bob")
+	if err != nil {
+		t.Fatalf("Failed to suggest remediation: %v", err)
+	}
+	var sources []RemediationSource
+	if err := json.Unmarshal(suggestion.SourceIncidents, &sources); err != nil {
+		t.Fatalf("Failed to decode sources: %v", err)
+	}
+	if len(sources) == 0 || sources[0].IncidentID != resolved.ID {
+		t.Fatalf("Expected the resolved incident as the source, got %s", suggestion.SourceIncidents)
+	}
+	for _, source := range sources {
+		if source.IncidentID == open.ID {
+			t.Error("Expected unresolved incidents to be excluded")
+		}
+	}
+	if !strings.Contains(string(suggestion.Steps), "Restored the cache memory limit") || suggestion.Actor != "bob" || suggestion.Helpful != nil {
+		t.Errorf("Unexpected suggestion %+v", suggestion)
+	}
+
+	updated, err := service.RecordFeedback(current.ID, suggestion.ID, true, "Fixed it", "carol")
+	if err != nil {
+		t.Fatalf("Failed to record feedback: %v", err)
+	}
+	if updated.Helpful == nil || !*updated.Helpful || updated.FeedbackActor != "carol" || updated.FeedbackAt == nil {
+		t.Errorf("Unexpected feedback %+v", updated)
+	}
+	suggestions, err := service.GetSuggestions(current.ID)
+	if err != nil || len(suggestions) != 1 || suggestions[0].Helpful == nil || suggestions[0].FeedbackComment != "Fixed it" {
+		t.Errorf("Expected the stored feedback, got %+v %v", suggestions, err)
+	}
+
+	if _, err := service.RecordFeedback(resolved.ID, suggestion.ID, false, "", "carol"); err != ErrNoSuggestion {
+		t.Errorf("Expected ErrNoSuggestion for another incident's suggestion, got %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// CallPolicy bounds and retries individual calls to an LLM provider
+type CallPolicy struct {
+	// Timeout bounds a single call; the caller's deadline still applies
+	Timeout time.Duration
+	// MaxRetries is how many times a call that failed with a retryable error is repeated
+	MaxRetries int
+	// BaseBackoff is the delay before the first retry; it doubles on every retry
+	BaseBackoff time.Duration
+	// MaxBackoff caps the retry delay
+	MaxBackoff time.Duration
+}
+
+// DefaultCallPolicy returns the default provider call settings
+func DefaultCallPolicy() CallPolicy {
+	return CallPolicy{
+		Timeout:     15 * time.Second,
+		MaxRetries:  2,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// backoff returns the delay before the given retry
+func (p CallPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseBackoff
+	for i := 1; i < retry; i++ {
+		delay *= 2
+		if delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+const (
+	// DefaultBreakerThreshold is the number of consecutive failures that opens a breaker
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown is how long an open breaker waits before probing the provider again
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned when a provider's circuit breaker is short-circuiting calls
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker stops calling a provider after consecutive failures. Once the cooldown
+// has passed a single probe call is let through; its outcome closes or reopens the breaker.
+type CircuitBreaker struct {
+	name     string
+	endpoint string
+
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	lastError string
+	probing   bool
+	now       func() time.Time
+}
+
+// BreakerStatus is a snapshot of a circuit breaker, as reported on /health
+type BreakerStatus struct {
+	Provider            string     `json:"provider"`
+	Endpoint            string     `json:"endpoint"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+var (
+	breakersMu sync.Mutex
+	// breakers holds one breaker per provider endpoint, so every classifier
+	// talking to the same endpoint shares its state
+	breakers = make(map[string]*CircuitBreaker)
+)
+
+// circuitBreaker returns the shared breaker of a provider endpoint, creating it if needed
+func circuitBreaker(provider, endpoint string) *CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	key := provider + " " + endpoint
+	breaker, ok := breakers[key]
+	if !ok {
+		breaker = &CircuitBreaker{
+			name:      provider,
+			endpoint:  endpoint,
+			threshold: DefaultBreakerThreshold,
+			cooldown:  DefaultBreakerCooldown,
+			state:     BreakerClosed,
+			now:       time.Now,
+		}
+		breakers[key] = breaker
+	}
+	return breaker
+}
+
+// CircuitBreakerStatuses returns the state of every provider breaker, ordered by provider and endpoint
+func CircuitBreakerStatuses() []BreakerStatus {
+	breakersMu.Lock()
+	list := make([]*CircuitBreaker, 0, len(breakers))
+	for _, breaker := range breakers {
+		list = append(list, breaker)
+	}
+	breakersMu.Unlock()
+
+	statuses := make([]BreakerStatus, 0, len(list))
+	for _, breaker := range list {
+		statuses = append(statuses, breaker.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Provider != statuses[j].Provider {
+			return statuses[i].Provider < statuses[j].Provider
+		}
+		return statuses[i].Endpoint < statuses[j].Endpoint
+	})
+	return statuses
+}
+
+// configure changes the failure threshold and cooldown; zero values leave them unchanged
+func (b *CircuitBreaker) configure(threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if threshold > 0 {
+		b.threshold = threshold
+	}
+	if cooldown > 0 {
+		b.cooldown = cooldown
+	}
+}
+
+// Allow reports whether a call may be made, returning ErrCircuitOpen when it may not
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		log.Printf("Circuit breaker for %s is half-open, probing the provider", b.name)
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+// Success records a call the provider answered and closes the breaker
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != BreakerClosed {
+		log.Printf("Circuit breaker for %s closed", b.name)
+	}
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a failed call, opening the breaker when the threshold is reached
+// or when the probe of a half-open breaker fails
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.threshold) {
+		log.Printf("Circuit breaker for %s opened after %d consecutive failures: %v", b.name, b.failures, err)
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+	b.probing = false
+}
+
+// Release ends a call whose outcome says nothing about the provider, such as one the
+// caller cancelled, so that a half-open breaker can be probed again
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Status returns a snapshot of the breaker
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{
+		Provider:            b.name,
+		Endpoint:            b.endpoint,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.cooldown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// retryableError reports whether a failed provider call is worth repeating: timeouts,
+// network errors, rate limiting and server errors are; other client errors are not
+func retryableError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.HTTPStatusCode)
+	}
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return retryableStatus(requestErr.HTTPStatusCode)
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// retryableStatus reports whether an HTTP status code indicates a transient failure
+func retryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// sleepContext waits for d or until the context is done, returning the context's error in that case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
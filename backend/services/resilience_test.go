@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// fastPolicy retries quickly so tests do not wait on real backoff delays
+var fastPolicy = CallPolicy{Timeout: time.Second, MaxRetries: 2, BaseBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestCreateChatCompletion_RetriesTransientErrors(t *testing.T) {
+	var calls int32
+	chat := newFakeChatServer(t, `{"severity": "low", "category": "hardware"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.Error(w, `{"error": {"message": "rate limited"}}`, http.StatusTooManyRequests)
+			return
+		}
+		chat.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	aiService.policy = fastPolicy
+
+	result, err := aiService.Classify(context.Background(), "Disk failing", "SMART errors on db-2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fallback {
+		t.Errorf("Expected the retry to succeed, got fallback: %s", result.FallbackReason)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected 2 calls, got %d", got)
+	}
+}
+
+func TestCreateChatCompletion_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, `{"error": {"message": "invalid model"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	aiService.policy = fastPolicy
+
+	result, err := aiService.Classify(context.Background(), "Disk failing", "SMART errors on db-2")
+	if err != nil {
+		t.Fatalf("Expected fallback result, got error %v", err)
+	}
+	if !result.Fallback {
+		t.Error("Expected result to be marked as fallback")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 call, got %d", got)
+	}
+}
+
+func TestCreateChatCompletion_PerCallTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	aiService.policy = CallPolicy{Timeout: 50 * time.Millisecond, MaxRetries: 1, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	started := time.Now()
+	result, err := aiService.Classify(context.Background(), "Router down", "Core router is not forwarding packets")
+	if err != nil {
+		t.Fatalf("Expected fallback result, got error %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected the call to time out quickly, took %s", elapsed)
+	}
+	if !result.Fallback {
+		t.Error("Expected result to be marked as fallback")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected a timed out call to be retried once, got %d calls", got)
+	}
+}
+
+func TestClassify_CircuitBreakerOpensAndRecovers(t *testing.T) {
+	var calls, failing int32 = 0, 1
+	chat := newFakeChatServer(t, `{"severity": "high", "category": "network"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			http.Error(w, `{"error": {"message": "overloaded"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		chat.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	aiService.policy = CallPolicy{Timeout: time.Second}
+	now := time.Now()
+	aiService.breaker.now = func() time.Time { return now }
+	aiService.breaker.configure(2, time.Minute)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		aiService.Classify(ctx, "Router down", "Core router is not forwarding packets")
+	}
+	if status := aiService.breaker.Status(); status.State != BreakerOpen {
+		t.Fatalf("Expected breaker to be open after 2 failures, got %s", status.State)
+	}
+
+	// While open, the provider is not called at all
+	result, err := aiService.Classify(ctx, "Router down", "Core router is not forwarding packets")
+	if err != nil {
+		t.Fatalf("Expected fallback result, got error %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected no call while the breaker is open, got %d calls", got)
+	}
+	if !result.Fallback || !strings.Contains(result.FallbackReason, ErrCircuitOpen.Error()) {
+		t.Errorf("Expected fallback because the breaker is open, got '%s'", result.FallbackReason)
+	}
+
+	found := false
+	for _, status := range CircuitBreakerStatuses() {
+		if status.Endpoint == server.URL+"/v1" {
+			found = true
+			if status.State != BreakerOpen || status.RetryAt == nil {
+				t.Errorf("Expected open breaker with a retry time in the statuses, got %+v", status)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected breaker to be listed in the statuses")
+	}
+
+	// After the cooldown a successful probe closes the breaker
+	atomic.StoreInt32(&failing, 0)
+	now = now.Add(2 * time.Minute)
+	result, err = aiService.Classify(ctx, "Router down", "Core router is not forwarding packets")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fallback {
+		t.Errorf("Expected the probe to reach the provider, got fallback: %s", result.FallbackReason)
+	}
+	if status := aiService.breaker.Status(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("Expected breaker to close after a successful probe, got %+v", status)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAllowsOneProbe(t *testing.T) {
+	breaker := circuitBreaker("test", t.Name())
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	breaker.configure(1, time.Second)
+
+	breaker.Failure(errors.New("boom"))
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected open breaker to refuse calls, got %v", err)
+	}
+
+	now = now.Add(2 * time.Second)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected a probe after the cooldown, got %v", err)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected a second concurrent probe to be refused, got %v", err)
+	}
+
+	// A failed probe reopens the breaker for another cooldown
+	breaker.Failure(errors.New("still down"))
+	if status := breaker.Status(); status.State != BreakerOpen || status.LastError != "still down" {
+		t.Errorf("Expected failed probe to reopen the breaker, got %+v", status)
+	}
+}
+
+func TestRetryableError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}, true},
+		{&openai.APIError{HTTPStatusCode: http.StatusBadGateway}, true},
+		{&openai.RequestError{HTTPStatusCode: http.StatusServiceUnavailable}, true},
+		{&openai.APIError{HTTPStatusCode: http.StatusUnauthorized}, false},
+		{&openai.RequestError{HTTPStatusCode: http.StatusBadRequest}, false},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+	}
+	for _, tc := range cases {
+		if got := retryableError(tc.err); got != tc.want {
+			t.Errorf("retryableError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"incident-management/model"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules/default.yaml
+var defaultRulesYAML []byte
+
+// ClassificationRule assigns a severity and/or category when any of its keywords
+// or patterns appear in an incident
+type ClassificationRule struct {
+	Name     string   `yaml:"name" json:"name"`
+	Keywords []string `yaml:"keywords" json:"keywords,omitempty"`
+	Patterns []string `yaml:"patterns" json:"patterns,omitempty"`
+	Severity string   `yaml:"severity" json:"severity,omitempty"`
+	Category string   `yaml:"category" json:"category,omitempty"`
+	Weight   float64  `yaml:"weight" json:"weight"`
+}
+
+// RuleSet is the configuration of the rule-based classifier
+type RuleSet struct {
+	DefaultSeverity string               `yaml:"default_severity"`
+	DefaultCategory string               `yaml:"default_category"`
+	Rules           []ClassificationRule `yaml:"rules"`
+}
+
+// RuleMatch explains why a rule fired
+type RuleMatch struct {
+	Rule     string   `json:"rule"`
+	Matches  []string `json:"matches"`
+	Severity string   `json:"severity,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Weight   float64  `json:"weight"`
+}
+
+// RuleClassifier is a deterministic classifier that scores incidents against weighted rules
+type RuleClassifier struct {
+	rules           []compiledRule
+	defaultSeverity string
+	defaultCategory string
+}
+
+// compiledRule is a classification rule with its keywords and patterns compiled
+type compiledRule struct {
+	ClassificationRule
+	matchers []*regexp.Regexp
+}
+
+// ParseRuleSet parses a YAML rule set
+func ParseRuleSet(data []byte) (RuleSet, error) {
+	var ruleSet RuleSet
+	if err := yaml.Unmarshal(data, &ruleSet); err != nil {
+		return RuleSet{}, fmt.Errorf("invalid rules: %v", err)
+	}
+	return ruleSet, nil
+}
+
+// LoadRuleSet reads a YAML rule set from a file
+func LoadRuleSet(path string) (RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RuleSet{}, err
+	}
+	return ParseRuleSet(data)
+}
+
+// DefaultRuleSet returns the built-in rules shipped in rules/default.yaml
+func DefaultRuleSet() RuleSet {
+	ruleSet, err := ParseRuleSet(defaultRulesYAML)
+	if err != nil {
+		panic("services: invalid embedded default rules: " + err.Error())
+	}
+	return ruleSet
+}
+
+// NewRuleClassifier creates a rule-based classifier from a rule set
+func NewRuleClassifier(ruleSet RuleSet) (*RuleClassifier, error) {
+	// Empty defaults follow the taxonomy default at classification time
+	classifier := &RuleClassifier{
+		defaultSeverity: ruleSet.DefaultSeverity,
+		defaultCategory: ruleSet.DefaultCategory,
+	}
+
+	for i, rule := range ruleSet.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.Severity == "" && rule.Category == "" {
+			return nil, fmt.Errorf("rule '%s' must set a severity or a category", rule.Name)
+		}
+		if rule.Weight == 0 {
+			rule.Weight = 1
+		}
+
+		compiled := compiledRule{ClassificationRule: rule}
+		if len(rule.Keywords) > 0 {
+			keywords := make([]string, 0, len(rule.Keywords))
+			for _, keyword := range rule.Keywords {
+				keywords = append(keywords, regexp.QuoteMeta(strings.ToLower(keyword)))
+			}
+			compiled.matchers = append(compiled.matchers, regexp.MustCompile(`(?i)\b(?:`+strings.Join(keywords, "|")+`)\b`))
+		}
+		for _, pattern := range rule.Patterns {
+			matcher, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule '%s' has an invalid pattern %q: %v", rule.Name, pattern, err)
+			}
+			compiled.matchers = append(compiled.matchers, matcher)
+		}
+		if len(compiled.matchers) == 0 {
+			return nil, fmt.Errorf("rule '%s' must have at least one keyword or pattern", rule.Name)
+		}
+		classifier.rules = append(classifier.rules, compiled)
+	}
+	return classifier, nil
+}
+
+// NewDefaultRuleClassifier creates a rule-based classifier using the built-in rules
+func NewDefaultRuleClassifier() *RuleClassifier {
+	classifier, err := NewRuleClassifier(DefaultRuleSet())
+	if err != nil {
+		panic("services: invalid embedded default rules: " + err.Error())
+	}
+	return classifier
+}
+
+// newRuleClassifierFromFile creates a rule-based classifier from a YAML file,
+// or from the built-in rules when no file is configured
+func newRuleClassifierFromFile(path string) (*RuleClassifier, error) {
+	if path == "" {
+		return NewDefaultRuleClassifier(), nil
+	}
+	ruleSet, err := LoadRuleSet(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuleClassifier(ruleSet)
+}
+
+// Name identifies the provider behind the classifier
+func (c *RuleClassifier) Name() string {
+	return ProviderRules
+}
+
+// Classify scores the incident against every rule and picks the highest-scoring
+// severity and category, reporting which rules fired
+func (c *RuleClassifier) Classify(ctx context.Context, title, description string) (*AIAnalysisResult, error) {
+	text := title + "\n" + description
+	taxonomy := model.CurrentTaxonomy()
+	severityScores := make(map[string]float64)
+	categoryScores := make(map[string]float64)
+	var severityOrder, categoryOrder []string
+	var fired []RuleMatch
+
+	for _, rule := range c.rules {
+		matches := rule.match(text)
+		if len(matches) == 0 {
+			continue
+		}
+
+		fired = append(fired, RuleMatch{
+			Rule:     rule.Name,
+			Matches:  matches,
+			Severity: rule.Severity,
+			Category: rule.Category,
+			Weight:   rule.Weight,
+		})
+		// Values removed from the taxonomy no longer receive votes
+		if taxonomy.Contains(model.TaxonomySeverity, rule.Severity) {
+			if _, seen := severityScores[rule.Severity]; !seen {
+				severityOrder = append(severityOrder, rule.Severity)
+			}
+			severityScores[rule.Severity] += rule.Weight
+		}
+		if taxonomy.Contains(model.TaxonomyCategory, rule.Category) {
+			if _, seen := categoryScores[rule.Category]; !seen {
+				categoryOrder = append(categoryOrder, rule.Category)
+			}
+			categoryScores[rule.Category] += rule.Weight
+		}
+	}
+
+	severity := highestScore(severityScores, severityOrder, c.fallback(taxonomy, model.TaxonomySeverity, c.defaultSeverity))
+	category := highestScore(categoryScores, categoryOrder, c.fallback(taxonomy, model.TaxonomyCategory, c.defaultCategory))
+	return &AIAnalysisResult{
+		Severity:     severity,
+		Category:     category,
+		Confidence:   (scoreShare(severityScores, severity) + scoreShare(categoryScores, category)) / 2,
+		Rationale:    explainRules(fired),
+		Provider:     ProviderRules,
+		MatchedRules: fired,
+	}, nil
+}
+
+// fallback returns the rule set default of a kind when it is part of the taxonomy, or the taxonomy default
+func (c *RuleClassifier) fallback(taxonomy *model.Taxonomy, kind, configured string) string {
+	if configured != "" && taxonomy.Contains(kind, configured) {
+		return configured
+	}
+	return taxonomy.Default(kind)
+}
+
+// scoreShare returns the winner's share of the total score, or 0 when no rule voted
+func scoreShare(scores map[string]float64, winner string) float64 {
+	total := 0.0
+	for _, score := range scores {
+		total += score
+	}
+	if total == 0 {
+		return 0
+	}
+	return scores[winner] / total
+}
+
+// explainRules summarizes the fired rules in a sentence
+func explainRules(fired []RuleMatch) string {
+	if len(fired) == 0 {
+		return "No rules matched; default severity and category were used."
+	}
+	parts := make([]string, 0, len(fired))
+	for _, match := range fired {
+		parts = append(parts, fmt.Sprintf("%s (%s)", match.Rule, strings.Join(match.Matches, ", ")))
+	}
+	return "Matched rules: " + strings.Join(parts, "; ") + "."
+}
+
+// match returns the distinct lower-cased text fragments that triggered the rule
+func (r *compiledRule) match(text string) []string {
+	var matches []string
+	seen := make(map[string]bool)
+	for _, matcher := range r.matchers {
+		for _, match := range matcher.FindAllString(text, -1) {
+			match = strings.ToLower(match)
+			if !seen[match] {
+				seen[match] = true
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// highestScore returns the key with the highest score, breaking ties by the order of candidates
+func highestScore(scores map[string]float64, candidates []string, fallback string) string {
+	best, bestScore := fallback, 0.0
+	for _, candidate := range candidates {
+		if scores[candidate] > bestScore {
+			best, bestScore = candidate, scores[candidate]
+		}
+	}
+	return best
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRuleClassifier_Classify(t *testing.T) {
+	classifier := NewDefaultRuleClassifier()
+
+	tests := []struct {
+		name             string
+		title            string
+		description      string
+		expectedSeverity string
+		expectedCategory string
+	}{
+		{
+			name:             "Network outage",
+			title:            "DNS outage",
+			description:      "Resolvers are down for all users",
+			expectedSeverity: "high",
+			expectedCategory: "network",
+		},
+		{
+			name:             "Security breach",
+			title:            "Possible breach",
+			description:      "Unauthorized login detected on the admin panel",
+			expectedSeverity: "high",
+			expectedCategory: "security",
+		},
+		{
+			name:             "Cosmetic bug",
+			title:            "Typo on settings page",
+			description:      "Minor cosmetic bug in the footer",
+			expectedSeverity: "low",
+			expectedCategory: "software",
+		},
+		{
+			name:             "Hardware",
+			title:            "Disk failing on db-02",
+			description:      "SMART errors reported, performance is degraded",
+			expectedSeverity: "medium",
+			expectedCategory: "hardware",
+		},
+		{
+			name:             "No rule fires",
+			title:            "Something happened",
+			description:      "Please take a look",
+			expectedSeverity: "medium",
+			expectedCategory: "software",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := classifier.Classify(context.Background(), tt.title, tt.description)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Severity != tt.expectedSeverity {
+				t.Errorf("Expected severity '%s', got '%s'", tt.expectedSeverity, result.Severity)
+			}
+			if result.Category != tt.expectedCategory {
+				t.Errorf("Expected category '%s', got '%s'", tt.expectedCategory, result.Category)
+			}
+		})
+	}
+}
+
+func TestRuleClassifier_WordBoundaries(t *testing.T) {
+	classifier, err := NewRuleClassifier(RuleSet{Rules: []ClassificationRule{
+		{Name: "fan", Keywords: []string{"fan"}, Category: "hardware"},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result, _ := classifier.Classify(context.Background(), "Fantastic release", "Nothing to report")
+	if result.Category != "software" {
+		t.Errorf("Expected keywords to match whole words only, got category '%s'", result.Category)
+	}
+}
+
+func TestRuleClassifier_ExplainsMatches(t *testing.T) {
+	classifier := NewDefaultRuleClassifier()
+
+	result, err := classifier.Classify(context.Background(), "Patch CVE-2024-3094", "Exploit attempts seen in production")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Category != "security" {
+		t.Errorf("Expected category 'security', got '%s'", result.Category)
+	}
+	if result.Provider != ProviderRules {
+		t.Errorf("Expected provider '%s', got '%s'", ProviderRules, result.Provider)
+	}
+
+	var securityRule *RuleMatch
+	for i := range result.MatchedRules {
+		if result.MatchedRules[i].Rule == "security-threat" {
+			securityRule = &result.MatchedRules[i]
+		}
+	}
+	if securityRule == nil {
+		t.Fatalf("Expected security-threat rule to fire, got %+v", result.MatchedRules)
+	}
+	if len(securityRule.Matches) != 2 {
+		t.Errorf("Expected keyword and pattern matches, got %v", securityRule.Matches)
+	}
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	rules := `
+default_severity: low
+default_category: network
+rules:
+  - name: database
+    keywords: [postgres, replication lag]
+    patterns: ['\bdeadlock(s)?\b']
+    severity: high
+    category: software
+    weight: 5
+`
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+
+	ruleSet, err := LoadRuleSet(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	classifier, err := NewRuleClassifier(ruleSet)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result, _ := classifier.Classify(context.Background(), "Deadlocks on orders table", "Checkout writes are failing")
+	if result.Severity != "high" || result.Category != "software" {
+		t.Errorf("Expected high/software, got %s/%s", result.Severity, result.Category)
+	}
+
+	result, _ = classifier.Classify(context.Background(), "Nothing matches", "Quiet day")
+	if result.Severity != "low" || result.Category != "network" {
+		t.Errorf("Expected configured defaults low/network, got %s/%s", result.Severity, result.Category)
+	}
+}
+
+func TestNewRuleClassifier_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule ClassificationRule
+	}{
+		{"Invalid pattern", ClassificationRule{Name: "bad", Patterns: []string{"("}, Category: "network"}},
+		{"No outcome", ClassificationRule{Name: "empty", Keywords: []string{"dns"}}},
+		{"No matchers", ClassificationRule{Name: "blind", Category: "network"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRuleClassifier(RuleSet{Rules: []ClassificationRule{tt.rule}}); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
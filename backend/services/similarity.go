@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSimilarLimit is the number of similar incidents returned by default
+	DefaultSimilarLimit = 5
+	// MaxSimilarLimit caps the number of similar incidents per request
+	MaxSimilarLimit = 50
+)
+
+// SimilarIncident is a past incident that resembles the one queried
+type SimilarIncident struct {
+	Incident model.Incident `json:"incident"`
+	// Score is the cosine similarity of the two incidents' embeddings
+	Score float64 `json:"score"`
+}
+
+// SimilarityService finds incidents that look alike using text embeddings
+type SimilarityService struct {
+	incidents  *repository.IncidentRepository
+	embeddings *repository.EmbeddingRepository
+	embedder   Embedder
+}
+
+// NewSimilarityService creates a new similarity service
+func NewSimilarityService(embedder Embedder) *SimilarityService {
+	return &SimilarityService{
+		incidents:  repository.NewIncidentRepository(),
+		embeddings: repository.NewEmbeddingRepository(),
+		embedder:   embedder,
+	}
+}
+
+// FindSimilar returns the k incidents most similar to an incident, best first.
+// The incident is embedded first if its embedding is missing or out of date.
+func (s *SimilarityService) FindSimilar(ctx context.Context, incidentID string, k int, minScore float64) ([]SimilarIncident, error) {
+	return s.FindSimilarWithStatus(ctx, incidentID, k, minScore, nil)
+}
+
+// FindSimilarWithStatus is FindSimilar restricted to incidents with one of the given statuses;
+// empty statuses include every incident
+func (s *SimilarityService) FindSimilarWithStatus(ctx context.Context, incidentID string, k int, minScore float64, statuses []string) ([]SimilarIncident, error) {
+	incident, err := s.incidents.GetByID(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		k = DefaultSimilarLimit
+	}
+
+	target, err := s.embeddings.Get(incidentID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && staleEmbedding(s.embedder, target, incident)) {
+		if budgetSpent(s.embedder) {
+			return s.findSimilarLocally(ctx, incident, k, minScore, statuses)
+		}
+		if err := s.indexIncidents(ctx, []model.Incident{*incident}); err != nil {
+			return nil, err
+		}
+		target, err = s.embeddings.Get(incidentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.embeddings.ListByModel(s.embedder.Model(), incidentID, statuses)
+	if err != nil {
+		return nil, err
+	}
+	query := model.DecodeVector(target.Vector)
+	ids := make([]string, len(candidates))
+	vectors := make([][]float32, len(candidates))
+	for i := range candidates {
+		ids[i] = candidates[i].IncidentID
+		vectors[i] = model.DecodeVector(candidates[i].Vector)
+	}
+	if s.embedder.Name() == ProviderLocal {
+		applyIDF(query, vectors)
+	}
+	return s.rankSimilar(query, ids, vectors, k, minScore)
+}
+
+// findSimilarLocally compares an incident with every other incident using local embeddings
+// computed for this query only. It answers queries while a paid embedder's budget is spent,
+// without replacing the stored embeddings.
+func (s *SimilarityService) findSimilarLocally(ctx context.Context, incident *model.Incident, k int, minScore float64, statuses []string) ([]SimilarIncident, error) {
+	all, err := s.incidents.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	texts := []string{embeddingText(incident)}
+	var ids []string
+	for i := range all {
+		if all[i].ID == incident.ID || (len(statuses) > 0 && !containsString(statuses, all[i].Status)) {
+			continue
+		}
+		texts = append(texts, embeddingText(&all[i]))
+		ids = append(ids, all[i].ID)
+	}
+
+	vectors, err := NewLocalEmbedder().Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	query := vectors[0]
+	applyIDF(query, vectors[1:])
+	return s.rankSimilar(query, ids, vectors[1:], k, minScore)
+}
+
+// rankSimilar scores the candidate vectors against the query and returns the k best
+// incidents scoring at least minScore
+func (s *SimilarityService) rankSimilar(query []float32, ids []string, vectors [][]float32, k int, minScore float64) ([]SimilarIncident, error) {
+	type scored struct {
+		incidentID string
+		score      float64
+	}
+	var matches []scored
+	for i := range ids {
+		score := cosineSimilarity(query, vectors[i])
+		if score > 0 && score >= minScore {
+			matches = append(matches, scored{incidentID: ids[i], score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+
+	matchedIDs := make([]string, len(matches))
+	for i, match := range matches {
+		matchedIDs[i] = match.incidentID
+	}
+	found, err := s.incidents.GetByIDs(matchedIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.Incident, len(found))
+	for _, candidate := range found {
+		byID[candidate.ID] = candidate
+	}
+
+	similar := make([]SimilarIncident, 0, len(matches))
+	for _, match := range matches {
+		// An incident deleted since its embedding was listed is left out
+		if candidate, ok := byID[match.incidentID]; ok {
+			similar = append(similar, SimilarIncident{Incident: candidate, Score: match.score})
+		}
+	}
+	return similar, nil
+}
+
+// IndexIncidents embeds incidents whose text changed and stores the vectors. Incidents
+// whose text is unchanged only have their embedding marked as up to date. Nothing is
+// embedded while a paid embedder's budget is spent.
+func (s *SimilarityService) IndexIncidents(ctx context.Context, incidents []model.Incident) error {
+	if budgetSpent(s.embedder) {
+		return nil
+	}
+	return s.indexIncidents(ctx, incidents)
+}
+
+// indexIncidents is IndexIncidents without the budget check
+func (s *SimilarityService) indexIncidents(ctx context.Context, incidents []model.Incident) error {
+	var texts []string
+	var pending []model.IncidentEmbedding
+	for _, incident := range incidents {
+		text := embeddingText(&incident)
+		hash := textHash(text)
+
+		existing, err := s.embeddings.Get(incident.ID)
+		if err == nil && existing.Model == s.embedder.Model() && existing.TextHash == hash {
+			existing.SourceUpdatedAt = incident.UpdatedAt
+			if err := s.embeddings.Save(existing); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		texts = append(texts, text)
+		pending = append(pending, model.IncidentEmbedding{
+			IncidentID:      incident.ID,
+			Provider:        s.embedder.Name(),
+			Model:           s.embedder.Model(),
+			TextHash:        hash,
+			SourceUpdatedAt: incident.UpdatedAt,
+		})
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		pending[i].Dimensions = len(vectors[i])
+		pending[i].Vector = model.EncodeVector(vectors[i])
+		if err := s.embeddings.Save(&pending[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexPending embeds up to limit incidents that have no current embedding, returning how
+// many were processed. While a paid embedder's budget is spent nothing is processed, so the
+// stored embeddings are kept until the budget allows calls again.
+func (s *SimilarityService) IndexPending(ctx context.Context, limit int) (int, error) {
+	if budgetSpent(s.embedder) {
+		return 0, nil
+	}
+	incidents, err := s.embeddings.ListStale(s.embedder.Model(), limit)
+	if err != nil || len(incidents) == 0 {
+		return 0, err
+	}
+	return len(incidents), s.indexIncidents(ctx, incidents)
+}
+
+// staleEmbedding reports whether an embedding predates the incident's last change or is of
+// another model than the embedder's
+func staleEmbedding(embedder Embedder, embedding *model.IncidentEmbedding, incident *model.Incident) bool {
+	return embedding.Model != embedder.Model() || embedding.SourceUpdatedAt.Before(incident.UpdatedAt)
+}
+
+// embeddingText is the text of an incident that is embedded
+func embeddingText(incident *model.Incident) string {
+	return incident.Title + "\n" + incident.Description
+}
+
+// textHash returns the hex SHA-256 of text
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// IndexerConfig controls the background embedding indexer
+type IndexerConfig struct {
+	// Interval is how often the indexer looks for incidents without a current embedding
+	Interval time.Duration
+	// BatchSize is the number of incidents embedded per request
+	BatchSize int
+}
+
+// IndexerConfigFromEnv reads AI_EMBEDDING_INTERVAL and AI_EMBEDDING_BATCH_SIZE
+func IndexerConfigFromEnv() IndexerConfig {
+	config := IndexerConfig{Interval: 5 * time.Second, BatchSize: 32}
+	if value, err := time.ParseDuration(os.Getenv("AI_EMBEDDING_INTERVAL")); err == nil && value > 0 {
+		config.Interval = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("AI_EMBEDDING_BATCH_SIZE")); err == nil && value > 0 {
+		config.BatchSize = value
+	}
+	return config
+}
+
+// EmbeddingIndexer keeps incident embeddings up to date in the background
+type EmbeddingIndexer struct {
+	config     IndexerConfig
+	similarity *SimilarityService
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewEmbeddingIndexer creates a background indexer for the given embedder
+func NewEmbeddingIndexer(embedder Embedder, config IndexerConfig) *EmbeddingIndexer {
+	return &EmbeddingIndexer{
+		config:     config,
+		similarity: NewSimilarityService(embedder),
+	}
+}
+
+// Start launches the indexer goroutine
+func (i *EmbeddingIndexer) Start(ctx context.Context) {
+	ctx, i.cancel = context.WithCancel(ctx)
+	i.wg.Add(1)
+	go i.run(ctx)
+}
+
+// Stop signals the indexer to exit and waits for the current batch to finish
+func (i *EmbeddingIndexer) Stop() {
+	if i.cancel != nil {
+		i.cancel()
+	}
+	i.wg.Wait()
+}
+
+// run indexes batches until none are left, then sleeps until the next interval
+func (i *EmbeddingIndexer) run(ctx context.Context) {
+	defer i.wg.Done()
+	ticker := time.NewTicker(i.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		processed, err := i.similarity.IndexPending(ctx, i.config.BatchSize)
+		if err != nil {
+			log.Println("Embedding indexer error:", err)
+		}
+		if processed == i.config.BatchSize && err == nil && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
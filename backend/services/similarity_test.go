@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalEmbedder(t *testing.T) {
+	embedder := NewLocalEmbedder()
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"Zephyr DNS resolution failing in eu-west",
+		"Zephyr DNS lookups timing out in eu-west",
+		"Disk array degraded on storage node",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(vectors) != 3 || len(vectors[0]) != LocalEmbeddingDimensions {
+		t.Fatalf("Expected 3 vectors of %d dimensions", LocalEmbeddingDimensions)
+	}
+
+	related := cosineSimilarity(vectors[0], vectors[1])
+	unrelated := cosineSimilarity(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Errorf("Expected related incidents to score higher: related %.3f, unrelated %.3f", related, unrelated)
+	}
+	if embedder.Model() != "tfidf-hash-1024" {
+		t.Errorf("Unexpected local model name %q", embedder.Model())
+	}
+}
+
+func TestVectorEncoding(t *testing.T) {
+	vector := []float32{0.25, -1.5, 0, 3.75}
+	decoded := model.DecodeVector(model.EncodeVector(vector))
+	for i := range vector {
+		if decoded[i] != vector[i] {
+			t.Fatalf("Expected %v after a round trip, got %v", vector, decoded)
+		}
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var request map[string]interface{}
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/v1/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		// Answer out of order to check that vectors are matched by index
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"object": "list",
+			"model":  "nomic-embed-text",
+			"data": []map[string]interface{}{
+				{"object": "embedding", "index": 1, "embedding": []float32{0, 1}},
+				{"object": "embedding", "index": 0, "embedding": []float32{1, 0}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(EmbeddingConfig{Provider: ProviderOpenAICompatible, BaseURL: server.URL + "/v1", Model: "nomic-embed-text"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	vectors, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Expected vectors in input order, got %v", vectors)
+	}
+	if request["model"] != "nomic-embed-text" {
+		t.Errorf("Expected the configured model in the request, got %v", request["model"])
+	}
+
+	if _, err := NewEmbedder(EmbeddingConfig{Provider: ProviderOpenAICompatible, BaseURL: server.URL + "/v1"}); err == nil {
+		t.Error("Expected an OpenAI-compatible embedder without a model to be rejected")
+	}
+
+	// Once the monthly budget is spent the provider is not called and the local embedder takes over
+	meter := NewUsageMeter(DefaultPriceTable(), 1)
+	meter.month = monthStart(meter.now()).Format("2006-01")
+	meter.monthCost = 2
+	embedder.(*OpenAIEmbedder).usage = meter
+	if _, err := embedder.Embed(context.Background(), []string{"third"}); !errors.Is(err, ErrEmbeddingUnavailable) || !strings.Contains(err.Error(), "budget") {
+		t.Errorf("Expected the budget to stop the call, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 provider call, got %d", calls)
+	}
+	if !budgetSpent(embedder) {
+		t.Error("Expected the embedder to report its budget as spent")
+	}
+}
+
+func TestFindSimilar(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	incidents := NewIncidentService()
+	create := func(title, description string) *model.Incident {
+		incident, err := incidents.CreateIncident(model.Incident{Title: title, Description: description})
+		if err != nil {
+			t.Fatalf("Failed to create test incident: %v", err)
+		}
+		return incident
+	}
+	target := create("Quokka gateway returning 502", "Quokka gateway upstream pool unhealthy, customers see 502 errors")
+	related := create("Quokka gateway 502 errors again", "Quokka gateway upstream pool flapping, 502 for customers")
+	unrelated := create("Printer jammed on floor three", "Office printer paper jam")
+
+	service := NewSimilarityService(NewLocalEmbedder())
+	if _, err := service.IndexPending(context.Background(), 1000); err != nil {
+		t.Fatalf("Failed to index incidents: %v", err)
+	}
+
+	similar, err := service.FindSimilar(context.Background(), target.ID, 3, 0)
+	if err != nil {
+		t.Fatalf("Failed to find similar incidents: %v", err)
+	}
+	if len(similar) == 0 || similar[0].Incident.ID != related.ID {
+		t.Fatalf("Expected %s to be the most similar incident, got %+v", related.ID, similar)
+	}
+	for _, match := range similar {
+		if match.Incident.ID == target.ID {
+			t.Error("Expected the incident itself to be excluded")
+		}
+		if match.Incident.ID == unrelated.ID && match.Score >= similar[0].Score {
+			t.Errorf("Expected the unrelated incident to score lower, got %.3f", match.Score)
+		}
+	}
+	if len(similar) > 3 {
+		t.Errorf("Expected at most 3 results, got %d", len(similar))
+	}
+
+	// A minimum score filters weak matches
+	strict, err := service.FindSimilar(context.Background(), target.ID, 3, 0.99)
+	if err != nil {
+		t.Fatalf("Failed to find similar incidents: %v", err)
+	}
+	if len(strict) != 0 {
+		t.Errorf("Expected no matches above 0.99, got %d", len(strict))
+	}
+
+	// Editing the text makes the stored embedding stale
+	edited := *unrelated
+	edited.Title = "Quokka gateway 502 on checkout"
+	edited.Description = "Quokka gateway upstream pool unhealthy during checkout, 502 errors"
+	if _, err := incidents.UpdateIncident(unrelated.ID, edited, "tester"); err != nil {
+		t.Fatalf("Failed to update incident: %v", err)
+	}
+	if processed, err := service.IndexPending(context.Background(), 1000); err != nil || processed == 0 {
+		t.Fatalf("Expected the edited incident to be re-indexed, got %d %v", processed, err)
+	}
+	stored, err := service.embeddings.Get(unrelated.ID)
+	if err != nil || stored.TextHash != textHash(embeddingText(&edited)) {
+		t.Errorf("Expected the embedding of the edited text, got %v %v", stored, err)
+	}
+}
+
+func TestSimilarity_BudgetBoundary(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// The provider answers with local vectors and counts the texts it was paid to embed
+	var embedded int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		embedded += len(request.Input)
+		vectors, _ := NewLocalEmbedder().Embed(r.Context(), request.Input)
+		data := make([]map[string]interface{}, len(vectors))
+		for i, vector := range vectors {
+			data[i] = map[string]interface{}{"object": "embedding", "index": i, "embedding": vector}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "model": "budget-embed", "data": data})
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(EmbeddingConfig{Provider: ProviderOpenAICompatible, BaseURL: server.URL + "/v1", Model: "budget-embed"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	clock := time.Date(2031, 1, 20, 12, 0, 0, 0, time.UTC)
+	meter := NewUsageMeter(DefaultPriceTable(), 1)
+	meter.now = func() time.Time { return clock }
+	embedder.(*OpenAIEmbedder).usage = meter
+	service := NewSimilarityService(embedder)
+
+	incidents := NewIncidentService()
+	create := func(title, description string) *model.Incident {
+		incident, err := incidents.CreateIncident(model.Incident{Title: title, Description: description})
+		if err != nil {
+			t.Fatalf("Failed to create test incident: %v", err)
+		}
+		return incident
+	}
+	storedModel := func(incidentID string) string {
+		stored, err := service.embeddings.Get(incidentID)
+		if err != nil {
+			return err.Error()
+		}
+		return stored.Model
+	}
+	gateway := create("Wombat gateway returning 502", "Wombat gateway upstream pool unhealthy")
+	if _, err := service.IndexPending(context.Background(), 1000); err != nil {
+		t.Fatalf("Failed to index incidents: %v", err)
+	}
+	if got := storedModel(gateway.ID); got != "budget-embed" {
+		t.Fatalf("Expected a paid embedding, got %s", got)
+	}
+
+	// Once the budget is spent, nothing is indexed and queries are answered with local vectors
+	meter.month = monthStart(clock).Format("2006-01")
+	meter.monthCost = 2
+	embedded = 0
+	repeat := create("Wombat gateway 502 errors again", "Wombat gateway upstream pool flapping")
+	if processed, err := service.IndexPending(context.Background(), 1000); err != nil || processed != 0 {
+		t.Errorf("Expected no indexing while the budget is spent, got %d %v", processed, err)
+	}
+	similar, err := service.FindSimilar(context.Background(), repeat.ID, 3, 0)
+	if err != nil {
+		t.Fatalf("Failed to find similar incidents: %v", err)
+	}
+	if len(similar) == 0 || similar[0].Incident.ID != gateway.ID {
+		t.Errorf("Expected %s to be the most similar incident, got %+v", gateway.ID, similar)
+	}
+	if got := storedModel(gateway.ID); got != "budget-embed" {
+		t.Errorf("Expected the paid embedding to be kept, got %s", got)
+	}
+	if got := storedModel(repeat.ID); got != repository.ErrNotFound.Error() {
+		t.Errorf("Expected no embedding to be stored for the query, got %s", got)
+	}
+
+	// In the next month only the incident that was never embedded is paid for
+	clock = time.Date(2031, 2, 1, 0, 5, 0, 0, time.UTC)
+	if _, err := service.IndexPending(context.Background(), 1000); err != nil {
+		t.Fatalf("Failed to index incidents: %v", err)
+	}
+	if embedded != 1 {
+		t.Errorf("Expected only the new incident to be embedded after the rollover, got %d texts", embedded)
+	}
+	if got := storedModel(repeat.ID); got != "budget-embed" {
+		t.Errorf("Expected a paid embedding for the new incident, got %s", got)
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"io"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Output modes control how the model is asked to return its classification
+const (
+	// OutputModeTools forces a call to a classify_incident function whose parameters are the schema
+	OutputModeTools = "tools"
+	// OutputModeJSONObject requests a JSON object response, for servers without tool calling
+	OutputModeJSONObject = "json_object"
+	// OutputModeText sends no format hint; the prompt alone asks for JSON
+	OutputModeText = "text"
+)
+
+// DefaultOutputRetries is how many times an invalid model answer is re-requested
+const DefaultOutputRetries = 1
+
+// classifyFunctionName is the function the model calls in tools mode
+const classifyFunctionName = "classify_incident"
+
+// defaultOutputModes selects the output mode of each provider unless AI_OUTPUT_MODE is set
+var defaultOutputModes = map[string]string{
+	ProviderOpenAI:           OutputModeTools,
+	ProviderOpenAICompatible: OutputModeJSONObject,
+}
+
+// ErrInvalidModelOutput is returned when the model's answer does not match the classification schema
+var ErrInvalidModelOutput = errors.New("model output does not match the classification schema")
+
+// modelOutput is the classification the model must return
+type modelOutput struct {
+	Severity   *string  `json:"severity"`
+	Category   *string  `json:"category"`
+	Confidence *float64 `json:"confidence"`
+	Rationale  *string  `json:"rationale"`
+}
+
+// classificationSchema returns the JSON schema of the model output for the taxonomy in effect
+func classificationSchema(taxonomy *model.Taxonomy) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"severity": map[string]interface{}{
+				"type": "string",
+				"enum": taxonomy.Values(model.TaxonomySeverity),
+			},
+			"category": map[string]interface{}{
+				"type": "string",
+				"enum": taxonomy.Values(model.TaxonomyCategory),
+			},
+			"confidence": map[string]interface{}{
+				"type":        "number",
+				"description": "Confidence in the classification, from 0 to 1",
+			},
+			"rationale": map[string]interface{}{
+				"type":        "string",
+				"description": "One or two sentences explaining the choice",
+			},
+		},
+		"required":             []string{"severity", "category"},
+		"additionalProperties": false,
+	}
+}
+
+// validOutputMode reports whether mode is a known output mode
+func validOutputMode(mode string) bool {
+	switch mode {
+	case OutputModeTools, OutputModeJSONObject, OutputModeText:
+		return true
+	}
+	return false
+}
+
+// chatRequest builds the completion request for a prompt in the service's output mode
+func (s *AIService) chatRequest(prompt string, taxonomy *model.Taxonomy) openai.ChatCompletionRequest {
+	request := openai.ChatCompletionRequest{
+		Model: s.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		},
+		Temperature: 0.1, // Low temperature for more consistent results
+	}
+
+	switch s.outputMode {
+	case OutputModeTools:
+		request.Tools = []openai.Tool{{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        classifyFunctionName,
+				Description: "Record the severity and category of the incident",
+				Parameters:  classificationSchema(taxonomy),
+			},
+		}}
+		request.ToolChoice = openai.ToolChoice{
+			Type:     openai.ToolTypeFunction,
+			Function: openai.ToolFunction{Name: classifyFunctionName},
+		}
+	case OutputModeJSONObject:
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+	return request
+}
+
+// responseOutput returns the raw classification from a completion: the function
+// arguments in tools mode, the message content otherwise
+func (s *AIService) responseOutput(message openai.ChatCompletionMessage) (string, error) {
+	if s.outputMode == OutputModeTools {
+		for _, call := range message.ToolCalls {
+			if call.Function.Name == classifyFunctionName {
+				return call.Function.Arguments, nil
+			}
+		}
+		return "", fmt.Errorf("%w: no %s call in the response", ErrInvalidModelOutput, classifyFunctionName)
+	}
+	return message.Content, nil
+}
+
+// parseModelOutput strictly decodes and validates a classification against the schema
+func parseModelOutput(raw string, taxonomy *model.Taxonomy) (*AIAnalysisResult, error) {
+	var output modelOutput
+	if err := decodeModelJSON(raw, &output); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if output.Severity == nil {
+		problems = append(problems, "severity is missing")
+	} else if !taxonomy.Contains(model.TaxonomySeverity, strings.ToLower(*output.Severity)) {
+		problems = append(problems, fmt.Sprintf("severity %q is not one of %s", *output.Severity, strings.Join(taxonomy.Values(model.TaxonomySeverity), ", ")))
+	}
+	if output.Category == nil {
+		problems = append(problems, "category is missing")
+	} else if !taxonomy.Contains(model.TaxonomyCategory, strings.ToLower(*output.Category)) {
+		problems = append(problems, fmt.Sprintf("category %q is not one of %s", *output.Category, strings.Join(taxonomy.Values(model.TaxonomyCategory), ", ")))
+	}
+	if output.Confidence != nil && (*output.Confidence < 0 || *output.Confidence > 1) {
+		problems = append(problems, fmt.Sprintf("confidence %v is not between 0 and 1", *output.Confidence))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidModelOutput, strings.Join(problems, "; "))
+	}
+
+	result := &AIAnalysisResult{
+		Severity: strings.ToLower(*output.Severity),
+		Category: strings.ToLower(*output.Category),
+	}
+	if output.Confidence != nil {
+		result.Confidence = *output.Confidence
+	}
+	if output.Rationale != nil {
+		result.Rationale = *output.Rationale
+	}
+	return result, nil
+}
+
+// decodeModelJSON strictly decodes a JSON object answer into v: unknown fields and
+// trailing data are errors. Markdown code fences around the JSON are tolerated.
+func decodeModelJSON(raw string, v interface{}) error {
+	text := strings.TrimSpace(raw)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidModelOutput, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("%w: unexpected data after the JSON object", ErrInvalidModelOutput)
+	}
+	return nil
+}
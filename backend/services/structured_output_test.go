@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"incident-management/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseModelOutput(t *testing.T) {
+	taxonomy := model.CurrentTaxonomy()
+
+	valid := []struct {
+		name     string
+		raw      string
+		severity string
+		category string
+	}{
+		{"plain", `{"severity": "high", "category": "network", "confidence": 0.9, "rationale": "Router down"}`, "high", "network"},
+		{"uppercase values", `{"severity": "LOW", "category": "Security"}`, "low", "security"},
+		{"code fence", "```json\n{\"severity\": \"medium\", \"category\": \"hardware\"}\n```", "medium", "hardware"},
+	}
+	for _, tc := range valid {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := parseModelOutput(tc.raw, taxonomy)
+			if err != nil {
+				t.Fatalf("Expected valid output, got %v", err)
+			}
+			if result.Severity != tc.severity || result.Category != tc.category {
+				t.Errorf("Expected %s/%s, got %s/%s", tc.severity, tc.category, result.Severity, result.Category)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name string
+		raw  string
+	}{
+		{"prose", "The severity is high and the category is network."},
+		{"missing category", `{"severity": "high"}`},
+		{"unknown severity", `{"severity": "critical", "category": "network"}`},
+		{"unknown field", `{"severity": "high", "category": "network", "priority": 1}`},
+		{"confidence out of range", `{"severity": "high", "category": "network", "confidence": 7}`},
+		{"trailing text", `{"severity": "high", "category": "network"} I hope this helps!`},
+		{"wrong type", `{"severity": 3, "category": "network"}`},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseModelOutput(tc.raw, taxonomy); !errors.Is(err, ErrInvalidModelOutput) {
+				t.Errorf("Expected ErrInvalidModelOutput, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClassify_ToolsMode(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":    "chatcmpl-test",
+			"model": "test-model",
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"finish_reason": "tool_calls",
+				"message": map[string]interface{}{
+					"role": "assistant",
+					"tool_calls": []map[string]interface{}{{
+						"id":   "call_1",
+						"type": "function",
+						"function": map[string]string{
+							"name":      classifyFunctionName,
+							"arguments": `{"severity": "high", "category": "security", "confidence": 0.8}`,
+						},
+					}},
+				},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	aiService.outputMode = OutputModeTools
+
+	result, err := aiService.Classify(context.Background(), "Leaked credentials", "API keys pushed to a public repository")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fallback {
+		t.Fatalf("Expected model result, got fallback: %s", result.FallbackReason)
+	}
+	if result.Severity != "high" || result.Category != "security" || result.Confidence != 0.8 {
+		t.Errorf("Expected high/security at 0.8, got %s/%s at %v", result.Severity, result.Category, result.Confidence)
+	}
+
+	tools, _ := request["tools"].([]interface{})
+	if len(tools) != 1 {
+		t.Fatalf("Expected one tool in the request, got %v", request["tools"])
+	}
+	if _, ok := request["tool_choice"].(map[string]interface{}); !ok {
+		t.Errorf("Expected the classify function to be forced, got tool_choice %v", request["tool_choice"])
+	}
+}
+
+func TestClassify_RetriesInvalidOutputThenFallsBack(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":    "chatcmpl-test",
+			"model": "test-model",
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"finish_reason": "stop",
+				"message":       map[string]string{"role": "assistant", "content": "I think this is a high severity network issue."},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+	aiService.outputRetries = 2
+
+	result, err := aiService.Classify(context.Background(), "Ransomware detected", "Files encrypted on the finance share")
+	if err != nil {
+		t.Fatalf("Expected fallback result, got error %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+	if !result.Fallback || result.Provider != ProviderRules {
+		t.Errorf("Expected rules fallback, got provider '%s' fallback %v", result.Provider, result.Fallback)
+	}
+	if !strings.Contains(result.FallbackReason, ErrInvalidModelOutput.Error()) {
+		t.Errorf("Expected fallback reason to mention invalid output, got '%s'", result.FallbackReason)
+	}
+}
+
+func TestNewClassifier_OutputMode(t *testing.T) {
+	classifier, err := NewClassifier(ClassifierConfig{
+		Provider:   ProviderOpenAICompatible,
+		BaseURL:    "http://localhost:11434/v1",
+		Model:      "llama3",
+		OutputMode: OutputModeText,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mode := classifier.(*AIService).outputMode; mode != OutputModeText {
+		t.Errorf("Expected output mode '%s', got '%s'", OutputModeText, mode)
+	}
+
+	if _, err := NewClassifier(ClassifierConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:11434/v1", OutputMode: "xml"}); err == nil {
+		t.Error("Expected an unknown output mode to be rejected")
+	}
+}
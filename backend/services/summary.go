@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ProviderLocal identifies the built-in summarizer that needs no external service
+const ProviderLocal = "local"
+
+// timelineTimeFormat is how times appear in prompts and timeline digests
+const timelineTimeFormat = "2006-01-02 15:04 MST"
+
+// ErrNoSummary is returned when an incident has no summary yet
+var ErrNoSummary = errors.New("incident has no summary yet")
+
+// Summarizer writes stakeholder-friendly summaries of incidents
+type Summarizer interface {
+	// Name identifies the provider behind the summarizer
+	Name() string
+	// Summarize writes a summary and timeline digest of an incident
+	Summarize(ctx context.Context, input SummaryInput) (*SummaryResult, error)
+}
+
+// SummaryInput is everything known about an incident that goes into its summary and postmortem
+type SummaryInput struct {
+	Incident    model.Incident
+	Transitions []model.StatusTransition
+	// Comments are the responders' notes, oldest first
+	Comments []model.Comment
+}
+
+// SummaryResult is a generated summary
+type SummaryResult struct {
+	Summary string `json:"summary"`
+	// Timeline is the digest of key events, one line per event
+	Timeline      []string     `json:"timeline"`
+	Provider      string       `json:"provider"`
+	Model         string       `json:"model,omitempty"`
+	PromptVersion string       `json:"prompt_version,omitempty"`
+	Usage         openai.Usage `json:"usage"`
+	// Fallback is set when the local summarizer stood in for the configured provider
+	Fallback       bool   `json:"fallback"`
+	FallbackReason string `json:"fallback_reason,omitempty"`
+	// Redactions counts the values removed from the text sent to the provider
+	Redactions RedactionReport `json:"redactions,omitempty"`
+}
+
+// SummaryPromptData is the data available to summary prompt templates
+type SummaryPromptData struct {
+	Title       string
+	Description string
+	Status      string
+	Priority    string
+	Severity    string
+	Category    string
+	OpenedAt    string
+	// Events is the incident's history, oldest first
+	Events []TimelineEvent
+}
+
+// TimelineEvent is one entry of an incident's history
+type TimelineEvent struct {
+	At   string
+	Text string
+}
+
+// summaryOutput is the summary the model must return
+type summaryOutput struct {
+	Summary  *string  `json:"summary"`
+	Timeline []string `json:"timeline"`
+}
+
+// newSummaryPromptData gathers the prompt data of an incident
+func newSummaryPromptData(input SummaryInput) SummaryPromptData {
+	incident := input.Incident
+	return SummaryPromptData{
+		Title:       incident.Title,
+		Description: incident.Description,
+		Status:      incident.Status,
+		Priority:    incident.Priority,
+		Severity:    incident.EffectiveSeverity(),
+		Category:    incident.EffectiveCategory(),
+		OpenedAt:    formatTimelineTime(incident.CreatedAt),
+		Events:      timelineEvents(input),
+	}
+}
+
+// redact replaces sensitive values in the free-text fields of the prompt data
+func (data *SummaryPromptData) redact(redaction *RedactionScope) {
+	data.Title = redaction.Redact(data.Title)
+	data.Description = redaction.Redact(data.Description)
+	for i := range data.Events {
+		data.Events[i].Text = redaction.Redact(data.Events[i].Text)
+	}
+}
+
+// timelineEvents lists the status changes and comments of an incident, oldest first
+func timelineEvents(input SummaryInput) []TimelineEvent {
+	entries := make([]ActivityEntry, 0, len(input.Transitions)+len(input.Comments))
+	for _, transition := range input.Transitions {
+		entries = append(entries, ActivityEntry{Type: ActivityTransition, At: transition.CreatedAt, Text: transitionText(transition)})
+	}
+	for _, comment := range input.Comments {
+		entries = append(entries, ActivityEntry{Type: ActivityComment, At: comment.CreatedAt, Text: commentText(comment)})
+	}
+	sortActivity(entries)
+
+	events := make([]TimelineEvent, 0, len(entries))
+	for _, entry := range entries {
+		events = append(events, TimelineEvent{At: formatTimelineTime(entry.At), Text: entry.Text})
+	}
+	return events
+}
+
+// formatTimelineTime formats a time for prompts and timeline digests
+func formatTimelineTime(t time.Time) string {
+	return t.UTC().Format(timelineTimeFormat)
+}
+
+// LocalSummarizer writes summaries from the incident fields without a model. It is the
+// offline mode and fallback of the LLM providers.
+type LocalSummarizer struct{}
+
+// NewLocalSummarizer creates the built-in summarizer
+func NewLocalSummarizer() *LocalSummarizer {
+	return &LocalSummarizer{}
+}
+
+// Name identifies the local summarizer
+func (l *LocalSummarizer) Name() string {
+	return ProviderLocal
+}
+
+// Summarize restates the incident's title, the start of its description and its current state
+func (l *LocalSummarizer) Summarize(ctx context.Context, input SummaryInput) (*SummaryResult, error) {
+	data := newSummaryPromptData(input)
+
+	summary := strings.TrimRight(data.Title, ".") + "."
+	if lead := leadSentences(data.Description, 2, 300); lead != "" {
+		summary += " " + lead
+	}
+	summary += fmt.Sprintf(" The incident is %s with %s priority", strings.ReplaceAll(data.Status, "_", " "), data.Priority)
+	if data.Severity != "" && data.Category != "" {
+		summary += fmt.Sprintf(", classified as a %s severity %s issue", data.Severity, data.Category)
+	}
+	summary += "."
+
+	timeline := []string{data.OpenedAt + ": opened"}
+	for _, event := range data.Events {
+		timeline = append(timeline, event.At+": "+event.Text)
+	}
+
+	return &SummaryResult{
+		Summary:  summary,
+		Timeline: timeline,
+		Provider: ProviderLocal,
+	}, nil
+}
+
+// leadSentences returns up to count sentences from the start of text, cut at limit characters
+func leadSentences(text string, count, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	end := 0
+	for i := 0; i < count && end < len(text); i++ {
+		next := strings.IndexAny(text[end:], ".!?")
+		if next < 0 {
+			end = len(text)
+			break
+		}
+		end += next + 1
+	}
+	lead := text[:end]
+	if runes := []rune(lead); len(runes) > limit {
+		lead = strings.TrimSpace(string(runes[:limit])) + "..."
+	}
+	if lead != "" && !strings.ContainsAny(lead[len(lead)-1:], ".!?") {
+		lead += "."
+	}
+	return lead
+}
+
+// Summarize writes a summary with the chat completion API. The local summarizer is used
+// when no API key is configured, the monthly budget is spent, the provider fails or its
+// circuit breaker is open.
+func (s *AIService) Summarize(ctx context.Context, input SummaryInput) (*SummaryResult, error) {
+	return callModel(ctx, s, modelTask[*SummaryResult]{
+		operation: OperationSummarize,
+		noun:      "summary",
+		// The summary of redacted text keeps the placeholders; the model never sees the values
+		prompt: func(redaction *RedactionScope) (string, error) {
+			data := newSummaryPromptData(input)
+			data.redact(redaction)
+			return s.summaryPrompt.Render(data)
+		},
+		request: func(prompt string) openai.ChatCompletionRequest {
+			return s.jsonRequest(prompt, 0.3)
+		},
+		parse: func(message openai.ChatCompletionMessage) (*SummaryResult, error) {
+			return parseSummaryOutput(message.Content)
+		},
+		answered: func(result *SummaryResult, answer modelAnswer) {
+			result.Provider = answer.Provider
+			result.Model = answer.Model
+			result.PromptVersion = s.summaryPrompt.Version
+			result.Usage = answer.Usage
+			result.Redactions = answer.Redactions
+		},
+		fallback: func(ctx context.Context, cause error) (*SummaryResult, error) {
+			return s.summarizeWithFallback(ctx, input, cause)
+		},
+	})
+}
+
+// summarizeWithFallback summarizes with the local summarizer
+func (s *AIService) summarizeWithFallback(ctx context.Context, input SummaryInput, cause error) (*SummaryResult, error) {
+	result, err := NewLocalSummarizer().Summarize(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Fallback = true
+	result.FallbackReason = cause.Error()
+	return result, nil
+}
+
+// parseSummaryOutput strictly decodes a summary answer
+func parseSummaryOutput(raw string) (*SummaryResult, error) {
+	var output summaryOutput
+	if err := decodeModelJSON(raw, &output); err != nil {
+		return nil, err
+	}
+	if output.Summary == nil || strings.TrimSpace(*output.Summary) == "" {
+		return nil, fmt.Errorf("%w: summary is missing", ErrInvalidModelOutput)
+	}
+
+	timeline := make([]string, 0, len(output.Timeline))
+	for _, line := range output.Timeline {
+		if line = strings.TrimSpace(line); line != "" {
+			timeline = append(timeline, line)
+		}
+	}
+	return &SummaryResult{Summary: strings.TrimSpace(*output.Summary), Timeline: timeline}, nil
+}
+
+// NewSummarizer creates the summarizer of the configured provider. The rules provider
+// has no language model, so it is served by the local summarizer.
+func NewSummarizer(config ClassifierConfig) (Summarizer, error) {
+	if config.Provider == ProviderRules {
+		return NewLocalSummarizer(), nil
+	}
+	// Summaries never classify, so the service needs no fallback classifier
+	return newAIServiceFromConfig(config, nil)
+}
+
+// NewSummarizerFromEnv creates the configured summarizer, falling back to the
+// local summarizer when the configuration is invalid
+func NewSummarizerFromEnv() Summarizer {
+	summarizer, err := NewSummarizer(ClassifierConfigFromEnv())
+	if err != nil {
+		log.Println("Invalid AI summarizer configuration, using local summarizer:", err)
+		return NewLocalSummarizer()
+	}
+	return summarizer
+}
+
+// SummaryService generates and stores versioned incident summaries
+type SummaryService struct {
+	incidents   *repository.IncidentRepository
+	transitions *repository.TransitionRepository
+	comments    *repository.CommentRepository
+	summaries   *repository.SummaryRepository
+	summarizer  Summarizer
+}
+
+// NewSummaryService creates a new summary service
+func NewSummaryService(summarizer Summarizer) *SummaryService {
+	return &SummaryService{
+		incidents:   repository.NewIncidentRepository(),
+		transitions: repository.NewTransitionRepository(),
+		comments:    repository.NewCommentRepository(),
+		summaries:   repository.NewSummaryRepository(),
+		summarizer:  summarizer,
+	}
+}
+
+// GenerateSummary writes a new summary version from the incident's current state, history and comments
+func (s *SummaryService) GenerateSummary(ctx context.Context, incidentID, actor string) (*model.IncidentSummary, error) {
+	incident, err := s.incidents.GetByID(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := s.transitions.ListByIncident(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.comments.ListByIncident(incidentID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.summarizer.Summarize(ctx, SummaryInput{Incident: *incident, Transitions: transitions, Comments: comments})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := newSummaryRecord(incident.ID, actor, result)
+	if err := s.summaries.Create(summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// GetSummary retrieves a summary version of an incident; version 0 is the latest
+func (s *SummaryService) GetSummary(incidentID string, version int) (*model.IncidentSummary, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+
+	var summary *model.IncidentSummary
+	var err error
+	if version > 0 {
+		summary, err = s.summaries.GetVersion(incidentID, version)
+	} else {
+		summary, err = s.summaries.GetLatest(incidentID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNoSummary
+	}
+	return summary, err
+}
+
+// GetSummaries retrieves every summary version of an incident, newest first
+func (s *SummaryService) GetSummaries(incidentID string) ([]model.IncidentSummary, error) {
+	if _, err := s.incidents.GetByID(incidentID); err != nil {
+		return nil, err
+	}
+	return s.summaries.ListByIncident(incidentID)
+}
+
+// newSummaryRecord converts a summary result into a stored version
+func newSummaryRecord(incidentID, actor string, result *SummaryResult) *model.IncidentSummary {
+	timeline, _ := json.Marshal(result.Timeline)
+	summary := &model.IncidentSummary{
+		IncidentID:       incidentID,
+		Summary:          result.Summary,
+		Timeline:         timeline,
+		Provider:         result.Provider,
+		Model:            result.Model,
+		PromptVersion:    result.PromptVersion,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		Fallback:         result.Fallback,
+		FallbackReason:   result.FallbackReason,
+		Actor:            actor,
+	}
+	if len(result.Redactions) > 0 {
+		summary.Redactions, _ = json.Marshal(result.Redactions)
+	}
+	return summary
+}
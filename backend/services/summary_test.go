@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestLocalSummarizer(t *testing.T) {
+	opened := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	input := SummaryInput{
+		Incident: model.Incident{
+			Title:       "Checkout API returning 500s",
+			Description: "Payments fail for all EU customers. The error started after the 09:00 deploy. Rollback is being prepared.",
+			Status:      "in_progress",
+			Priority:    "high",
+			AISeverity:  "high",
+			AICategory:  "software",
+			CreatedAt:   opened,
+		},
+		Transitions: []model.StatusTransition{
+			{Action: "start", FromStatus: "open", ToStatus: "in_progress", Actor: "alice", CreatedAt: opened.Add(10 * time.Minute)},
+		},
+	}
+
+	result, err := NewLocalSummarizer().Summarize(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{"Checkout API returning 500s.", "Payments fail for all EU customers.", "in progress with high priority", "high severity software issue"} {
+		if !strings.Contains(result.Summary, want) {
+			t.Errorf("Expected summary to contain %q, got %q", want, result.Summary)
+		}
+	}
+	if strings.Contains(result.Summary, "Rollback") {
+		t.Errorf("Expected only the lead sentences of the description, got %q", result.Summary)
+	}
+	want := []string{"2024-03-01 09:00 UTC: opened", "2024-03-01 09:10 UTC: start, open to in_progress by alice"}
+	if len(result.Timeline) != len(want) || result.Timeline[0] != want[0] || result.Timeline[1] != want[1] {
+		t.Errorf("Expected timeline %v, got %v", want, result.Timeline)
+	}
+}
+
+func TestLeadSentences_CutsAtRunes(t *testing.T) {
+	// A byte cut through the multi-byte characters would leave invalid UTF-8 behind
+	lead := leadSentences(strings.Repeat("数据库连接失败", 60), 2, 300)
+	if !utf8.ValidString(lead) {
+		t.Fatalf("Expected valid UTF-8, got %q", lead)
+	}
+	if got := utf8.RuneCountInString(strings.TrimSuffix(lead, "...")); got != 300 {
+		t.Errorf("Expected the lead to be cut at 300 characters, got %d", got)
+	}
+}
+
+func TestAIServiceSummarize(t *testing.T) {
+	server := newFakeChatServer(t, `{"summary": "Payments are failing for EU customers; a rollback is underway.", "timeline": ["09:00 deploy", "09:10 investigation started"]}`)
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+
+	result, err := aiService.Summarize(context.Background(), SummaryInput{Incident: model.Incident{Title: "Checkout down", Description: "Payments fail"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fallback {
+		t.Fatalf("Expected model summary, got fallback: %s", result.FallbackReason)
+	}
+	if result.PromptVersion != DefaultSummaryPromptVersion || result.Provider != ProviderOpenAICompatible {
+		t.Errorf("Unexpected provenance: %+v", result)
+	}
+	if len(result.Timeline) != 2 || result.Usage.TotalTokens != 150 {
+		t.Errorf("Unexpected timeline or usage: %v %+v", result.Timeline, result.Usage)
+	}
+}
+
+func TestAIServiceSummarize_InvalidOutputFallsBack(t *testing.T) {
+	server := newFakeChatServer(t, "Payments are failing.")
+	aiService := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model")
+
+	result, err := aiService.Summarize(context.Background(), SummaryInput{Incident: model.Incident{Title: "Checkout down", Description: "Payments fail"}})
+	if err != nil {
+		t.Fatalf("Expected fallback summary, got error %v", err)
+	}
+	if !result.Fallback || result.Provider != ProviderLocal {
+		t.Errorf("Expected local fallback, got provider %s fallback %v", result.Provider, result.Fallback)
+	}
+}
+
+func TestGenerateSummary_Versions(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	incidents := NewIncidentService()
+	incident, err := incidents.CreateIncident(model.Incident{
+		Title:       "Summary versions incident",
+		Description: "Queue consumers are stuck",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+
+	service := NewSummaryService(NewLocalSummarizer())
+	if _, err := service.GetSummary(incident.ID, 0); !errors.Is(err, ErrNoSummary) {
+		t.Fatalf("Expected ErrNoSummary, got %v", err)
+	}
+
+	first, err := service.GenerateSummary(context.Background(), incident.ID, "alice")
+	if err != nil {
+		t.Fatalf("Failed to generate summary: %v", err)
+	}
+	if _, err := incidents.TransitionIncident(incident.ID, "start", "Investigating", "bob"); err != nil {
+		t.Fatalf("Failed to transition incident: %v", err)
+	}
+	second, err := service.GenerateSummary(context.Background(), incident.ID, "alice")
+	if err != nil {
+		t.Fatalf("Failed to regenerate summary: %v", err)
+	}
+
+	if first.Version != 1 || second.Version != 2 {
+		t.Errorf("Expected versions 1 and 2, got %d and %d", first.Version, second.Version)
+	}
+	var timeline []string
+	if err := json.Unmarshal(second.Timeline, &timeline); err != nil || len(timeline) != 2 {
+		t.Errorf("Expected the regenerated summary to include the transition, got %s", second.Timeline)
+	}
+
+	latest, err := service.GetSummary(incident.ID, 0)
+	if err != nil || latest.Version != 2 {
+		t.Errorf("Expected latest version 2, got %v %v", latest, err)
+	}
+	earlier, err := service.GetSummary(incident.ID, 1)
+	if err != nil || earlier.ID != first.ID {
+		t.Errorf("Expected version 1 to be kept, got %v %v", earlier, err)
+	}
+	summaries, err := service.GetSummaries(incident.ID)
+	if err != nil || len(summaries) != 2 || summaries[0].Version != 2 {
+		t.Errorf("Expected both versions newest first, got %v %v", summaries, err)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"incident-management/model"
+	"incident-management/repository"
+	"incident-management/utils"
+)
+
+var (
+	// ErrTaxonomyTermExists is returned when creating a value that is already defined
+	ErrTaxonomyTermExists = errors.New("taxonomy value already exists")
+	// ErrTaxonomyTermInUse is returned when deleting a value that incidents still reference
+	ErrTaxonomyTermInUse = errors.New("taxonomy value is used by existing incidents")
+)
+
+// TaxonomyService manages the severity and category taxonomies. Changes take effect
+// immediately for validation, the AI prompt and the fallback defaults.
+type TaxonomyService struct {
+	repo *repository.TaxonomyRepository
+}
+
+// TaxonomyTermUpdate holds the mutable fields of a taxonomy term; nil fields are left unchanged.
+// Values cannot be renamed because incidents store them.
+type TaxonomyTermUpdate struct {
+	Description *string `json:"description" validate:"omitempty,max=500"`
+	Position    *int    `json:"position"`
+	IsDefault   *bool   `json:"is_default"`
+}
+
+// NewTaxonomyService creates a new taxonomy service
+func NewTaxonomyService() *TaxonomyService {
+	return &TaxonomyService{
+		repo: repository.NewTaxonomyRepository(),
+	}
+}
+
+// LoadTaxonomy makes the taxonomy stored in the database the one in effect
+func LoadTaxonomy() error {
+	return NewTaxonomyService().reload()
+}
+
+// GetTaxonomy returns the taxonomy in effect
+func (s *TaxonomyService) GetTaxonomy() *model.Taxonomy {
+	return model.CurrentTaxonomy()
+}
+
+// CreateTerm adds a value to a taxonomy
+func (s *TaxonomyService) CreateTerm(term model.TaxonomyTerm) (*model.TaxonomyTerm, error) {
+	term.ID = 0
+	if validationErrors := utils.ValidateAndGetErrors(&term); validationErrors != nil {
+		return nil, &ValidationError{Details: validationErrors}
+	}
+
+	if _, err := s.repo.Get(term.Kind, term.Value); err == nil {
+		return nil, ErrTaxonomyTermExists
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.Save(&term); err != nil {
+		return nil, err
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+// UpdateTerm changes the description, position or default flag of a taxonomy value
+func (s *TaxonomyService) UpdateTerm(kind, value string, update TaxonomyTermUpdate) (*model.TaxonomyTerm, error) {
+	if validationErrors := utils.ValidateAndGetErrors(&update); validationErrors != nil {
+		return nil, &ValidationError{Details: validationErrors}
+	}
+
+	term, err := s.repo.Get(kind, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Description != nil {
+		term.Description = *update.Description
+	}
+	if update.Position != nil {
+		term.Position = *update.Position
+	}
+	if update.IsDefault != nil {
+		if term.IsDefault && !*update.IsDefault {
+			return nil, &ValidationError{Details: map[string]string{
+				"is_default": fmt.Sprintf("make another %s the default instead", kind),
+			}}
+		}
+		term.IsDefault = *update.IsDefault
+	}
+
+	if err := s.repo.Save(term); err != nil {
+		return nil, err
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// DeleteTerm removes a value from a taxonomy. The default value and values used by incidents cannot be deleted.
+func (s *TaxonomyService) DeleteTerm(kind, value string) error {
+	term, err := s.repo.Get(kind, value)
+	if err != nil {
+		return err
+	}
+	if term.IsDefault {
+		return &ValidationError{Details: map[string]string{
+			"is_default": fmt.Sprintf("the default %s cannot be deleted; make another %s the default first", kind, kind),
+		}}
+	}
+
+	count, err := s.repo.CountUsage(kind, value)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d incidents use %s %q", ErrTaxonomyTermInUse, count, kind, value)
+	}
+
+	if err := s.repo.Delete(term); err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// reload reads the taxonomy from the database and puts it into effect
+func (s *TaxonomyService) reload() error {
+	terms, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	model.SetTaxonomy(model.NewTaxonomy(terms))
+	return nil
+}
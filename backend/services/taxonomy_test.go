@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"incident-management/database"
+	"incident-management/model"
+	"testing"
+)
+
+func TestTaxonomyService(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	service := NewTaxonomyService()
+	if err := LoadTaxonomy(); err != nil {
+		t.Fatalf("Failed to load taxonomy: %v", err)
+	}
+	t.Cleanup(func() {
+		database.GetDB().Where("ai_category = ? OR human_category = ?", "database", "database").Delete(&model.Incident{})
+		database.GetDB().Where("kind = ? AND value = ?", model.TaxonomyCategory, "database").Delete(&model.TaxonomyTerm{})
+		isDefault := true
+		service.UpdateTerm(model.TaxonomyCategory, "software", TaxonomyTermUpdate{IsDefault: &isDefault})
+		model.SetTaxonomy(nil)
+	})
+
+	if got := service.GetTaxonomy().Values(model.TaxonomySeverity); len(got) != 3 {
+		t.Fatalf("Expected the seeded severities, got %v", got)
+	}
+
+	// Adding a category is a data change
+	if _, err := service.CreateTerm(model.TaxonomyTerm{Kind: model.TaxonomyCategory, Value: "database", Position: 4, Description: "Database outages"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.CreateTerm(model.TaxonomyTerm{Kind: model.TaxonomyCategory, Value: "database"}); !errors.Is(err, ErrTaxonomyTermExists) {
+		t.Errorf("Expected ErrTaxonomyTermExists, got %v", err)
+	}
+	if _, err := service.CreateTerm(model.TaxonomyTerm{Kind: model.TaxonomyCategory, Value: "Third Party"}); err == nil {
+		t.Error("Expected an error for a value with spaces and capitals")
+	}
+
+	// The new value is accepted by the AI service and by overrides
+	server := newFakeChatServer(t, `{"severity": "high", "category": "database"}`)
+	result, err := NewOpenAICompatibleService(server.URL+"/v1", "", "test-model").Classify(context.Background(), "Replica lag", "Primary database is not replicating")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Category != "database" || result.Fallback {
+		t.Errorf("Expected category 'database' without fallback, got %s (%s)", result.Category, result.FallbackReason)
+	}
+
+	incident, err := NewIncidentService().CreateIncident(model.Incident{Title: "Taxonomy incident", Description: "Replica lag"})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+	classification := NewClassificationService(NewDefaultRuleClassifier())
+	if _, _, err := classification.OverrideClassification(incident.ID, "", "database", "Replication issue", "tester"); err != nil {
+		t.Fatalf("Expected override to the new category to succeed, got %v", err)
+	}
+
+	// Values in use cannot be deleted
+	if err := service.DeleteTerm(model.TaxonomyCategory, "database"); !errors.Is(err, ErrTaxonomyTermInUse) {
+		t.Errorf("Expected ErrTaxonomyTermInUse, got %v", err)
+	}
+
+	// Changing the default moves the fallback for new incidents
+	isDefault := true
+	if _, err := service.UpdateTerm(model.TaxonomyCategory, "database", TaxonomyTermUpdate{IsDefault: &isDefault}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := model.CurrentTaxonomy().Default(model.TaxonomyCategory); got != "database" {
+		t.Errorf("Expected default category 'database', got '%s'", got)
+	}
+	created, err := NewIncidentService().CreateIncident(model.Incident{Title: "Default category incident", Description: "Uses the new default"})
+	if err != nil {
+		t.Fatalf("Failed to create test incident: %v", err)
+	}
+	if created.AICategory != "database" {
+		t.Errorf("Expected AI category 'database', got '%s'", created.AICategory)
+	}
+
+	// The default cannot be deleted or unset directly
+	if err := service.DeleteTerm(model.TaxonomyCategory, "database"); err == nil {
+		t.Error("Expected an error deleting the default category")
+	}
+	notDefault := false
+	if _, err := service.UpdateTerm(model.TaxonomyCategory, "database", TaxonomyTermUpdate{IsDefault: &notDefault}); err == nil {
+		t.Error("Expected an error unsetting the default category")
+	}
+}
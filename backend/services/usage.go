@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"incident-management/database"
+	"incident-management/model"
+	"incident-management/repository"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"gopkg.in/yaml.v3"
+)
+
+// Operations that AI usage is recorded for
+const (
+	OperationClassify   = "classify"
+	OperationSummarize  = "summarize"
+	OperationEmbed      = "embed"
+	OperationRemediate  = "remediate"
+	OperationPostmortem = "postmortem"
+)
+
+// ErrBudgetExceeded is returned when the monthly AI budget has been spent
+var ErrBudgetExceeded = errors.New("monthly AI budget exceeded")
+
+// ModelPrice is the price of a model in US dollars per million tokens
+type ModelPrice struct {
+	Prompt     float64 `yaml:"prompt" json:"prompt"`
+	Completion float64 `yaml:"completion" json:"completion"`
+}
+
+// PriceTable holds the prices of models by name. A model without an exact entry uses the
+// longest entry it starts with, so gpt-4o-2024-08-06 is priced as gpt-4o.
+type PriceTable map[string]ModelPrice
+
+// DefaultPriceTable returns the list prices of common OpenAI models
+func DefaultPriceTable() PriceTable {
+	return PriceTable{
+		"gpt-3.5-turbo":          {Prompt: 0.50, Completion: 1.50},
+		"gpt-4":                  {Prompt: 30.00, Completion: 60.00},
+		"gpt-4-turbo":            {Prompt: 10.00, Completion: 30.00},
+		"gpt-4o":                 {Prompt: 2.50, Completion: 10.00},
+		"gpt-4o-mini":            {Prompt: 0.15, Completion: 0.60},
+		"text-embedding-3-small": {Prompt: 0.02},
+		"text-embedding-3-large": {Prompt: 0.13},
+	}
+}
+
+// LoadPriceTable reads model prices from a YAML file mapping model names to prices
+func LoadPriceTable(path string) (PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var prices PriceTable
+	if err := yaml.Unmarshal(data, &prices); err != nil {
+		return nil, fmt.Errorf("invalid price table: %v", err)
+	}
+	return prices, nil
+}
+
+// Price returns the price of a model and whether the table has one
+func (t PriceTable) Price(modelName string) (ModelPrice, bool) {
+	if price, ok := t[modelName]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range t {
+		if strings.HasPrefix(modelName, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return t[best], true
+}
+
+// Cost returns the price in US dollars of a call's token usage
+func (t PriceTable) Cost(modelName string, usage openai.Usage) float64 {
+	price, _ := t.Price(modelName)
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+}
+
+// UsageConfig controls AI cost accounting
+type UsageConfig struct {
+	// PriceFile is a YAML price table whose entries replace the default prices
+	PriceFile string
+	// MonthlyBudget is the spend in US dollars per calendar month (UTC) after which the
+	// LLM providers are no longer called; zero means no budget
+	MonthlyBudget float64
+}
+
+// UsageConfigFromEnv reads AI_PRICE_FILE and AI_MONTHLY_BUDGET
+func UsageConfigFromEnv() UsageConfig {
+	config := UsageConfig{PriceFile: os.Getenv("AI_PRICE_FILE")}
+	if value, err := strconv.ParseFloat(os.Getenv("AI_MONTHLY_BUDGET"), 64); err == nil && value >= 0 {
+		config.MonthlyBudget = value
+	}
+	return config
+}
+
+// BudgetStatus reports the spend of the current month against the budget
+type BudgetStatus struct {
+	Month       string  `json:"month"`
+	Budget      float64 `json:"budget"`
+	MonthToDate float64 `json:"month_to_date"`
+	Exceeded    bool    `json:"exceeded"`
+}
+
+// UsageMeter records the token usage and cost of provider calls and enforces the monthly budget
+type UsageMeter struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	prices PriceTable
+	budget float64
+	// month is the UTC month that monthCost is the spend of
+	month     string
+	monthCost float64
+}
+
+// NewUsageMeter creates a usage meter with the given prices and monthly budget
+func NewUsageMeter(prices PriceTable, budget float64) *UsageMeter {
+	return &UsageMeter{now: time.Now, prices: prices, budget: budget}
+}
+
+var (
+	sharedUsageMu sync.Mutex
+	// sharedUsage is the process-wide usage meter, so every service spends from the same budget
+	sharedUsage *UsageMeter
+)
+
+// usageMeter returns the process-wide usage meter, creating it with the default prices on first use
+func usageMeter() *UsageMeter {
+	sharedUsageMu.Lock()
+	defer sharedUsageMu.Unlock()
+	if sharedUsage == nil {
+		sharedUsage = NewUsageMeter(DefaultPriceTable(), 0)
+	}
+	return sharedUsage
+}
+
+// AIBudgetStatus returns the budget status of the process-wide usage meter
+func AIBudgetStatus() BudgetStatus {
+	return usageMeter().Status()
+}
+
+// configure applies a usage configuration, merging its price file over the default prices
+func (m *UsageMeter) configure(config UsageConfig) error {
+	prices := DefaultPriceTable()
+	if config.PriceFile != "" {
+		filePrices, err := LoadPriceTable(config.PriceFile)
+		if err != nil {
+			return fmt.Errorf("failed to load prices from %s: %v", config.PriceFile, err)
+		}
+		for name, price := range filePrices {
+			prices[name] = price
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices = prices
+	m.budget = config.MonthlyBudget
+	return nil
+}
+
+// Record stores the usage of a provider call and adds its cost to the month's spend.
+// Storage failures are logged; they must not fail the call that was already paid for.
+func (m *UsageMeter) Record(provider, modelName, operation string, usage openai.Usage) {
+	m.mu.Lock()
+	cost := m.prices.Cost(modelName, usage)
+	m.loadMonth()
+	m.monthCost += cost
+	m.mu.Unlock()
+
+	if database.GetDB() == nil {
+		return
+	}
+	err := repository.NewUsageRepository().Record(&model.AIUsage{
+		Provider:         provider,
+		Model:            modelName,
+		Operation:        operation,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		Cost:             cost,
+	})
+	if err != nil {
+		log.Println("Failed to record AI usage:", err)
+	}
+}
+
+// Allow returns ErrBudgetExceeded once the month's spend has reached the budget
+func (m *UsageMeter) Allow() error {
+	status := m.Status()
+	if status.Exceeded {
+		return fmt.Errorf("%w: spent $%.2f of $%.2f in %s", ErrBudgetExceeded, status.MonthToDate, status.Budget, status.Month)
+	}
+	return nil
+}
+
+// Status returns the spend of the current month against the budget
+func (m *UsageMeter) Status() BudgetStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loadMonth()
+	return BudgetStatus{
+		Month:       m.month,
+		Budget:      m.budget,
+		MonthToDate: m.monthCost,
+		Exceeded:    m.budget > 0 && m.monthCost >= m.budget,
+	}
+}
+
+// loadMonth starts tracking a new month, reading its spend so far from the database.
+// The caller must hold m.mu.
+func (m *UsageMeter) loadMonth() {
+	start := monthStart(m.now())
+	month := start.Format("2006-01")
+	if month == m.month {
+		return
+	}
+	m.month = month
+	m.monthCost = 0
+	if database.GetDB() == nil {
+		return
+	}
+	cost, err := repository.NewUsageRepository().TotalCost(start, start.AddDate(0, 1, 0))
+	if err != nil {
+		log.Println("Failed to load this month's AI spend:", err)
+		return
+	}
+	m.monthCost = cost
+}
+
+// monthStart returns the start of the UTC calendar month of t
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// UsageReport is the aggregated AI usage of a period
+type UsageReport struct {
+	From    time.Time                   `json:"from"`
+	To      time.Time                   `json:"to"`
+	GroupBy []string                    `json:"group_by"`
+	Data    []repository.UsageAggregate `json:"data"`
+	Total   repository.UsageAggregate   `json:"total"`
+	Budget  BudgetStatus                `json:"budget"`
+}
+
+// UsageService reports recorded AI usage
+type UsageService struct {
+	usage *repository.UsageRepository
+}
+
+// NewUsageService creates a new usage service
+func NewUsageService() *UsageService {
+	return &UsageService{usage: repository.NewUsageRepository()}
+}
+
+// Report aggregates the usage of [from, to) by the given groups, e.g. day and provider
+func (s *UsageService) Report(from, to time.Time, groups []string) (*UsageReport, error) {
+	details := make(map[string]string)
+	for _, group := range groups {
+		if _, ok := repository.UsageGroups[group]; !ok {
+			valid := make([]string, 0, len(repository.UsageGroups))
+			for name := range repository.UsageGroups {
+				valid = append(valid, name)
+			}
+			sort.Strings(valid)
+			details["group_by"] = fmt.Sprintf("unknown group '%s' (valid groups: %s)", group, strings.Join(valid, ", "))
+		}
+	}
+	if !to.After(from) {
+		details["to"] = "to must be after from"
+	}
+	if len(details) > 0 {
+		return nil, &ValidationError{Details: details}
+	}
+
+	data, err := s.usage.Aggregate(from, to, groups)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.usage.Aggregate(from, to, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{From: from, To: to, GroupBy: groups, Data: data, Budget: AIBudgetStatus()}
+	if len(totals) > 0 {
+		report.Total = totals[0]
+	}
+	return report, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"incident-management/database"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestPriceTable(t *testing.T) {
+	prices := PriceTable{
+		"gpt-4o":      {Prompt: 2.50, Completion: 10.00},
+		"gpt-4o-mini": {Prompt: 0.15, Completion: 0.60},
+	}
+
+	tests := []struct {
+		model    string
+		expected float64
+	}{
+		{"gpt-4o", 2.50*1 + 10.00*0.5},
+		{"gpt-4o-2024-08-06", 2.50*1 + 10.00*0.5},
+		{"gpt-4o-mini-2024-07-18", 0.15*1 + 0.60*0.5},
+		{"llama3", 0},
+	}
+	usage := openai.Usage{PromptTokens: 1000000, CompletionTokens: 500000}
+	for _, tt := range tests {
+		if cost := prices.Cost(tt.model, usage); math.Abs(cost-tt.expected) > 1e-9 {
+			t.Errorf("Expected %s to cost %.4f, got %.4f", tt.model, tt.expected, cost)
+		}
+	}
+}
+
+func TestUsageMeter_BudgetSwitchesToFallback(t *testing.T) {
+	// Initialize database first
+	err := database.InitDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	var calls int32
+	chat := newFakeChatServer(t, `{"severity": "high", "category": "network"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		chat.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	// The fake server answers as test-model with 120 prompt and 30 completion tokens,
+	// $0.15 at these prices
+	service := NewOpenAICompatibleService(server.URL+"/v1", "", "budget-test-model")
+	service.usage = NewUsageMeter(PriceTable{"test-model": {Prompt: 1000, Completion: 1000}}, 0.10)
+
+	result, err := service.Classify(context.Background(), "Router down", "Core router is not forwarding packets")
+	if err != nil || result.Fallback {
+		t.Fatalf("Expected the provider to classify within budget, got %+v %v", result, err)
+	}
+
+	status := service.usage.Status()
+	if math.Abs(status.MonthToDate-0.15) > 1e-9 || !status.Exceeded {
+		t.Errorf("Expected $0.15 spent and the budget exceeded, got %+v", status)
+	}
+
+	result, err = service.Classify(context.Background(), "Router down again", "Core router is not forwarding packets")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Fallback || !strings.Contains(result.FallbackReason, "budget") {
+		t.Errorf("Expected the fallback classifier once the budget is spent, got %+v", result)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected 1 provider call, got %d", calls)
+	}
+
+	// The call was recorded under the model that answered and shows up in the aggregates
+	now := time.Now().UTC()
+	report, err := NewUsageService().Report(now.Add(-time.Hour), now.Add(time.Hour), []string{"day", "provider"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var found bool
+	for _, row := range report.Data {
+		if row.Provider == ProviderOpenAICompatible && row.Cost > 0 {
+			found = true
+			if row.Day != now.Format("2006-01-02") || row.Requests < 1 || row.PromptTokens < 120 || math.Abs(row.Cost-0.15) > 1e-9 {
+				t.Errorf("Unexpected aggregate %+v", row)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected the priced call in the aggregates, got %+v", report.Data)
+	}
+	if report.Total.Requests < 1 {
+		t.Errorf("Expected a total over all calls, got %+v", report.Total)
+	}
+
+	if _, err := NewUsageService().Report(now, now.Add(time.Hour), []string{"incident"}); err == nil {
+		t.Error("Expected an unknown group to be rejected")
+	}
+}
@@ -87,28 +87,10 @@ func (h *TestHelper) ValidateIncidentFields(incident model.Incident) []string {
 func (h *TestHelper) ValidateAIFields(incident model.Incident) []string {
 	var errors []string
 
-	validSeverities := []string{"low", "medium", "high"}
-	validCategories := []string{"network", "software", "hardware", "security"}
-
-	severityValid := false
-	for _, severity := range validSeverities {
-		if incident.AISeverity == severity {
-			severityValid = true
-			break
-		}
-	}
-	if !severityValid {
+	if !incident.ValidateSeverity() {
 		errors = append(errors, "Invalid AI severity: "+incident.AISeverity)
 	}
-
-	categoryValid := false
-	for _, category := range validCategories {
-		if incident.AICategory == category {
-			categoryValid = true
-			break
-		}
-	}
-	if !categoryValid {
+	if !incident.ValidateCategory() {
 		errors = append(errors, "Invalid AI category: "+incident.AICategory)
 	}
 
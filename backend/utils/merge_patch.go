@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidMergePatch is returned when a merge patch document is not a JSON object
+var ErrInvalidMergePatch = errors.New("merge patch must be a JSON object")
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) document to the original JSON document
+func ApplyMergePatch(original, patch []byte) ([]byte, error) {
+	var target map[string]interface{}
+	if err := json.Unmarshal(original, &target); err != nil {
+		return nil, err
+	}
+
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, err
+	}
+	patchObject, ok := patchValue.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidMergePatch
+	}
+
+	return json.Marshal(mergeObjects(target, patchObject))
+}
+
+// mergeObjects recursively merges patch into target, removing keys set to null
+func mergeObjects(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{})
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		patchChild, isObject := value.(map[string]interface{})
+		if !isObject {
+			target[key] = value
+			continue
+		}
+		targetChild, _ := target[key].(map[string]interface{})
+		target[key] = mergeObjects(targetChild, patchChild)
+	}
+	return target
+}
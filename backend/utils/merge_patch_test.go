@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "Replace single field",
+			original: `{"title": "Old", "status": "open"}`,
+			patch:    `{"status": "resolved"}`,
+			expected: `{"title": "Old", "status": "resolved"}`,
+		},
+		{
+			name:     "Null removes field",
+			original: `{"title": "Old", "priority": "high"}`,
+			patch:    `{"priority": null}`,
+			expected: `{"title": "Old"}`,
+		},
+		{
+			name:     "Nested objects are merged",
+			original: `{"meta": {"a": 1, "b": 2}}`,
+			patch:    `{"meta": {"b": 3, "c": 4}}`,
+			expected: `{"meta": {"a": 1, "b": 3, "c": 4}}`,
+		},
+		{
+			name:     "Arrays are replaced",
+			original: `{"tags": ["a", "b"]}`,
+			patch:    `{"tags": ["c"]}`,
+			expected: `{"tags": ["c"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyMergePatch([]byte(tt.original), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(result, &got); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &want); err != nil {
+				t.Fatalf("Failed to unmarshal expected: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %s, got %s", tt.expected, string(result))
+			}
+		})
+	}
+}
+
+func TestApplyMergePatch_NonObjectPatch(t *testing.T) {
+	_, err := ApplyMergePatch([]byte(`{"title": "Old"}`), []byte(`["status"]`))
+	if err != ErrInvalidMergePatch {
+		t.Errorf("Expected ErrInvalidMergePatch, got %v", err)
+	}
+}
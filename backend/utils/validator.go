@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"incident-management/model"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -11,6 +12,13 @@ var validate *validator.Validate
 // InitValidator initializes the validator
 func InitValidator() {
 	validate = validator.New()
+	validate.RegisterValidation("taxonomy", validateTaxonomy)
+}
+
+// validateTaxonomy checks a field against the taxonomy kind given as the tag parameter,
+// e.g. validate:"taxonomy=severity"
+func validateTaxonomy(fl validator.FieldLevel) bool {
+	return model.CurrentTaxonomy().Contains(fl.Param(), fl.Field().String())
 }
 
 // ValidateAndGetErrors validates a struct and returns detailed error messages
@@ -36,6 +44,8 @@ func ValidateAndGetErrors(s interface{}) map[string]string {
 			errors[field] = field + " must be at most " + err.Param() + " characters"
 		case "oneof":
 			errors[field] = field + " must be one of: " + err.Param()
+		case "taxonomy":
+			errors[field] = field + " must be one of: " + strings.Join(model.CurrentTaxonomy().Values(err.Param()), " ")
 		case "uuid4":
 			errors[field] = field + " must be a valid UUID"
 		default:
@@ -18,8 +18,8 @@ function App() {
   const fetchIncidents = async () => {
     try {
       const res = await fetch("http://localhost:8080/api/v1/incidents");
-      const data = await res.json();
-      setIncidents(data);
+      const page = await res.json();
+      setIncidents(page.data);
     } catch (err) {
       console.error("Failed to fetch incidents", err);
     }
@@ -8,7 +8,7 @@ export default function IncidentList() {
 
   const loadIncidents = async () => {
     const res = await fetchIncidents();
-    setIncidents(res.data);
+    setIncidents(res.data.data);
   };
 
   useEffect(() => {